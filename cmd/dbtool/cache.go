@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/cache"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// runCache manages the Redis caches.
+func runCache(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: cache takes one of flush, export-matrix", errUsage)
+	}
+
+	switch args[0] {
+	case "flush":
+		return runCacheFlush(e, args[1:])
+	case "export-matrix":
+		return runCacheExportMatrix(e, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown cache action %q", errUsage, args[0])
+	}
+}
+
+func runCacheFlush(e *env, args []string) error {
+	fs := flag.NewFlagSet("cache flush", flag.ContinueOnError)
+	var kinds mapFlags
+	fs.Var(&kinds, "kind", fmt.Sprintf("cache kind to flush (repeatable; default all): %s", strings.Join(cache.Kinds(), ", ")))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rdb, err := e.Redis()
+	if err != nil {
+		return err
+	}
+
+	n, err := cache.Flush(context.Background(), rdb, kinds...)
+	if err != nil {
+		return err
+	}
+	log.Printf("Flushed %d cache entries.", n)
+	return nil
+}
+
+// Redis connects on first use so database commands work without Redis.
+func (e *env) Redis() (*redis.Client, error) {
+	if e.rdb != nil {
+		return e.rdb, nil
+	}
+	redisURL := e.cfg.Redis.URL
+	if strings.TrimSpace(redisURL) == "" {
+		return nil, errors.New("REDIS_URL is required")
+	}
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	e.rdb = redis.NewClient(opt)
+	return e.rdb, nil
+}
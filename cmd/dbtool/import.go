@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/repositories"
+	"delivery-route-service/internal/services"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mapFlags collects a repeatable string flag such as -map field=column.
+type mapFlags []string
+
+func (m *mapFlags) String() string     { return strings.Join(*m, ",") }
+func (m *mapFlags) Set(v string) error { *m = append(*m, v); return nil }
+
+// importRequest builds an import request for path, inferring the format from
+// its extension unless format is given.
+func importRequest(path, format string, mappings []string) (services.ImportPackagesRequest, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = string(services.ImportFormatCSV)
+		case ".json", ".ndjson", ".jsonl":
+			format = string(services.ImportFormatJSON)
+		default:
+			return services.ImportPackagesRequest{}, fmt.Errorf("cannot infer format from %q; pass -format", path)
+		}
+	}
+
+	mapping, err := services.ParseImportMapping(mappings)
+	if err != nil {
+		return services.ImportPackagesRequest{}, err
+	}
+
+	return services.ImportPackagesRequest{
+		Format:  services.ImportFormat(format),
+		Mode:    services.ImportModeAtomic,
+		Mapping: mapping,
+	}, nil
+}
+
+// runImport bulk imports packages from a CSV or JSON file ("-" reads stdin)
+// and prints a summary with one line per rejected row.
+func runImport(e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "input format: csv or json (default: from file extension)")
+	mode := fs.String("mode", string(services.ImportModeAtomic), "atomic (all-or-nothing) or best_effort (skip invalid rows)")
+	dryRun := fs.Bool("dry-run", false, "validate and report without writing")
+	var mappings mapFlags
+	fs.Var(&mappings, "map", "map a package field to a source column, as field=column (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dbtool import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: import takes exactly one input file", errUsage)
+	}
+	path := fs.Arg(0)
+
+	req, err := importRequest(path, *format, mappings)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	req.Mode = services.ImportMode(*mode)
+	req.DryRun = *dryRun
+
+	var src io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+		defer f.Close()
+		src = f
+	}
+
+	db, err := e.DB()
+	if err != nil {
+		return err
+	}
+	if req.Hubs, err = repositories.NewSQLHubRepository(db).ListHubs(context.Background()); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	result, err := services.ImportPackages(context.Background(), src, req, repositories.NewSQLPackageRepository(db))
+	if err != nil {
+		return err
+	}
+
+	for _, e := range result.Errors {
+		if e.Field == "" {
+			log.Printf("row %d: %s", e.Row, e.Reason)
+			continue
+		}
+		log.Printf("row %d: %s: %s", e.Row, e.Field, e.Reason)
+	}
+
+	verb := "Imported"
+	if result.DryRun {
+		verb = "Would import"
+	}
+	log.Printf("%s %d of %d rows (%d rejected, mode=%s).", verb, result.Imported, result.Total, len(result.Errors), result.Mode)
+
+	if result.Mode == services.ImportModeAtomic && len(result.Errors) > 0 {
+		return errors.New("import: nothing written because some rows were rejected; fix them or use -mode best_effort")
+	}
+	return nil
+}
@@ -2,52 +2,131 @@ package main
 
 import (
 	"database/sql"
-	"delivery-route-service/internal/adapters/repositories"
 	"delivery-route-service/internal/config"
 	"delivery-route-service/internal/platform/db"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"strings"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 )
 
+const usage = `usage: dbtool <command> [flags]
+
+commands:
+  setup                      migrate up, then seed from SEED_PATH
+  migrate up                 apply pending schema migrations
+  migrate down [-steps N | -all]
+                             revert the latest N migrations (default 1)
+  migrate status             list migrations and when they were applied
+  seed [-file PATH]          upsert packages from a JSON seed file
+  import [flags] FILE        bulk import packages from CSV or JSON
+  export [-format json|csv] [-out PATH]
+                             write all packages as a seed file or CSV
+  reset -confirm [-no-seed]  revert all migrations, reapply them, then seed
+  cache flush [-kind KIND]   delete cached geocode/distance/directions entries
+  cache export-matrix -out FILE [flags]
+                             write cached distances between the hub and package
+                             destinations as a matrix file for cmd/plan
+
+Run "dbtool <command> -h" for command flags.
+`
+
+// errUsage marks command-line mistakes; main prints usage and exits 2 for them.
+var errUsage = errors.New("usage error")
+
+// env holds what commands need: the loaded config and a lazily opened database.
+type env struct {
+	cfg config.Config
+	db  *sql.DB
+	rdb *redis.Client
+	// Set by commands that manage the schema themselves, so DB does not
+	// auto-migrate underneath them.
+	manualSchema bool
+}
+
+// DB opens the database on first use so cache commands work without Postgres.
+// With DATABASE_AUTO_MIGRATE set, pending migrations are applied on open.
+func (e *env) DB() (*sql.DB, error) {
+	if e.db != nil {
+		return e.db, nil
+	}
+	databaseURL := e.cfg.Database.URL
+	if strings.TrimSpace(databaseURL) == "" {
+		return nil, errors.New("DATABASE_URL is required")
+	}
+	conn, err := db.Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	e.db = conn
+	if e.cfg.Database.AutoMigrate && !e.manualSchema {
+		if err := applyMigrations(conn); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found (using environment variables)")
 	}
 
-	databaseURL := os.Getenv("DATABASE_URL")
-	if strings.TrimSpace(databaseURL) == "" {
-		log.Fatal("DATABASE_URL is required")
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
 	}
 
-	db, err := db.Open(databaseURL)
+	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer db.Close()
 
-	seedPath := config.Get("SEED_PATH", "data/seeds/packages.json")
-	if err := initAndSeed(db, seedPath); err != nil {
+	e := &env{cfg: cfg}
+	err = run(e, os.Args[1], os.Args[2:])
+	if e.db != nil {
+		e.db.Close()
+	}
+	if e.rdb != nil {
+		e.rdb.Close()
+	}
+	if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		}
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
-
 }
 
-func initAndSeed(db *sql.DB, seedPath string) error {
-	log.Println("Initializing database schema...")
-	if err := repositories.InitSchema(db); err != nil {
-		log.Fatalf("schema initialization failed: %v", err)
+func run(e *env, cmd string, args []string) error {
+	switch cmd {
+	case "setup":
+		return runSetup(e, args)
+	case "migrate":
+		return runMigrate(e, args)
+	case "seed":
+		return runSeed(e, args)
+	case "import":
+		return runImport(e, args)
+	case "export":
+		return runExport(e, args)
+	case "reset":
+		return runReset(e, args)
+	case "cache":
+		return runCache(e, args)
+	case "help", "-h", "--help":
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, cmd)
 	}
-	log.Println("Schema ready.")
-
-	log.Println("Seeding database...")
-	if err := repositories.SeedFromJSON(db, seedPath); err != nil {
-		log.Fatalf("seeding failed: %v", err)
-	}
-	log.Println("Seeding complete.")
-
-	return nil
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/services"
+)
+
+func TestRun(t *testing.T) {
+	// Without DATABASE_URL and REDIS_URL, a command that parses its flags
+	// fails when it first needs a connection, which tells it apart from a
+	// usage error.
+	const (
+		noDB    = "DATABASE_URL is required"
+		noRedis = "REDIS_URL is required"
+	)
+
+	tests := []struct {
+		name      string
+		cmd       string
+		args      []string
+		wantUsage bool
+		wantHelp  bool
+		wantErr   string
+	}{
+		{name: "unknown command", cmd: "drop", wantUsage: true},
+		{name: "help", cmd: "help", wantHelp: true},
+		{name: "command help", cmd: "seed", args: []string{"-h"}, wantHelp: true},
+		{name: "setup", cmd: "setup", wantErr: noDB},
+		{name: "migrate without action", cmd: "migrate", wantUsage: true},
+		{name: "unknown migrate action", cmd: "migrate", args: []string{"sideways"}, wantUsage: true},
+		{name: "migrate up", cmd: "migrate", args: []string{"up"}, wantErr: noDB},
+		{name: "migrate up with arguments", cmd: "migrate", args: []string{"up", "2"}, wantUsage: true},
+		{name: "migrate down steps", cmd: "migrate", args: []string{"down", "-steps", "2"}, wantErr: noDB},
+		{name: "migrate down all", cmd: "migrate", args: []string{"down", "-all"}, wantErr: noDB},
+		{name: "migrate down zero steps", cmd: "migrate", args: []string{"down", "-steps", "0"}, wantUsage: true},
+		{name: "migrate status with arguments", cmd: "migrate", args: []string{"status", "-v"}, wantUsage: true},
+		{name: "seed from file", cmd: "seed", args: []string{"-file", "seed.json"}, wantErr: noDB},
+		{name: "import without file", cmd: "import", wantUsage: true},
+		{name: "import with two files", cmd: "import", args: []string{"a.csv", "b.csv"}, wantUsage: true},
+		{name: "import unknown extension", cmd: "import", args: []string{"pkgs.txt"}, wantErr: "cannot infer format"},
+		{name: "export csv", cmd: "export", args: []string{"-format", "csv"}, wantErr: noDB},
+		{name: "export unknown format", cmd: "export", args: []string{"-format", "xml"}, wantUsage: true},
+		{name: "reset without confirm", cmd: "reset", wantUsage: true},
+		{name: "reset without confirm keeps other flags", cmd: "reset", args: []string{"-no-seed"}, wantUsage: true},
+		{name: "reset with confirm", cmd: "reset", args: []string{"-confirm"}, wantErr: noDB},
+		{name: "reset with confirm false", cmd: "reset", args: []string{"-confirm=false"}, wantUsage: true},
+		{name: "cache without action", cmd: "cache", wantUsage: true},
+		{name: "unknown cache action", cmd: "cache", args: []string{"warm"}, wantUsage: true},
+		{name: "cache flush", cmd: "cache", args: []string{"flush", "-kind", "geocode"}, wantErr: noRedis},
+		{name: "cache export-matrix without out", cmd: "cache", args: []string{"export-matrix"}, wantUsage: true},
+		{name: "undefined flag", cmd: "seed", args: []string{"-force"}, wantErr: "flag provided but not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Database.URL = ""
+			cfg.Redis.URL = ""
+			e := &env{cfg: cfg}
+
+			err := run(e, tt.cmd, tt.args)
+			switch {
+			case tt.wantUsage:
+				if !errors.Is(err, errUsage) {
+					t.Fatalf("expected usage error, got %v", err)
+				}
+				if e.db != nil || e.rdb != nil {
+					t.Fatal("expected no connection to be opened on a usage error")
+				}
+			case tt.wantHelp:
+				if !errors.Is(err, flag.ErrHelp) {
+					t.Fatalf("expected help, got %v", err)
+				}
+			default:
+				if err == nil || errors.Is(err, errUsage) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+func TestImportRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		format   string
+		mappings []string
+		want     services.ImportPackagesRequest
+		wantErr  bool
+	}{
+		{
+			name: "csv from extension",
+			path: "pkgs.CSV",
+			want: services.ImportPackagesRequest{Format: services.ImportFormatCSV, Mode: services.ImportModeAtomic, Mapping: map[string]string{}},
+		},
+		{
+			name: "json lines from extension",
+			path: "pkgs.jsonl",
+			want: services.ImportPackagesRequest{Format: services.ImportFormatJSON, Mode: services.ImportModeAtomic, Mapping: map[string]string{}},
+		},
+		{
+			name:   "explicit format wins",
+			path:   "-",
+			format: "csv",
+			want:   services.ImportPackagesRequest{Format: services.ImportFormatCSV, Mode: services.ImportModeAtomic, Mapping: map[string]string{}},
+		},
+		{
+			name:     "mapped columns",
+			path:     "pkgs.json",
+			mappings: []string{"package_id=Order", "destination=Ship To"},
+			want: services.ImportPackagesRequest{
+				Format:  services.ImportFormatJSON,
+				Mode:    services.ImportModeAtomic,
+				Mapping: map[string]string{"package_id": "Order", "destination": "Ship To"},
+			},
+		},
+		{name: "unknown extension", path: "pkgs.txt", wantErr: true},
+		{name: "unknown mapped field", path: "pkgs.csv", mappings: []string{"colour=Paint"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := importRequest(tt.path, tt.format, tt.mappings)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected request %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
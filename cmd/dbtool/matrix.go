@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/cache"
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/adapters/repositories"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/services"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// maxMissingLogged bounds how many missing pairs export-matrix lists.
+const maxMissingLogged = 10
+
+// runCacheExportMatrix writes the cached distances between the hub and every
+// package destination to a matrix file, so a plan can be replayed offline with
+// "plan -provider matrix". Destinations come from -packages, or from Postgres
+// when it is not given.
+func runCacheExportMatrix(e *env, args []string) error {
+	fs := flag.NewFlagSet("cache export-matrix", flag.ContinueOnError)
+	out := fs.String("out", "", "matrix file to write, .json or .csv; required")
+	hub := fs.String("hub", e.cfg.Server.HubAddress, "hub address to include")
+	packages := fs.String("packages", "", "package file (CSV or JSON) listing destinations (default: the packages table)")
+	format := fs.String("format", "", "package file format: csv or json (default: from file extension)")
+	strict := fs.Bool("strict", false, "fail instead of writing a partial matrix when pairs are not cached")
+	var mappings mapFlags
+	fs.Var(&mappings, "map", "map a package field to a source column, as field=column (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("%w: cache export-matrix needs -out FILE", errUsage)
+	}
+
+	ctx := context.Background()
+	pkgs, err := matrixPackages(ctx, e, *packages, *format, mappings)
+	if err != nil {
+		return err
+	}
+
+	addresses := make([]string, 0, len(pkgs)+1)
+	if h := strings.TrimSpace(*hub); h != "" {
+		addresses = append(addresses, h)
+	}
+	for _, p := range pkgs {
+		addresses = append(addresses, strings.TrimSpace(p.Destination))
+	}
+
+	rdb, err := e.Redis()
+	if err != nil {
+		return err
+	}
+	// The TTL only applies to writes, which export never does.
+	distanceCache := cache.NewRedisDistanceCache(rdb, e.cfg.Cache.TTL)
+
+	entries, missing, err := distance.ExportCachedMatrix(ctx, distanceCache, addresses)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range missing {
+		if i == maxMissingLogged {
+			log.Printf("... and %d more", len(missing)-maxMissingLogged)
+			break
+		}
+		log.Printf("not cached: %q -> %q", m.Origin, m.Destination)
+	}
+	if len(missing) > 0 && *strict {
+		return fmt.Errorf("cache export-matrix: %d pairs are not cached; plan once with ORS to fill the cache", len(missing))
+	}
+
+	if err := distance.SaveMatrixFile(*out, entries); err != nil {
+		return err
+	}
+	log.Printf("Wrote %d pairs to %s (%d not cached).", len(entries), *out, len(missing))
+	return nil
+}
+
+// matrixPackages lists the packages whose destinations go into the matrix.
+func matrixPackages(
+	ctx context.Context,
+	e *env,
+	path string,
+	format string,
+	mappings []string,
+) ([]*domain.Package, error) {
+	if path == "" {
+		db, err := e.DB()
+		if err != nil {
+			return nil, err
+		}
+		return repositories.NewSQLPackageRepository(db).ListPackages(ctx)
+	}
+
+	req, err := importRequest(path, format, mappings)
+	if err != nil {
+		return nil, fmt.Errorf("cache export-matrix: %w", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cache export-matrix: %w", err)
+	}
+	defer f.Close()
+
+	repo := repositories.NewMemoryPackageRepository()
+	result, err := services.ImportPackages(ctx, f, req, repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("cache export-matrix: %d of %d rows in %s were rejected", len(result.Errors), result.Total, path)
+	}
+	return repo.ListPackages(ctx)
+}
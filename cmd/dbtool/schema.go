@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"delivery-route-service/internal/adapters/repositories"
+	"flag"
+	"fmt"
+	"log"
+)
+
+// runSetup prepares a fresh database: migrate up, then seed. It is what the
+// docker-compose dbtool service runs.
+func runSetup(e *env, args []string) error {
+	fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := migrateUp(e); err != nil {
+		return err
+	}
+	return seed(e, e.cfg.Database.SeedPath)
+}
+
+func runMigrate(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: migrate takes one of up, down, status", errUsage)
+	}
+	e.manualSchema = true
+
+	switch args[0] {
+	case "up":
+		if len(args) > 1 {
+			return fmt.Errorf("%w: migrate up takes no arguments", errUsage)
+		}
+		return migrateUp(e)
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to revert")
+		all := fs.Bool("all", false, "revert every applied migration")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if !*all && *steps < 1 {
+			return fmt.Errorf("%w: -steps must be at least 1", errUsage)
+		}
+		if *all {
+			*steps = 0
+		}
+		return migrateDown(e, *steps)
+	case "status":
+		if len(args) > 1 {
+			return fmt.Errorf("%w: migrate status takes no arguments", errUsage)
+		}
+		db, err := e.DB()
+		if err != nil {
+			return err
+		}
+		states, err := repositories.MigrationStatus(context.Background(), db)
+		if err != nil {
+			return err
+		}
+		for _, st := range states {
+			name := fmt.Sprintf("%04d_%s", st.Version, st.Name)
+			if st.AppliedAt != nil {
+				fmt.Printf("%-36s applied  %s\n", name, st.AppliedAt.Format("2006-01-02 15:04:05Z07:00"))
+			} else {
+				fmt.Printf("%-36s pending\n", name)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown migrate action %q", errUsage, args[0])
+	}
+}
+
+func migrateUp(e *env) error {
+	e.manualSchema = true
+	db, err := e.DB()
+	if err != nil {
+		return err
+	}
+	return applyMigrations(db)
+}
+
+// applyMigrations applies pending migrations and logs each one.
+func applyMigrations(db *sql.DB) error {
+	log.Println("Applying schema migrations...")
+	applied, err := repositories.MigrateUp(context.Background(), db)
+	if err != nil {
+		return fmt.Errorf("schema migration failed: %w", err)
+	}
+	for _, m := range applied {
+		log.Printf("Applied %04d_%s", m.Version, m.Name)
+	}
+	log.Printf("Schema up to date (%d applied).", len(applied))
+	return nil
+}
+
+// migrateDown reverts the latest steps migrations; steps <= 0 reverts all.
+func migrateDown(e *env, steps int) error {
+	e.manualSchema = true
+	db, err := e.DB()
+	if err != nil {
+		return err
+	}
+	reverted, err := repositories.MigrateDown(context.Background(), db, steps)
+	if err != nil {
+		return fmt.Errorf("schema rollback failed: %w", err)
+	}
+	for _, m := range reverted {
+		log.Printf("Reverted %04d_%s", m.Version, m.Name)
+	}
+	log.Printf("Schema rolled back (%d reverted).", len(reverted))
+	return nil
+}
+
+// runReset reverts every migration and reapplies them, then seeds unless
+// -no-seed is set. It destroys all package data, so -confirm is required.
+func runReset(e *env, args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ContinueOnError)
+	confirm := fs.Bool("confirm", false, "required; acknowledges that all package data is deleted")
+	noSeed := fs.Bool("no-seed", false, "leave the tables empty instead of seeding")
+	file := fs.String("file", "", "seed file (default: SEED_PATH)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*confirm {
+		return fmt.Errorf("%w: reset deletes all data; pass -confirm", errUsage)
+	}
+
+	if err := migrateDown(e, 0); err != nil {
+		return err
+	}
+	if err := migrateUp(e); err != nil {
+		return err
+	}
+	if *noSeed {
+		return nil
+	}
+
+	path := *file
+	if path == "" {
+		path = e.cfg.Database.SeedPath
+	}
+	return seed(e, path)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/repositories"
+	"delivery-route-service/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+func runSeed(e *env, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	file := fs.String("file", "", "seed file (default: SEED_PATH)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path := *file
+	if path == "" {
+		path = e.cfg.Database.SeedPath
+	}
+	return seed(e, path)
+}
+
+func seed(e *env, path string) error {
+	db, err := e.DB()
+	if err != nil {
+		return err
+	}
+	log.Printf("Seeding database from %s...", path)
+	if err := repositories.SeedFromJSON(db, path); err != nil {
+		return fmt.Errorf("seeding failed: %w", err)
+	}
+	log.Println("Seeding complete.")
+	return nil
+}
+
+// runExport writes every package either as a JSON seed file, which seed and
+// import accept back, or as CSV with the import column names.
+func runExport(e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "output format: json or csv")
+	out := fs.String("out", "-", `output file ("-" for stdout)`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("%w: export format must be json or csv, got %q", errUsage, *format)
+	}
+
+	db, err := e.DB()
+	if err != nil {
+		return err
+	}
+	pkgs, err := repositories.NewSQLPackageRepository(db).ListPackages(context.Background())
+	if err != nil {
+		return err
+	}
+
+	seeds := make([]repositories.PackageSeed, 0, len(pkgs))
+	for _, p := range pkgs {
+		s := repositories.PackageSeed{
+			PackageID:   p.PackageID,
+			Destination: p.Destination,
+			WeightKg:    p.WeightKg,
+			VolumeM3:    p.VolumeM3,
+			HubID:       p.HubID,
+			Pickup:      p.Pickup,
+			Deadline:    p.Deadline,
+		}
+		if p.ServiceLevel != domain.ServiceLevelStandard {
+			s.ServiceLevel = p.ServiceLevel
+		}
+		if p.Coordinates != nil {
+			lat, lon := p.Coordinates.Lat, p.Coordinates.Lon
+			s.Lat, s.Lon = &lat, &lon
+		}
+		if p.ServiceTime != nil {
+			secs := int(*p.ServiceTime / time.Second)
+			s.ServiceSeconds = &secs
+		}
+		seeds = append(seeds, s)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(seeds); err != nil {
+			return fmt.Errorf("export: encode json: %w", err)
+		}
+	} else if err := writeSeedCSV(w, seeds); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	if *out != "-" {
+		log.Printf("Exported %d packages to %s.", len(seeds), *out)
+	}
+	return nil
+}
+
+func writeSeedCSV(w io.Writer, seeds []repositories.PackageSeed) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"package_id", "destination", "lat", "lon", "service_seconds", "weight_kg", "volume_m3", "hub_id", "pickup", "service_level", "deadline"}); err != nil {
+		return err
+	}
+	for _, s := range seeds {
+		lat, lon := "", ""
+		if s.Lat != nil && s.Lon != nil {
+			lat = strconv.FormatFloat(*s.Lat, 'f', -1, 64)
+			lon = strconv.FormatFloat(*s.Lon, 'f', -1, 64)
+		}
+		service := ""
+		if s.ServiceSeconds != nil {
+			service = strconv.Itoa(*s.ServiceSeconds)
+		}
+		weight := strconv.FormatFloat(s.WeightKg, 'f', -1, 64)
+		volume := strconv.FormatFloat(s.VolumeM3, 'f', -1, 64)
+		hub := ""
+		if s.HubID != 0 {
+			hub = strconv.Itoa(s.HubID)
+		}
+		deadline := ""
+		if s.Deadline != nil {
+			deadline = s.Deadline.Format(time.RFC3339)
+		}
+		row := []string{
+			strconv.Itoa(s.PackageID), s.Destination, lat, lon, service, weight, volume, hub,
+			s.Pickup, string(s.ServiceLevel), deadline,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
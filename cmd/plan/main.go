@@ -0,0 +1,361 @@
+// Command plan runs route planning from a package file without the HTTP
+// server, Postgres or Redis, and prints the plans as a table, JSON or GeoJSON.
+package main
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/adapters/repositories"
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"delivery-route-service/internal/services"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Distance providers selectable with -provider.
+const (
+	providerEstimate = "estimate"
+	providerORS      = "ors"
+	providerMatrix   = "matrix"
+)
+
+// Output formats selectable with -output.
+const (
+	outputTable   = "table"
+	outputJSON    = "json"
+	outputGeoJSON = "geojson"
+)
+
+// errUsage marks command-line mistakes; main prints usage and exits 2 for them.
+var errUsage = errors.New("usage error")
+
+// mapFlags collects a repeatable string flag such as -map field=column.
+type mapFlags []string
+
+func (m *mapFlags) String() string     { return strings.Join(*m, ",") }
+func (m *mapFlags) Set(v string) error { *m = append(*m, v); return nil }
+
+// options holds the parsed command line.
+type options struct {
+	packages string
+	format   string
+	mappings mapFlags
+	hub      string
+	trucks   int
+	capacity int
+	// Weight and volume limit per truck; zero for none.
+	maxWeight float64
+	maxVolume float64
+	fleet     string
+	strategy  string
+	objective string
+	// Even out workloads by duration or packages, within a tolerance.
+	balance   string
+	tolerance float64
+	provider  string
+	matrix    string
+	speed     float64
+	depart    string
+	returnHub bool
+	// Service time per stop and per package.
+	serviceStop    time.Duration
+	servicePackage time.Duration
+	// Driver shift limits.
+	maxShift      time.Duration
+	breakAfter    time.Duration
+	breakDuration time.Duration
+	// Run several trips per truck, reloading at the hub in between.
+	multiTrip bool
+	reload    time.Duration
+	// Default promises of same-day and express packages.
+	sameDay  time.Duration
+	express  time.Duration
+	geometry bool
+	output   string
+	out      string
+}
+
+func main() {
+	log.SetFlags(0)
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = run(cfg, os.Args[1:], os.Stdout)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "%v\n\nRun \"plan -h\" for flags.\n", err)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseFlags reads the command line, taking defaults from cfg.
+func parseFlags(cfg config.Config, args []string) (options, error) {
+	var o options
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+	fs.StringVar(&o.packages, "packages", "", "package file, CSV or JSON (\"-\" reads stdin); required")
+	fs.StringVar(&o.format, "format", "", "package file format: csv or json (default: from file extension)")
+	fs.Var(&o.mappings, "map", "map a package field to a source column, as field=column (repeatable)")
+	fs.StringVar(&o.hub, "hub", cfg.Server.HubAddress, "hub address or \"lat,lon\"")
+	fs.IntVar(&o.trucks, "trucks", cfg.Planning.DefaultTruckCount, "number of trucks")
+	fs.IntVar(&o.capacity, "capacity", cfg.Planning.DefaultTruckCapacity, "packages per truck")
+	fs.Float64Var(&o.maxWeight, "max-weight", 0, "kilograms per truck; 0 for no limit")
+	fs.Float64Var(&o.maxVolume, "max-volume", 0, "cubic meters per truck; 0 for no limit")
+	fs.StringVar(&o.fleet, "fleet", "", "fleet file, a JSON array of trucks as accepted by POST /trucks; replaces -trucks, -capacity and the limits")
+	fs.StringVar(&o.strategy, "strategy", string(services.RouteStrategyNearestNeighbor), "stop ordering: nearest_neighbor or two_opt")
+	fs.StringVar(&o.objective, "objective", string(services.ObjectiveDuration), "what to minimize: duration, distance, cost (needs -fleet rates) or makespan")
+	fs.StringVar(&o.balance, "balance", "", "even out workloads between trucks: duration or packages (default: off)")
+	fs.Float64Var(&o.tolerance, "balance-tolerance", cfg.Planning.BalanceTolerance, "gap allowed between the busiest and idlest truck, as a fraction of the average workload")
+	fs.StringVar(&o.provider, "provider", providerEstimate, "distance provider: estimate (offline), ors or matrix")
+	fs.StringVar(&o.matrix, "matrix", "", "distance matrix file (.json or .csv) for -provider matrix")
+	fs.Float64Var(&o.speed, "speed", distance.DefaultEstimateSpeedKPH, "average speed in km/h for -provider estimate")
+	fs.StringVar(&o.depart, "depart", "", "departure time, RFC 3339 (default: now)")
+	fs.BoolVar(&o.returnHub, "return", false, "include the return leg to the hub")
+	fs.DurationVar(&o.serviceStop, "service-stop", cfg.Planning.ServiceTimePerStop, "time spent at each stop")
+	fs.DurationVar(&o.servicePackage, "service-package", cfg.Planning.ServiceTimePerPackage, "time spent per package, unless the package file gives service_seconds")
+	fs.DurationVar(&o.maxShift, "max-shift", cfg.Planning.MaxShift, "longest route a driver may work; 0 for no limit")
+	fs.DurationVar(&o.breakAfter, "break-after", cfg.Planning.BreakAfter, "time on duty before a break; 0 for no breaks")
+	fs.DurationVar(&o.breakDuration, "break-duration", cfg.Planning.BreakDuration, "length of each break")
+	fs.BoolVar(&o.multiTrip, "multi-trip", false, "let trucks return to the hub, reload and run further trips")
+	fs.DurationVar(&o.reload, "reload", cfg.Planning.ReloadTime, "time to reload at the hub between trips")
+	fs.DurationVar(&o.sameDay, "same-day-window", cfg.Planning.SameDayWindow, "promise of same_day packages without a deadline, from departure; 0 for none")
+	fs.DurationVar(&o.express, "express-window", cfg.Planning.ExpressWindow, "promise of express packages without a deadline, from departure; 0 for none")
+	fs.BoolVar(&o.geometry, "geometry", false, "attach road geometry and directions (ors only)")
+	fs.StringVar(&o.output, "output", outputTable, "output format: table, json or geojson")
+	fs.StringVar(&o.out, "out", "", "write output to this file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: plan -packages FILE [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return o, err
+	}
+	if fs.NArg() > 0 {
+		return o, fmt.Errorf("%w: unexpected argument %q", errUsage, fs.Arg(0))
+	}
+
+	if o.packages == "" {
+		return o, fmt.Errorf("%w: -packages is required", errUsage)
+	}
+	if o.format == "" {
+		switch strings.ToLower(filepath.Ext(o.packages)) {
+		case ".csv":
+			o.format = string(services.ImportFormatCSV)
+		case ".json", ".ndjson", ".jsonl":
+			o.format = string(services.ImportFormatJSON)
+		default:
+			return o, fmt.Errorf("%w: cannot infer format from %q; pass -format", errUsage, o.packages)
+		}
+	}
+	switch o.output {
+	case outputTable, outputJSON, outputGeoJSON:
+	default:
+		return o, fmt.Errorf("%w: -output must be %s, %s or %s", errUsage, outputTable, outputJSON, outputGeoJSON)
+	}
+	if o.provider == providerMatrix && o.matrix == "" {
+		return o, fmt.Errorf("%w: -provider matrix needs -matrix FILE", errUsage)
+	}
+	if o.provider != providerMatrix && o.matrix != "" {
+		return o, fmt.Errorf("%w: -matrix is only used with -provider matrix", errUsage)
+	}
+
+	return o, nil
+}
+
+func run(cfg config.Config, args []string, stdout io.Writer) error {
+	o, err := parseFlags(cfg, args)
+	if err != nil {
+		return err
+	}
+
+	depart := time.Now()
+	if o.depart != "" {
+		depart, err = time.Parse(time.RFC3339, o.depart)
+		if err != nil {
+			return fmt.Errorf("%w: -depart must be RFC 3339, e.g. 2025-01-02T08:00:00-07:00", errUsage)
+		}
+	}
+
+	provider, err := newProvider(cfg, o)
+	if err != nil {
+		return err
+	}
+
+	var fleet []*domain.Truck
+	if o.fleet != "" {
+		if fleet, err = loadFleet(o.fleet); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	repo := repositories.NewMemoryPackageRepository()
+	if err := loadPackages(ctx, o, repo); err != nil {
+		return err
+	}
+
+	req := planRequest(cfg, o, depart, fleet)
+	result, err := services.PlanDeliveries(ctx, req, repo, provider)
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	if o.out != "" {
+		f, err := os.Create(o.out)
+		if err != nil {
+			return fmt.Errorf("plan: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := writeResult(w, o.output, o.multiTrip, req.Objective, result); err != nil {
+		return fmt.Errorf("plan: write %s: %w", o.output, err)
+	}
+	if o.out != "" {
+		log.Printf("Wrote %d plans to %s.", len(result.Plans), o.out)
+	}
+	return nil
+}
+
+// planRequest maps the command line onto a planning request.
+func planRequest(cfg config.Config, o options, depart time.Time, fleet []*domain.Truck) services.PlanDeliveriesRequest {
+	return services.PlanDeliveriesRequest{
+		Hub:              strings.TrimSpace(o.hub),
+		TruckCount:       o.trucks,
+		TruckCapacity:    o.capacity,
+		TruckMaxWeightKg: o.maxWeight,
+		TruckMaxVolumeM3: o.maxVolume,
+		Trucks:           fleet,
+		DepartAt:         depart,
+		ReturnToStart:    o.returnHub,
+		Concurrency:      cfg.Planning.Concurrency,
+		IncludeGeometry:  o.geometry,
+		Strategy:         services.RouteStrategy(o.strategy),
+		Objective:        services.Objective(o.objective),
+		Balance:          services.Balance{Mode: services.BalanceMode(o.balance), Tolerance: o.tolerance},
+		ServiceTime:      domain.ServiceTime{PerStop: o.serviceStop, PerPackage: o.servicePackage},
+		Shift:            domain.Shift{MaxDuration: o.maxShift, BreakAfter: o.breakAfter, BreakDuration: o.breakDuration},
+		MultiTrip:        o.multiTrip,
+		ReloadTime:       o.reload,
+		Promises:         domain.Promises{SameDay: o.sameDay, Express: o.express},
+	}
+}
+
+// newProvider builds the distance provider selected with -provider.
+func newProvider(cfg config.Config, o options) (ports.DistanceProvider, error) {
+	switch o.provider {
+	case providerEstimate:
+		return distance.NewEstimateDistanceProvider(o.speed)
+	case providerMatrix:
+		return distance.LoadMatrixFile(o.matrix)
+	case providerORS:
+		if strings.TrimSpace(cfg.ORS.APIKey) == "" {
+			return nil, errors.New("ORS_API_KEY is required for -provider ors")
+		}
+		// No Redis or Postgres: every run geocodes and fetches distances afresh.
+		return distance.NewORSDistanceProvider(cfg.ORS, nil, nil, nil, nil)
+	default:
+		return nil, fmt.Errorf("%w: -provider must be %s, %s or %s", errUsage, providerEstimate, providerORS, providerMatrix)
+	}
+}
+
+// loadFleet reads the trucks in a fleet file, leaving out unavailable ones.
+func loadFleet(path string) ([]*domain.Truck, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("plan: %w", err)
+	}
+	var rows []dto.TruckRequest
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, fmt.Errorf("plan: parse %s: %w", path, err)
+	}
+
+	var trucks []*domain.Truck
+	for i, row := range rows {
+		if row.TruckID < 1 {
+			return nil, fmt.Errorf("plan: %s: truck at index %d: truck_id must be a positive integer", path, i)
+		}
+		if row.Available != nil && !*row.Available {
+			continue
+		}
+		trucks = append(trucks, &domain.Truck{
+			TruckID:       row.TruckID,
+			Name:          strings.TrimSpace(row.Name),
+			Capacity:      row.Capacity,
+			MaxWeightKg:   row.MaxWeightKg,
+			MaxVolumeM3:   row.MaxVolumeM3,
+			StartLocation: row.HomeHub,
+			VehicleType:   row.VehicleType,
+			CostPerKm:     row.CostPerKm,
+			CostPerHour:   row.CostPerHour,
+			Available:     true,
+		})
+	}
+	if len(trucks) == 0 {
+		return nil, fmt.Errorf("plan: %s lists no available trucks", path)
+	}
+	return trucks, nil
+}
+
+// loadPackages imports the package file into repo, failing on any bad row.
+func loadPackages(ctx context.Context, o options, repo *repositories.MemoryPackageRepository) error {
+	mapping, err := services.ParseImportMapping(o.mappings)
+	if err != nil {
+		return fmt.Errorf("plan: %w", err)
+	}
+
+	var src io.Reader = os.Stdin
+	if o.packages != "-" {
+		f, err := os.Open(o.packages)
+		if err != nil {
+			return fmt.Errorf("plan: %w", err)
+		}
+		defer f.Close()
+		src = f
+	}
+
+	req := services.ImportPackagesRequest{
+		Format:  services.ImportFormat(o.format),
+		Mode:    services.ImportModeAtomic,
+		Mapping: mapping,
+	}
+	result, err := services.ImportPackages(ctx, src, req, repo)
+	if err != nil {
+		return err
+	}
+	for _, e := range result.Errors {
+		if e.Field == "" {
+			log.Printf("row %d: %s", e.Row, e.Reason)
+			continue
+		}
+		log.Printf("row %d: %s: %s", e.Row, e.Field, e.Reason)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("plan: %d of %d rows in %s were rejected", len(result.Errors), result.Total, o.packages)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"delivery-route-service/internal/adapters/repositories"
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/services"
+)
+
+func TestParseFlags(t *testing.T) {
+	cfg := config.Default()
+
+	tests := []struct {
+		name    string
+		args    []string
+		check   func(t *testing.T, o options)
+		wantErr bool
+	}{
+		{
+			name: "defaults come from config",
+			args: []string{"-packages", "pkgs.csv"},
+			check: func(t *testing.T, o options) {
+				if o.format != string(services.ImportFormatCSV) {
+					t.Fatalf("expected csv format from the extension, got %q", o.format)
+				}
+				if o.hub != cfg.Server.HubAddress || o.trucks != cfg.Planning.DefaultTruckCount ||
+					o.capacity != cfg.Planning.DefaultTruckCapacity || o.tolerance != cfg.Planning.BalanceTolerance {
+					t.Fatalf("expected config defaults, got %+v", o)
+				}
+				if o.provider != providerEstimate || o.output != outputTable {
+					t.Fatalf("expected estimate provider and table output, got %q and %q", o.provider, o.output)
+				}
+			},
+		},
+		{
+			name: "format from json lines extension",
+			args: []string{"-packages", "pkgs.jsonl"},
+			check: func(t *testing.T, o options) {
+				if o.format != string(services.ImportFormatJSON) {
+					t.Fatalf("expected json format, got %q", o.format)
+				}
+			},
+		},
+		{
+			name: "explicit format and repeated mappings",
+			args: []string{"-packages", "-", "-format", "csv", "-map", "package_id=Order", "-map", "pickup=From"},
+			check: func(t *testing.T, o options) {
+				if o.format != "csv" {
+					t.Fatalf("expected csv format, got %q", o.format)
+				}
+				if want := (mapFlags{"package_id=Order", "pickup=From"}); !reflect.DeepEqual(o.mappings, want) {
+					t.Fatalf("expected mappings %v, got %v", want, o.mappings)
+				}
+			},
+		},
+		{
+			name: "matrix provider with file",
+			args: []string{"-packages", "pkgs.csv", "-provider", "matrix", "-matrix", "m.json"},
+			check: func(t *testing.T, o options) {
+				if o.provider != providerMatrix || o.matrix != "m.json" {
+					t.Fatalf("expected matrix provider reading m.json, got %q and %q", o.provider, o.matrix)
+				}
+			},
+		},
+		{name: "packages required", args: []string{"-trucks", "2"}, wantErr: true},
+		{name: "unknown extension needs format", args: []string{"-packages", "pkgs.txt"}, wantErr: true},
+		{name: "unknown output", args: []string{"-packages", "pkgs.csv", "-output", "xml"}, wantErr: true},
+		{name: "matrix provider needs file", args: []string{"-packages", "pkgs.csv", "-provider", "matrix"}, wantErr: true},
+		{name: "matrix file needs matrix provider", args: []string{"-packages", "pkgs.csv", "-matrix", "m.json"}, wantErr: true},
+		{name: "stray argument", args: []string{"-packages", "pkgs.csv", "extra"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := parseFlags(cfg, tt.args)
+			if tt.wantErr {
+				if !errors.Is(err, errUsage) {
+					t.Fatalf("expected usage error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, o)
+		})
+	}
+}
+
+func TestPlanRequest(t *testing.T) {
+	cfg := config.Default()
+	depart := time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)
+	fleet := []*domain.Truck{{TruckID: 7, Capacity: 10, Available: true}}
+
+	o, err := parseFlags(cfg, []string{
+		"-packages", "pkgs.csv",
+		"-hub", "  33.45,-112.07 ",
+		"-trucks", "3",
+		"-capacity", "12",
+		"-max-weight", "500",
+		"-max-volume", "2.5",
+		"-strategy", "two_opt",
+		"-objective", "makespan",
+		"-balance", "packages",
+		"-balance-tolerance", "0.25",
+		"-return",
+		"-service-stop", "2m",
+		"-service-package", "30s",
+		"-max-shift", "8h",
+		"-break-after", "4h",
+		"-break-duration", "30m",
+		"-multi-trip",
+		"-reload", "15m",
+		"-same-day-window", "10h",
+		"-express-window", "3h",
+		"-geometry",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := services.PlanDeliveriesRequest{
+		Hub:              "33.45,-112.07",
+		TruckCount:       3,
+		TruckCapacity:    12,
+		TruckMaxWeightKg: 500,
+		TruckMaxVolumeM3: 2.5,
+		Trucks:           fleet,
+		DepartAt:         depart,
+		ReturnToStart:    true,
+		Concurrency:      cfg.Planning.Concurrency,
+		IncludeGeometry:  true,
+		Strategy:         services.RouteStrategyTwoOpt,
+		Objective:        services.ObjectiveMakespan,
+		Balance:          services.Balance{Mode: services.BalancePackages, Tolerance: 0.25},
+		ServiceTime:      domain.ServiceTime{PerStop: 2 * time.Minute, PerPackage: 30 * time.Second},
+		Shift:            domain.Shift{MaxDuration: 8 * time.Hour, BreakAfter: 4 * time.Hour, BreakDuration: 30 * time.Minute},
+		MultiTrip:        true,
+		ReloadTime:       15 * time.Minute,
+		Promises:         domain.Promises{SameDay: 10 * time.Hour, Express: 3 * time.Hour},
+	}
+	if got := planRequest(cfg, o, depart, fleet); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected request\n%+v\ngot\n%+v", want, got)
+	}
+}
+
+func TestLoadPackages(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		file     string
+		mappings mapFlags
+		want     map[int]string
+		wantErr  bool
+	}{
+		{
+			name: "quoted pickup coordinates",
+			src:  "package_id,destination,pickup\n1,\"1 Main St, Phoenix\",\"33.45,-112.07\"\n2,2 Main St,\n",
+			file: "pkgs.csv",
+			want: map[int]string{1: "33.45,-112.07", 2: ""},
+		},
+		{
+			name:     "mapped columns",
+			src:      "Order,Ship To,From\n3,3 Main St,\"  4 Oak   Ave \"\n",
+			file:     "pkgs.csv",
+			mappings: mapFlags{"package_id=Order", "destination=Ship To", "pickup=From"},
+			want:     map[int]string{3: "4 Oak Ave"},
+		},
+		{
+			name: "json",
+			src:  `[{"package_id": 5, "destination": "5 Main St", "pickup": "33.45,-112.07"}]`,
+			file: "pkgs.json",
+			want: map[int]string{5: "33.45,-112.07"},
+		},
+		{
+			name:    "pickup equal to destination is rejected",
+			src:     "package_id,destination,pickup\n1,1 Main St,1 Main St\n",
+			file:    "pkgs.csv",
+			wantErr: true,
+		},
+		{
+			name:     "unknown mapped field",
+			src:      "package_id,destination\n1,1 Main St\n",
+			file:     "pkgs.csv",
+			mappings: mapFlags{"colour=Paint"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.src), 0o600); err != nil {
+				t.Fatalf("write package file: %v", err)
+			}
+			args := []string{"-packages", path}
+			for _, m := range tt.mappings {
+				args = append(args, "-map", m)
+			}
+			o, err := parseFlags(config.Default(), args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ctx := context.Background()
+			repo := repositories.NewMemoryPackageRepository()
+			err = loadPackages(ctx, o, repo)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pkgs, err := repo.ListPackages(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make(map[int]string, len(pkgs))
+			for _, p := range pkgs {
+				got[p.PackageID] = p.Pickup
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected pickups %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
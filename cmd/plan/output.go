@@ -0,0 +1,172 @@
+package main
+
+import (
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
+	"delivery-route-service/internal/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// writeResult renders result in the selected output format. JSON and GeoJSON
+// use the same shapes as the HTTP API, so files are interchangeable.
+func writeResult(
+	w io.Writer,
+	format string,
+	multiTrip bool,
+	objective services.Objective,
+	result *services.PlanDeliveriesResult,
+) error {
+	metrics := handlers.NewPlanMetricsResponse(result.Metrics, objective)
+	ungeocodable := handlers.NewUngeocodableResponses(result.Ungeocodable)
+	unassigned := handlers.NewUnassignedResponses(result.Unassigned)
+	missed := handlers.NewMissedPromiseResponses(result.MissedPromises)
+
+	switch format {
+	case outputJSON:
+		if multiTrip {
+			return writeIndented(w, dto.ListTruckTripsResponse{
+				Trucks:         dto.NewTruckTripsResponses(result.Plans),
+				Metrics:        metrics,
+				Ungeocodable:   ungeocodable,
+				Unassigned:     unassigned,
+				MissedPromises: missed,
+			})
+		}
+		res := dto.ListPlanResponse{
+			Plans:          make([]dto.PlanResponse, 0, len(result.Plans)),
+			Metrics:        metrics,
+			Ungeocodable:   ungeocodable,
+			Unassigned:     unassigned,
+			MissedPromises: missed,
+		}
+		for _, p := range result.Plans {
+			res.Plans = append(res.Plans, dto.NewPlanResponse(p))
+		}
+		return writeIndented(w, res)
+	case outputGeoJSON:
+		fc, err := export.PlansGeoJSON(result.Plans)
+		if err != nil {
+			return err
+		}
+		return writeIndented(w, struct {
+			*export.FeatureCollection
+			Ungeocodable   []dto.UngeocodableResponse  `json:"ungeocodable"`
+			Unassigned     []dto.UnassignedResponse    `json:"unassigned"`
+			MissedPromises []dto.MissedPromiseResponse `json:"missed_promises"`
+		}{fc, ungeocodable, unassigned, missed})
+	default:
+		return writeTable(w, metrics, result)
+	}
+}
+
+func writeIndented(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeTable prints one row per stop followed by per-truck totals, the
+// plan's totals in every objective and any skipped or unassigned destinations.
+func writeTable(w io.Writer, metrics dto.PlanMetricsResponse, result *services.PlanDeliveriesResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRUCK\tSTOP\tARRIVE\tDEPART\tKM\tPACKAGES\tDESTINATION")
+	for _, p := range result.Plans {
+		for i, s := range p.Stops {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%.1f\t%s\t%s\n",
+				truckLabel(p), i+1, s.ArriveAt.Format("15:04"), s.DepartAt.Format("15:04"),
+				float64(s.CumulativeDistanceMeters)/1000, joinIDs(s.PackageIDs), s.Destination)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRUCK\tSTOPS\tPACKAGES\tKG\tM3\tKM\tDURATION\tBREAKS\tCOST\tDEPART")
+	for _, p := range result.Plans {
+		packages := 0
+		for _, s := range p.Stops {
+			packages += len(s.PackageIDs)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%.1f\t%s\t%s\t%.2f\t%s\n",
+			truckLabel(p), len(p.Stops), packages,
+			usage(p.Load.WeightKg, p.Capacity.WeightKg), usage(p.Load.VolumeM3, p.Capacity.VolumeM3),
+			float64(p.TotalDistanceMeters)/1000,
+			time.Duration(p.TotalDurationSeconds)*time.Second, time.Duration(p.TotalBreakSeconds)*time.Second,
+			p.TotalCost, p.DepartAt.Format(time.RFC3339))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nobjective %s: %.1f km, %s total duration, cost %.2f, makespan %s\n",
+		metrics.Objective, float64(metrics.TotalDistanceMeters)/1000,
+		time.Duration(metrics.TotalDurationSeconds)*time.Second, metrics.TotalCost,
+		time.Duration(metrics.MakespanSeconds)*time.Second)
+	fmt.Fprintf(w, "route durations: min %s, max %s, stddev %s\n",
+		time.Duration(metrics.RouteDurations.MinSeconds)*time.Second,
+		time.Duration(metrics.RouteDurations.MaxSeconds)*time.Second,
+		time.Duration(metrics.RouteDurations.StdDevSeconds*float64(time.Second)).Round(time.Second))
+
+	if len(result.Ungeocodable) > 0 {
+		fmt.Fprintln(w)
+		for _, u := range result.Ungeocodable {
+			fmt.Fprintf(w, "skipped %q (packages %s): %s\n", u.Address, joinIDs(u.PackageIDs), u.Reason)
+		}
+	}
+	if len(result.Unassigned) > 0 {
+		fmt.Fprintln(w)
+		for _, u := range result.Unassigned {
+			fmt.Fprintf(w, "unassigned %q (packages %s): %s\n", u.Address, joinIDs(u.PackageIDs), u.Reason)
+		}
+	}
+	if len(result.MissedPromises) > 0 {
+		fmt.Fprintln(w)
+		for _, m := range result.MissedPromises {
+			if m.ArriveAt.IsZero() {
+				fmt.Fprintf(w, "missed promise: package %d due %s is not planned\n", m.PackageID, m.Deadline.Format(time.RFC3339))
+				continue
+			}
+			truck := truckLabel(&domain.RoutePlan{TruckID: m.TruckID, Trip: m.Trip})
+			fmt.Fprintf(w, "missed promise: package %d due %s arrives %s late on truck %s\n",
+				m.PackageID, m.Deadline.Format(time.RFC3339), m.ArriveAt.Sub(m.Deadline), truck)
+		}
+	}
+	return nil
+}
+
+// truckLabel names the truck of p, and its trip in multi-trip plans, e.g. "2 trip 3".
+func truckLabel(p *domain.RoutePlan) string {
+	if p.Trip > 0 {
+		return fmt.Sprintf("%d trip %d", p.TruckID, p.Trip)
+	}
+	return strconv.Itoa(p.TruckID)
+}
+
+// usage formats a load as "used/limit", or just "used" without a limit.
+func usage(used, limit float64) string {
+	// Rounding hides float noise from summing package weights.
+	s := strconv.FormatFloat(math.Round(used*1000)/1000, 'f', -1, 64)
+	if limit > 0 {
+		s += "/" + strconv.FormatFloat(limit, 'f', -1, 64)
+	}
+	return s
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ",")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"delivery-route-service/internal/adapters/cache"
 	"delivery-route-service/internal/adapters/distance"
 	"delivery-route-service/internal/adapters/repositories"
@@ -9,9 +10,7 @@ import (
 	"delivery-route-service/internal/platform/db"
 	"log"
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
@@ -24,11 +23,12 @@ func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found (using environment variables)")
 	}
-	hub := config.Get("HUB_ADDRESS", "1901 W Madison St, Phoenix, AZ 85009")
-	port := config.Get("PORT", "8080")
-	orsKey := os.Getenv("ORS_API_KEY")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	databaseURL := os.Getenv("DATABASE_URL")
+	databaseURL := cfg.Database.URL
 	if strings.TrimSpace(databaseURL) == "" {
 		log.Fatal("DATABASE_URL is required")
 	}
@@ -38,7 +38,17 @@ func main() {
 	}
 	defer db.Close()
 
-	redisURL := os.Getenv("REDIS_URL")
+	// Replicas starting together serialize on the migration lock, so only
+	// one of them applies each pending migration.
+	if cfg.Database.AutoMigrate {
+		applied, err := repositories.MigrateUp(context.Background(), db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Schema migrated applied=%d", len(applied))
+	}
+
+	redisURL := cfg.Redis.URL
 	if strings.TrimSpace(redisURL) == "" {
 		log.Fatal("REDIS_URL is required")
 	}
@@ -48,29 +58,41 @@ func main() {
 	}
 	rdb := redis.NewClient(opt)
 
-	if strings.TrimSpace(orsKey) == "" {
+	if strings.TrimSpace(cfg.ORS.APIKey) == "" {
 		log.Fatal("ORS_API_KEY is required")
 	}
 
-	// ORS provider uses persistent DB caches to avoid repeated geocode/matrix calls.
-	distanceCache := cache.NewRedisDistanceCache(rdb)
-	geocodeCache := cache.NewRedisGeocodeCache(rdb)
-	provider, err := distance.NewORSDistanceProvider(orsKey, distanceCache, geocodeCache)
+	// ORS provider uses persistent DB caches to avoid repeated geocode/matrix calls
+	// and consults manual overrides before geocoding.
+	distanceCache := cache.NewRedisDistanceCache(rdb, cfg.Cache.TTL)
+	geocodeCache := cache.NewRedisGeocodeCache(rdb, cfg.Cache.TTL, cfg.Cache.NegativeTTL)
+	directionsCache := cache.NewRedisDirectionsCache(rdb, cfg.Cache.TTL)
+	overrides := repositories.NewSQLGeocodeOverrideRepository(db)
+	provider, err := distance.NewORSDistanceProvider(cfg.ORS, distanceCache, geocodeCache, overrides, directionsCache)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	repo := repositories.NewSQLPackageRepository(db)
-	router := api.NewRouter(repo, provider, hub)
+	router := api.NewRouter(api.Dependencies{
+		Packages:            repo,
+		Provider:            provider,
+		Overrides:           overrides,
+		Trucks:              repositories.NewSQLTruckRepository(db),
+		Hubs:                repositories.NewSQLHubRepository(db),
+		DistanceInvalidator: distanceCache,
+		DefaultHub:          cfg.Server.HubAddress,
+		Planning:            cfg.Planning,
+	})
 	// Timeouts are tuned for cold-cache route planning (external API latency).
-	log.Printf("Server listening addr=:%s", port)
+	log.Printf("Server listening addr=:%s", cfg.Server.Port)
 	srv := &http.Server{
-		Addr:              ":" + port,
+		Addr:              ":" + cfg.Server.Port,
 		Handler:           router,
-		ReadHeaderTimeout: 5 * time.Second,
-		ReadTimeout:       10 * time.Second,
-		WriteTimeout:      120 * time.Second,
-		IdleTimeout:       60 * time.Second,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 	log.Fatal(srv.ListenAndServe())
 }
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPatterns maps each cache kind to the Redis keys it owns.
+var keyPatterns = map[string]string{
+	"distance":     "distance:*",
+	"geocode":      "geocode:*",
+	"geocode_fail": "geocode_fail:*",
+	"directions":   "directions:*",
+}
+
+// Kinds returns the cache kinds accepted by Flush, sorted.
+func Kinds() []string {
+	kinds := make([]string, 0, len(keyPatterns))
+	for k := range keyPatterns {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Flush deletes cached entries of the given kinds, or of every kind when none
+// are given, and returns how many keys were removed. Other keys in the Redis
+// database are left alone. Keys are found with SCAN so large caches are not blocked.
+func Flush(ctx context.Context, client *redis.Client, kinds ...string) (int, error) {
+	if client == nil {
+		return 0, errors.New("cache flush: client is nil")
+	}
+	if len(kinds) == 0 {
+		kinds = Kinds()
+	}
+
+	deleted := 0
+	for _, kind := range kinds {
+		pattern, ok := keyPatterns[kind]
+		if !ok {
+			return deleted, fmt.Errorf("cache flush: unknown kind %q; expected one of %s", kind, strings.Join(Kinds(), ", "))
+		}
+
+		iter := client.Scan(ctx, 0, pattern, 500).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return deleted, fmt.Errorf("cache flush scan %q: %w", pattern, err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		n, err := client.Del(ctx, keys...).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("cache flush delete %d keys: %w", len(keys), err)
+		}
+		deleted += int(n)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,66 @@
+package cache_test
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/cache"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name        string
+		kinds       []string
+		wantDeleted int
+		wantKept    []string
+		wantErr     bool
+	}{
+		{
+			name:        "all kinds",
+			wantDeleted: 4,
+			wantKept:    []string{"session:1"},
+		},
+		{
+			name:        "geocode only keeps failures and distances",
+			kinds:       []string{"geocode"},
+			wantDeleted: 1,
+			wantKept:    []string{"distance:A|B", "geocode_fail:C", "directions:abc", "session:1"},
+		},
+		{
+			name:    "unknown kind",
+			kinds:   []string{"sessions"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			for _, k := range []string{"distance:A|B", "geocode:A", "geocode_fail:C", "directions:abc", "session:1"} {
+				mr.Set(k, "x")
+			}
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+			deleted, err := cache.Flush(context.Background(), client, tc.kinds...)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if deleted != tc.wantDeleted {
+				t.Fatalf("expected %d deleted, got %d", tc.wantDeleted, deleted)
+			}
+			for _, k := range tc.wantKept {
+				if !mr.Exists(k) {
+					t.Fatalf("expected %q to be kept", k)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/platform/obs"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisDirectionsCache is a Redis-backed cache for route geometry keyed by
+// the ordered waypoint list.
+type RedisDirectionsCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisDirectionsCache returns a cache whose entries expire after ttl.
+// A non-positive ttl falls back to DefaultTTL.
+func NewRedisDirectionsCache(client *redis.Client, ttl time.Duration) *RedisDirectionsCache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &RedisDirectionsCache{client: client, ttl: ttl}
+}
+
+// directionsKey hashes the waypoints since routes can exceed practical key lengths.
+func directionsKey(waypoints []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(waypoints, "\n")))
+	return "directions:" + hex.EncodeToString(sum[:])
+}
+
+// Fetch cached geometry for an ordered waypoint list.
+func (r *RedisDirectionsCache) Get(
+	ctx context.Context,
+	waypoints []string,
+) (_ *domain.RouteGeometry, _ bool, err error) {
+	defer obs.Time(ctx, "directions.cache.Get")(&err)
+
+	if r.client == nil {
+		return nil, false, errors.New("directions cache: db is nil")
+	}
+	if len(waypoints) < 2 {
+		return nil, false, errors.New("get directions cache: at least two waypoints are required")
+	}
+
+	key := directionsKey(waypoints)
+	val, err := r.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("directions cache get %q: %w", key, err)
+	}
+
+	var geometry domain.RouteGeometry
+	if err := json.Unmarshal([]byte(val), &geometry); err != nil {
+		return nil, false, fmt.Errorf("directions cache unmarshal %q: %w", key, err)
+	}
+
+	return &geometry, true, nil
+}
+
+// Store geometry for an ordered waypoint list.
+func (r *RedisDirectionsCache) Put(
+	ctx context.Context,
+	waypoints []string,
+	geometry *domain.RouteGeometry,
+) error {
+	if r.client == nil {
+		return errors.New("directions cache: db is nil")
+	}
+	if len(waypoints) < 2 {
+		return errors.New("insert directions cache: at least two waypoints are required")
+	}
+	if geometry == nil {
+		return errors.New("insert directions cache: geometry must not be nil")
+	}
+
+	key := directionsKey(waypoints)
+	val, err := json.Marshal(geometry)
+	if err != nil {
+		return fmt.Errorf("directions cache marshal %q: %w", key, err)
+	}
+	if err := r.client.Set(ctx, key, val, r.ttl).Err(); err != nil {
+		return fmt.Errorf("directions cache set %q: %w", key, err)
+	}
+
+	return nil
+}
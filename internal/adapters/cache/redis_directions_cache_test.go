@@ -0,0 +1,67 @@
+package cache_test
+
+import (
+	"context"
+	"delivery-route-service/internal/adapters/cache"
+	"delivery-route-service/internal/domain"
+	"reflect"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisDirectionsCacheRoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	c := cache.NewRedisDirectionsCache(client, cache.DefaultTTL)
+	ctx := context.Background()
+
+	waypoints := []string{"HUB", "DestA", "DestB"}
+	stored := &domain.RouteGeometry{
+		Polyline: "_p~iF~ps|U_ulLnnqC",
+		Legs: []domain.RouteLeg{
+			{From: "HUB", To: "DestA", DistanceMeters: 100, DurationSeconds: 60,
+				Steps: []domain.DirectionStep{{Instruction: "Head north", Street: "Main St", DistanceMeters: 100, DurationSeconds: 60}}},
+			{From: "DestA", To: "DestB", DistanceMeters: 200, DurationSeconds: 120},
+		},
+	}
+
+	if _, ok, err := c.Get(ctx, waypoints); err != nil || ok {
+		t.Fatalf("expected miss before Put, got ok=%v err=%v", ok, err)
+	}
+
+	if err := c.Put(ctx, waypoints, stored); err != nil {
+		t.Fatalf("Put: unexpected error: %v", err)
+	}
+
+	got, ok, err := c.Get(ctx, waypoints)
+	if err != nil || !ok {
+		t.Fatalf("Get: expected hit, got ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(got, stored) {
+		t.Fatalf("expected %+v, got %+v", stored, got)
+	}
+
+	// Order matters: the reversed route is a different path.
+	if _, ok, err := c.Get(ctx, []string{"DestB", "DestA", "HUB"}); err != nil || ok {
+		t.Fatalf("expected miss for reversed waypoints, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestRedisDirectionsCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	nilCache := cache.NewRedisDirectionsCache(nil, cache.DefaultTTL)
+	if _, _, err := nilCache.Get(ctx, []string{"A", "B"}); err == nil {
+		t.Fatalf("expected error when client is nil")
+	}
+
+	mr := miniredis.RunT(t)
+	c := cache.NewRedisDirectionsCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), cache.DefaultTTL)
+	if _, _, err := c.Get(ctx, []string{"A"}); err == nil {
+		t.Fatalf("expected error for a single waypoint")
+	}
+	if err := c.Put(ctx, []string{"A", "B"}, nil); err == nil {
+		t.Fatalf("expected error for nil geometry")
+	}
+}
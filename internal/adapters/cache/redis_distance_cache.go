@@ -13,13 +13,25 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// Cache entry lifetimes used when none are configured.
+const (
+	DefaultTTL         = 24 * time.Hour
+	DefaultNegativeTTL = 6 * time.Hour
+)
+
 // RedisDistanceCache is a Redis-backed cache for origin->destination distance results.
 type RedisDistanceCache struct {
 	client *redis.Client
+	ttl    time.Duration
 }
 
-func NewRedisDistanceCache(client *redis.Client) *RedisDistanceCache {
-	return &RedisDistanceCache{client: client}
+// NewRedisDistanceCache returns a cache whose entries expire after ttl.
+// A non-positive ttl falls back to DefaultTTL.
+func NewRedisDistanceCache(client *redis.Client, ttl time.Duration) *RedisDistanceCache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &RedisDistanceCache{client: client, ttl: ttl}
 }
 
 // Fetch cached distances for one origin and multiple destinations.
@@ -136,7 +148,7 @@ func (r *RedisDistanceCache) PutMany(
 		if err != nil {
 			return fmt.Errorf("distance cache marshal %q: %w", key, err)
 		}
-		pipe.Set(ctx, key, val, 24*time.Hour)
+		pipe.Set(ctx, key, val, r.ttl)
 	}
 	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("distance cache pipeline exec: %w", err)
@@ -144,3 +156,55 @@ func (r *RedisDistanceCache) PutMany(
 
 	return nil
 }
+
+// InvalidateAddress removes every cached distance where address is the origin
+// or a destination. Keys are found with SCAN so large caches are not blocked.
+func (r *RedisDistanceCache) InvalidateAddress(ctx context.Context, address string) (err error) {
+	defer obs.Time(ctx, "distance.cache.InvalidateAddress")(&err)
+
+	if r.client == nil {
+		return errors.New("distance cache: db is nil")
+	}
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return errors.New("invalidate distance cache: address must not be empty")
+	}
+
+	escaped := escapeGlob(address)
+	patterns := []string{
+		fmt.Sprintf("distance:%s|*", escaped),
+		fmt.Sprintf("distance:*|%s", escaped),
+	}
+
+	for _, pattern := range patterns {
+		iter := r.client.Scan(ctx, 0, pattern, 500).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("distance cache scan %q: %w", pattern, err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		if err := r.client.Del(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("distance cache delete %d keys: %w", len(keys), err)
+		}
+	}
+
+	return nil
+}
+
+// escapeGlob escapes Redis MATCH pattern metacharacters so addresses match literally.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
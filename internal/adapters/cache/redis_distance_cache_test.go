@@ -68,12 +68,12 @@ func TestRedisDistanceCacheGetMany(t *testing.T) {
 
 			var c *cache.RedisDistanceCache
 			if tt.nilClient {
-				c = cache.NewRedisDistanceCache(nil)
+				c = cache.NewRedisDistanceCache(nil, cache.DefaultTTL)
 			} else {
 				for key, val := range tt.seedData {
 					mr.Set(key, val)
 				}
-				c = cache.NewRedisDistanceCache(client)
+				c = cache.NewRedisDistanceCache(client, cache.DefaultTTL)
 			}
 
 			result , err := c.GetMany(ctx, tt.origin, tt.destinations)
@@ -139,9 +139,9 @@ func TestRedisDistanceCachePutMany(t *testing.T) {
 
 			var c *cache.RedisDistanceCache
 			if tt.nilClient {
-				c = cache.NewRedisDistanceCache(nil)
+				c = cache.NewRedisDistanceCache(nil, cache.DefaultTTL)
 			} else {
-				c = cache.NewRedisDistanceCache(client)
+				c = cache.NewRedisDistanceCache(client, cache.DefaultTTL)
 			}
 
 			err := c.PutMany(ctx, tt.origin, tt.results)
@@ -162,7 +162,7 @@ func TestRedisDistanceCachePutMany(t *testing.T) {
 func TestRedisDistanceCacheRoundTrip(t *testing.T) {
 	mr := miniredis.RunT(t)
 	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
-	c := cache.NewRedisDistanceCache(client)
+	c := cache.NewRedisDistanceCache(client, cache.DefaultTTL)
 
 	t.Run("values stored are retrievable by GetMany", func(t *testing.T) {
 		ctx := context.Background()
@@ -200,3 +200,30 @@ func TestRedisDistanceCacheRoundTrip(t *testing.T) {
 		}
 	})
 }
+
+func TestRedisDistanceCacheInvalidateAddress(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	c := cache.NewRedisDistanceCache(client, cache.DefaultTTL)
+
+	ctx := context.Background()
+	mr.Set("distance:HUB|DestA", `{"DistanceMeters":100,"DurationSeconds":60}`)
+	mr.Set("distance:HUB|DestB", `{"DistanceMeters":200,"DurationSeconds":120}`)
+	mr.Set("distance:DestA|DestB", `{"DistanceMeters":300,"DurationSeconds":180}`)
+	mr.Set("distance:DestB|HUB", `{"DistanceMeters":200,"DurationSeconds":120}`)
+
+	if err := c.InvalidateAddress(ctx, "DestA"); err != nil {
+		t.Fatalf("InvalidateAddress: unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"distance:HUB|DestA", "distance:DestA|DestB"} {
+		if mr.Exists(key) {
+			t.Fatalf("expected %q to be removed", key)
+		}
+	}
+	for _, key := range []string{"distance:HUB|DestB", "distance:DestB|HUB"} {
+		if !mr.Exists(key) {
+			t.Fatalf("expected %q to be kept", key)
+		}
+	}
+}
@@ -14,12 +14,25 @@ import (
 )
 
 // RedisGeocodeCache is a Redis-backed cache mapping addresses to coordinates.
+// Known-bad addresses are stored under a separate key prefix with their own,
+// usually shorter, TTL so fixes upstream are picked up without a manual flush.
 type RedisGeocodeCache struct {
-	client *redis.Client
+	client      *redis.Client
+	ttl         time.Duration
+	negativeTTL time.Duration
 }
 
-func NewRedisGeocodeCache(client *redis.Client) *RedisGeocodeCache {
-	return &RedisGeocodeCache{client: client}
+// NewRedisGeocodeCache returns a cache whose coordinate entries expire after ttl
+// and whose failure entries expire after negativeTTL.
+// Non-positive values fall back to DefaultTTL and DefaultNegativeTTL.
+func NewRedisGeocodeCache(client *redis.Client, ttl, negativeTTL time.Duration) *RedisGeocodeCache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = DefaultNegativeTTL
+	}
+	return &RedisGeocodeCache{client: client, ttl: ttl, negativeTTL: negativeTTL}
 }
 
 // Fetch cached coordinates for the given addresses.
@@ -124,7 +137,83 @@ func (r *RedisGeocodeCache) PutMany(ctx context.Context, results map[string]doma
 		if err != nil {
 			return fmt.Errorf("geocode cache marshal %q: %w", key, err)
 		}
-		pipe.Set(ctx, key, val, 24*time.Hour)
+		pipe.Set(ctx, key, val, r.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("geocode cache pipeline exec: %w", err)
+	}
+
+	return nil
+}
+
+// Fetch cached failure reasons for addresses known not to geocode.
+func (r *RedisGeocodeCache) GetFailures(
+	ctx context.Context,
+	addresses []string,
+) (_ map[string]string, err error) {
+	defer obs.Time(ctx, "geocode.cache.GetFailures")(&err)
+
+	if r.client == nil {
+		return nil, errors.New("geocode cache: db is nil")
+	}
+	if len(addresses) == 0 {
+		return map[string]string{}, nil
+	}
+
+	unique := make([]string, 0, len(addresses))
+	seen := map[string]struct{}{}
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		unique = append(unique, addr)
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(unique))
+	for i, addr := range unique {
+		cmds[i] = pipe.Get(ctx, fmt.Sprintf("geocode_fail:%s", addr))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("geocode cache pipeline exec: %w", err)
+	}
+
+	out := make(map[string]string)
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geocode cache get failure %q: %w", unique[i], err)
+		}
+		out[unique[i]] = val
+	}
+
+	return out, nil
+}
+
+// Store address -> failure reason mappings with the negative TTL.
+func (r *RedisGeocodeCache) PutFailures(ctx context.Context, failures map[string]string) error {
+	if r.client == nil {
+		return errors.New("geocode cache: db is nil")
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for addr, reason := range failures {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		pipe.Set(ctx, fmt.Sprintf("geocode_fail:%s", addr), reason, r.negativeTTL)
 	}
 	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("geocode cache pipeline exec: %w", err)
@@ -5,6 +5,7 @@ import (
 	"delivery-route-service/internal/adapters/cache"
 	"delivery-route-service/internal/domain"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
@@ -58,12 +59,12 @@ func TestRedisGeocodeCacheGetMany(t *testing.T) {
 
 			var c *cache.RedisGeocodeCache
 			if tt.nilClient {
-				c = cache.NewRedisGeocodeCache(nil)
+				c = cache.NewRedisGeocodeCache(nil, cache.DefaultTTL, cache.DefaultNegativeTTL)
 			} else {
 				for key, val := range tt.seedData {
 					mr.Set(key, val)
 				}
-				c = cache.NewRedisGeocodeCache(client)
+				c = cache.NewRedisGeocodeCache(client, cache.DefaultTTL, cache.DefaultNegativeTTL)
 			}
 
 			result, err := c.GetMany(ctx, tt.addresses)
@@ -128,9 +129,9 @@ func TestRedisGeocodeCachePutMany(t *testing.T) {
 
 			var c *cache.RedisGeocodeCache
 			if tt.nilClient {
-				c = cache.NewRedisGeocodeCache(nil)
+				c = cache.NewRedisGeocodeCache(nil, cache.DefaultTTL, cache.DefaultNegativeTTL)
 			} else {
-				c = cache.NewRedisGeocodeCache(client)
+				c = cache.NewRedisGeocodeCache(client, cache.DefaultTTL, cache.DefaultNegativeTTL)
 			}
 
 			err := c.PutMany(ctx, tt.results)
@@ -151,7 +152,7 @@ func TestRedisGeocodeCachePutMany(t *testing.T) {
 func TestRedisGeocodeCacheRoundTrip(t *testing.T) {
 	mr := miniredis.RunT(t)
 	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
-	c := cache.NewRedisGeocodeCache(client)
+	c := cache.NewRedisGeocodeCache(client, cache.DefaultTTL, cache.DefaultNegativeTTL)
 
 	t.Run("values stored are retrievable by GetMany", func(t *testing.T) {
 		ctx := context.Background()
@@ -188,3 +189,36 @@ func TestRedisGeocodeCacheRoundTrip(t *testing.T) {
 		}
 	})
 }
+
+func TestRedisGeocodeCacheFailuresRoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	c := cache.NewRedisGeocodeCache(client, cache.DefaultTTL, time.Hour)
+
+	ctx := context.Background()
+	failures := map[string]string{"BadAddr": "no geocode results"}
+	if err := c.PutFailures(ctx, failures); err != nil {
+		t.Fatalf("PutFailures: unexpected error: %v", err)
+	}
+
+	got, err := c.GetFailures(ctx, []string{"BadAddr", "GoodAddr"})
+	if err != nil {
+		t.Fatalf("GetFailures: unexpected error: %v", err)
+	}
+	if len(got) != 1 || got["BadAddr"] != "no geocode results" {
+		t.Fatalf("expected only BadAddr failure, got %+v", got)
+	}
+
+	// Failures must not be visible as coordinates.
+	coords, err := c.GetMany(ctx, []string{"BadAddr"})
+	if err != nil {
+		t.Fatalf("GetMany: unexpected error: %v", err)
+	}
+	if len(coords) != 0 {
+		t.Fatalf("expected no coordinates for failed address, got %+v", coords)
+	}
+
+	if ttl := mr.TTL("geocode_fail:BadAddr"); ttl != time.Hour {
+		t.Fatalf("expected negative ttl 1h, got %s", ttl)
+	}
+}
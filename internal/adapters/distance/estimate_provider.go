@@ -0,0 +1,128 @@
+package distance
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	earthRadiusMeters = 6371000.0
+	// Road distance is longer than the great-circle distance; 1.3 is a common
+	// urban detour factor.
+	estimateDetourFactor = 1.3
+	// DefaultEstimateSpeedKPH is the average driving speed assumed when none is given.
+	DefaultEstimateSpeedKPH = 40.0
+
+	estimateUnlocatedReason = `offline estimate needs a "lat,lon" position or stored coordinates`
+)
+
+// EstimateDistanceProvider approximates road distance and travel time from
+// straight-line distance, without any network calls. Addresses must be
+// "lat,lon" labels or come with known coordinates; nothing is geocoded.
+//
+// The provider is safe for concurrent use.
+type EstimateDistanceProvider struct {
+	metersPerSecond float64
+}
+
+// NewEstimateDistanceProvider builds a provider assuming an average driving
+// speed of speedKPH.
+func NewEstimateDistanceProvider(speedKPH float64) (*EstimateDistanceProvider, error) {
+	if speedKPH <= 0 || math.IsInf(speedKPH, 0) || math.IsNaN(speedKPH) {
+		return nil, fmt.Errorf("estimate speed must be positive, got %v", speedKPH)
+	}
+	return &EstimateDistanceProvider{metersPerSecond: speedKPH * 1000 / 3600}, nil
+}
+
+// positionOf resolves an address from a "lat,lon" label or known coordinates.
+func positionOf(address string, known map[string]domain.Coordinates) (domain.Coordinates, bool) {
+	if c, ok := known[address]; ok {
+		return c, true
+	}
+	return domain.ParseCoordinates(address)
+}
+
+func (e *EstimateDistanceProvider) estimate(from, to domain.Coordinates) ports.DistanceResult {
+	meters := haversineMeters(from, to) * estimateDetourFactor
+	return ports.DistanceResult{
+		DistanceMeters:  int(math.Round(meters)),
+		DurationSeconds: int(math.Round(meters / e.metersPerSecond)),
+	}
+}
+
+func (e *EstimateDistanceProvider) GetDistance(
+	ctx context.Context,
+	origin string,
+	destination string,
+) (ports.DistanceResult, error) {
+	results, err := e.GetDistancesWithCoordinates(ctx, origin, []string{destination}, nil)
+	if err != nil {
+		return ports.DistanceResult{}, err
+	}
+	return results[destination], nil
+}
+
+func (e *EstimateDistanceProvider) GetDistances(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+) (map[string]ports.DistanceResult, error) {
+	return e.GetDistancesWithCoordinates(ctx, origin, destinations, nil)
+}
+
+// GetDistancesWithCoordinates estimates distances from origin to each
+// destination. An address without a position is an AddressError.
+func (e *EstimateDistanceProvider) GetDistancesWithCoordinates(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+	known map[string]domain.Coordinates,
+) (map[string]ports.DistanceResult, error) {
+	from, ok := positionOf(origin, known)
+	if !ok {
+		return nil, &ports.AddressError{Address: origin, Reason: estimateUnlocatedReason}
+	}
+
+	out := make(map[string]ports.DistanceResult, len(destinations))
+	for _, d := range destinations {
+		to, ok := positionOf(d, known)
+		if !ok {
+			return nil, &ports.AddressError{Address: d, Reason: estimateUnlocatedReason}
+		}
+		out[d] = e.estimate(from, to)
+	}
+
+	return out, nil
+}
+
+// GeocodeAddresses parses "lat,lon" labels; any other address is reported as
+// a failure so planning can skip it.
+func (e *EstimateDistanceProvider) GeocodeAddresses(
+	ctx context.Context,
+	addresses []string,
+) (coords map[string]domain.Coordinates, failures map[string]string, err error) {
+	coords = make(map[string]domain.Coordinates, len(addresses))
+	failures = make(map[string]string)
+	for _, a := range addresses {
+		if c, ok := domain.ParseCoordinates(strings.TrimSpace(a)); ok {
+			coords[a] = c
+			continue
+		}
+		failures[a] = estimateUnlocatedReason
+	}
+	return coords, failures, nil
+}
+
+// haversineMeters returns the great-circle distance between two positions.
+func haversineMeters(a, b domain.Coordinates) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(b.Lat - a.Lat)
+	dLon := rad(b.Lon - a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(a.Lat))*math.Cos(rad(b.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
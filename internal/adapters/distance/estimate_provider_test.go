@@ -0,0 +1,57 @@
+package distance_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+)
+
+func TestEstimateDistanceProvider(t *testing.T) {
+	p, err := distance.NewEstimateDistanceProvider(36)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	// 0.01 degrees of latitude is about 1112 m; with the 1.3 detour factor
+	// that is about 1446 m, or 145 s at 10 m/s.
+	r, err := p.GetDistance(ctx, "33.00,-112.00", "33.01,-112.00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.DistanceMeters < 1440 || r.DistanceMeters > 1450 || r.DurationSeconds < 144 || r.DurationSeconds > 146 {
+		t.Fatalf("expected about 1446 m / 145 s, got %+v", r)
+	}
+
+	known := map[string]domain.Coordinates{"Depot": {Lat: 33.00, Lon: -112.00}}
+	rs, err := p.GetDistancesWithCoordinates(ctx, "Depot", []string{"33.01,-112.00"}, known)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rs["33.01,-112.00"] != r {
+		t.Fatalf("expected known coordinates to give %+v, got %+v", r, rs["33.01,-112.00"])
+	}
+
+	if _, err := p.GetDistance(ctx, "33.00,-112.00", "1 Main St"); !errors.Is(err, ports.ErrAddressNotGeocodable) {
+		t.Fatalf("expected address error for an unplaced address, got %v", err)
+	}
+
+	coords, failures, err := p.GeocodeAddresses(ctx, []string{"33.01,-112.00", "1 Main St"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := coords["33.01,-112.00"]; !ok {
+		t.Fatalf("expected lat,lon label to resolve, got %v", coords)
+	}
+	if _, ok := failures["1 Main St"]; !ok {
+		t.Fatalf("expected street address to be reported as a failure, got %v", failures)
+	}
+
+	if _, err := distance.NewEstimateDistanceProvider(0); err == nil {
+		t.Fatalf("expected error for zero speed")
+	}
+}
@@ -0,0 +1,132 @@
+package distance
+
+import (
+	"context"
+	"delivery-route-service/internal/ports"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MatrixPair is a directed pair with no cached distance.
+type MatrixPair struct {
+	Origin      string
+	Destination string
+}
+
+// ExportCachedMatrix reads every cached distance between the given addresses,
+// in both directions, so a plan over them can be replayed with
+// MatrixDistanceProvider. Addresses are normalized the way the ORS provider
+// keys its cache. Entries and missing pairs are sorted by origin, then
+// destination, so exports of the same cache are byte-identical.
+func ExportCachedMatrix(
+	ctx context.Context,
+	cache ports.DistanceCache,
+	addresses []string,
+) (entries []MatrixEntry, missing []MatrixPair, err error) {
+	seen := make(map[string]struct{}, len(addresses))
+	norm := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		a = strings.Join(strings.Fields(a), " ")
+		if a == "" {
+			continue
+		}
+		if _, ok := seen[a]; ok {
+			continue
+		}
+		seen[a] = struct{}{}
+		norm = append(norm, a)
+	}
+	sort.Strings(norm)
+
+	for _, origin := range norm {
+		targets := make([]string, 0, len(norm)-1)
+		for _, d := range norm {
+			if d != origin {
+				targets = append(targets, d)
+			}
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		hits, err := cache.GetMany(ctx, origin, targets)
+		if err != nil {
+			return nil, nil, fmt.Errorf("export cached matrix: origin %q: %w", origin, err)
+		}
+		for _, d := range targets {
+			r, ok := hits[d]
+			if !ok {
+				missing = append(missing, MatrixPair{Origin: origin, Destination: d})
+				continue
+			}
+			entries = append(entries, MatrixEntry{
+				Origin:      origin,
+				Destination: d,
+				Meters:      r.DistanceMeters,
+				Seconds:     r.DurationSeconds,
+			})
+		}
+	}
+
+	return entries, missing, nil
+}
+
+// WriteMatrixJSON writes entries as an indented JSON array.
+func WriteMatrixJSON(w io.Writer, entries []MatrixEntry) error {
+	if entries == nil {
+		entries = []MatrixEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// WriteMatrixCSV writes entries with an origin,destination,meters,seconds header.
+func WriteMatrixCSV(w io.Writer, entries []MatrixEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(matrixHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		rec := []string{e.Origin, e.Destination, strconv.Itoa(e.Meters), strconv.Itoa(e.Seconds)}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// SaveMatrixFile writes entries to a .json or .csv file, the formats
+// LoadMatrixFile reads.
+func SaveMatrixFile(path string, entries []MatrixEntry) error {
+	var write func(io.Writer, []MatrixEntry) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		write = WriteMatrixJSON
+	case ".csv":
+		write = WriteMatrixCSV
+	default:
+		return fmt.Errorf("save distance matrix: unsupported extension %q (expected .json or .csv)", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("save distance matrix: %w", err)
+	}
+	if err := write(f, entries); err != nil {
+		f.Close()
+		return fmt.Errorf("save distance matrix %q: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("save distance matrix %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package distance_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"delivery-route-service/internal/adapters/cache"
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"delivery-route-service/internal/services"
+	"delivery-route-service/internal/testutil"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestExportCachedMatrixReplaysPlan checks that a plan computed from live
+// distances is reproduced exactly from a matrix exported out of the cache.
+func TestExportCachedMatrixReplaysPlan(t *testing.T) {
+	ctx := context.Background()
+	addresses := []string{"Hub", "A St", "B St", "C St"}
+
+	var pairs []testutil.MockPair
+	for i, from := range addresses {
+		for j, to := range addresses {
+			if i != j {
+				pairs = append(pairs, testutil.MockPair{From: from, To: to, Meters: 1000 * (i + 2*j + 1), Seconds: 60 * (2*i + j + 1)})
+			}
+		}
+	}
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	distanceCache := cache.NewRedisDistanceCache(rdb, time.Hour)
+	for _, p := range pairs {
+		r := ports.DistanceResult{DistanceMeters: p.Meters, DurationSeconds: p.Seconds}
+		if err := distanceCache.PutMany(ctx, p.From, map[string]ports.DistanceResult{p.To: r}); err != nil {
+			t.Fatalf("seed cache: %v", err)
+		}
+	}
+
+	entries, missing, err := distance.ExportCachedMatrix(ctx, distanceCache, append(addresses, " A  St "))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(missing) != 0 || len(entries) != len(pairs) {
+		t.Fatalf("expected %d entries and no missing pairs, got %d entries, missing %v", len(pairs), len(entries), missing)
+	}
+
+	var buf bytes.Buffer
+	if err := distance.WriteMatrixCSV(&buf, entries); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	readBack, err := distance.ReadMatrixCSV(&buf)
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	matrix, err := distance.NewMatrixDistanceProvider(readBack)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo := testutil.NewMockPackageRepository([]*domain.Package{
+		{PackageID: 1, Destination: "A St"},
+		{PackageID: 2, Destination: "B St"},
+		{PackageID: 3, Destination: "C St"},
+		{PackageID: 4, Destination: "A St"},
+	}, nil)
+	req := services.PlanDeliveriesRequest{
+		Hub:           "Hub",
+		TruckCount:    2,
+		TruckCapacity: 3,
+		DepartAt:      time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC),
+		ReturnToStart: true,
+	}
+
+	live, err := services.PlanDeliveries(ctx, req, repo, testutil.NewMockDistanceProvider(pairs))
+	if err != nil {
+		t.Fatalf("live plan: %v", err)
+	}
+	replayed, err := services.PlanDeliveries(ctx, req, repo, matrix)
+	if err != nil {
+		t.Fatalf("replayed plan: %v", err)
+	}
+	if !reflect.DeepEqual(live, replayed) {
+		t.Fatalf("expected replayed plan to match live plan\nlive:     %+v\nreplayed: %+v", live.Plans, replayed.Plans)
+	}
+
+	// A pair that was never cached is reported rather than silently dropped.
+	mr.Del("distance:C St|Hub")
+	_, missing, err = distance.ExportCachedMatrix(ctx, distanceCache, addresses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []distance.MatrixPair{{Origin: "C St", Destination: "Hub"}}; !reflect.DeepEqual(missing, want) {
+		t.Fatalf("expected missing %v, got %v", want, missing)
+	}
+}
@@ -0,0 +1,164 @@
+package distance
+
+import (
+	"context"
+	"delivery-route-service/internal/ports"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MatrixEntry is one directed pair in a distance matrix file.
+type MatrixEntry struct {
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	Meters      int    `json:"meters"`
+	Seconds     int    `json:"seconds"`
+}
+
+// matrixHeader is the CSV column order of a distance matrix file.
+var matrixHeader = []string{"origin", "destination", "meters", "seconds"}
+
+// MatrixDistanceProvider serves distances from a precomputed matrix, so plans
+// can run without network access and give the same result on every run.
+// Addresses are matched after collapsing whitespace, as ORSDistanceProvider
+// does. A pair missing from the matrix is an error.
+//
+// The provider is read-only after construction and safe for concurrent use.
+type MatrixDistanceProvider struct {
+	pairs map[string]ports.DistanceResult
+}
+
+// NewMatrixDistanceProvider builds a provider from matrix entries.
+// Duplicate pairs with different values are rejected.
+func NewMatrixDistanceProvider(entries []MatrixEntry) (*MatrixDistanceProvider, error) {
+	pairs := make(map[string]ports.DistanceResult, len(entries))
+	for i, e := range entries {
+		origin, dest := strings.Join(strings.Fields(e.Origin), " "), strings.Join(strings.Fields(e.Destination), " ")
+		if origin == "" || dest == "" {
+			return nil, fmt.Errorf("distance matrix entry %d: origin and destination must be non-empty", i+1)
+		}
+		if e.Meters < 0 || e.Seconds < 0 {
+			return nil, fmt.Errorf("distance matrix entry %d: meters and seconds must not be negative", i+1)
+		}
+
+		r := ports.DistanceResult{DistanceMeters: e.Meters, DurationSeconds: e.Seconds}
+		key := origin + "|" + dest
+		if prev, ok := pairs[key]; ok && prev != r {
+			return nil, fmt.Errorf("distance matrix entry %d: conflicting values for %q -> %q", i+1, origin, dest)
+		}
+		pairs[key] = r
+	}
+
+	return &MatrixDistanceProvider{pairs: pairs}, nil
+}
+
+// LoadMatrixFile reads a distance matrix from a .json or .csv file.
+func LoadMatrixFile(path string) (*MatrixDistanceProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("load distance matrix: %w", err)
+	}
+	defer f.Close()
+
+	var entries []MatrixEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		entries, err = ReadMatrixJSON(f)
+	case ".csv":
+		entries, err = ReadMatrixCSV(f)
+	default:
+		return nil, fmt.Errorf("load distance matrix: unsupported extension %q (expected .json or .csv)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load distance matrix %q: %w", path, err)
+	}
+
+	return NewMatrixDistanceProvider(entries)
+}
+
+// ReadMatrixJSON reads a JSON array of matrix entries.
+func ReadMatrixJSON(r io.Reader) ([]MatrixEntry, error) {
+	var entries []MatrixEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parse json: %w", err)
+	}
+	return entries, nil
+}
+
+// ReadMatrixCSV reads matrix entries from CSV with an
+// origin,destination,meters,seconds header.
+func ReadMatrixCSV(r io.Reader) ([]MatrixEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(matrixHeader)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv input is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	for i, h := range header {
+		if strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))) != matrixHeader[i] {
+			return nil, fmt.Errorf("csv header must be %s", strings.Join(matrixHeader, ","))
+		}
+	}
+
+	var entries []MatrixEntry
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv line %d: %w", line, err)
+		}
+
+		meters, mErr := strconv.Atoi(strings.TrimSpace(rec[2]))
+		seconds, sErr := strconv.Atoi(strings.TrimSpace(rec[3]))
+		if mErr != nil || sErr != nil {
+			return nil, fmt.Errorf("csv line %d: meters and seconds must be integers", line)
+		}
+		entries = append(entries, MatrixEntry{Origin: rec[0], Destination: rec[1], Meters: meters, Seconds: seconds})
+	}
+}
+
+func (m *MatrixDistanceProvider) GetDistance(
+	ctx context.Context,
+	origin string,
+	destination string,
+) (ports.DistanceResult, error) {
+	origin = strings.Join(strings.Fields(origin), " ")
+	destination = strings.Join(strings.Fields(destination), " ")
+	if origin == destination {
+		return ports.DistanceResult{}, nil
+	}
+	r, ok := m.pairs[origin+"|"+destination]
+	if !ok {
+		return ports.DistanceResult{}, fmt.Errorf("distance matrix has no entry for %q -> %q", origin, destination)
+	}
+	return r, nil
+}
+
+func (m *MatrixDistanceProvider) GetDistances(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+) (map[string]ports.DistanceResult, error) {
+	out := make(map[string]ports.DistanceResult, len(destinations))
+	for _, d := range destinations {
+		r, err := m.GetDistance(ctx, origin, d)
+		if err != nil {
+			return nil, err
+		}
+		out[d] = r
+	}
+	return out, nil
+}
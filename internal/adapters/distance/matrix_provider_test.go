@@ -0,0 +1,95 @@
+package distance_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/ports"
+)
+
+func TestMatrixDistanceProvider(t *testing.T) {
+	entries, err := distance.ReadMatrixCSV(strings.NewReader(
+		"origin,destination,meters,seconds\n" +
+			"Hub,1 Main St,1000,60\n" +
+			"\"1 Main St\",Hub,1100,70\n",
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := distance.NewMatrixDistanceProvider(entries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	got, err := p.GetDistances(ctx, "Hub", []string{"1  Main St", "Hub"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["1  Main St"] != (ports.DistanceResult{DistanceMeters: 1000, DurationSeconds: 60}) {
+		t.Fatalf("expected whitespace-normalized lookup to hit, got %+v", got)
+	}
+	if got["Hub"] != (ports.DistanceResult{}) {
+		t.Fatalf("expected zero distance to self, got %+v", got["Hub"])
+	}
+
+	if _, err := p.GetDistance(ctx, "Hub", "2 Main St"); err == nil || !strings.Contains(err.Error(), "no entry") {
+		t.Fatalf("expected missing pair error, got %v", err)
+	}
+}
+
+func TestReadMatrix(t *testing.T) {
+	tests := []struct {
+		name        string
+		read        func(string) ([]distance.MatrixEntry, error)
+		src         string
+		wantEntries int
+		errContains string
+	}{
+		{
+			name:        "json array",
+			read:        func(s string) ([]distance.MatrixEntry, error) { return distance.ReadMatrixJSON(strings.NewReader(s)) },
+			src:         `[{"origin": "Hub", "destination": "A", "meters": 10, "seconds": 1}]`,
+			wantEntries: 1,
+		},
+		{
+			name:        "csv with wrong header",
+			read:        func(s string) ([]distance.MatrixEntry, error) { return distance.ReadMatrixCSV(strings.NewReader(s)) },
+			src:         "from,to,meters,seconds\n",
+			errContains: "header",
+		},
+		{
+			name:        "csv with non-integer value",
+			read:        func(s string) ([]distance.MatrixEntry, error) { return distance.ReadMatrixCSV(strings.NewReader(s)) },
+			src:         "origin,destination,meters,seconds\nHub,A,ten,1\n",
+			errContains: "line 2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := tc.read(tc.src)
+			if tc.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tc.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != tc.wantEntries {
+				t.Fatalf("expected %d entries, got %d", tc.wantEntries, len(entries))
+			}
+		})
+	}
+
+	if _, err := distance.NewMatrixDistanceProvider([]distance.MatrixEntry{
+		{Origin: "Hub", Destination: "A", Meters: 10, Seconds: 1},
+		{Origin: "Hub", Destination: "A", Meters: 20, Seconds: 1},
+	}); err == nil {
+		t.Fatalf("expected error for conflicting duplicate pairs")
+	}
+}
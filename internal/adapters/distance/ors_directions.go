@@ -0,0 +1,191 @@
+package distance
+
+import (
+	"bytes"
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/platform/obs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+)
+
+type directionsRequest struct {
+	Coordinates  [][]float64 `json:"coordinates"`
+	Instructions bool        `json:"instructions"`
+}
+
+type directionsResponse struct {
+	Routes []struct {
+		Geometry string `json:"geometry"`
+		Segments []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+			Steps    []struct {
+				Distance    float64 `json:"distance"`
+				Duration    float64 `json:"duration"`
+				Instruction string  `json:"instruction"`
+				Name        string  `json:"name"`
+			} `json:"steps"`
+		} `json:"segments"`
+	} `json:"routes"`
+}
+
+// GetDirections returns the road path visiting waypoints in order using the
+// OpenRouteService directions endpoint. Results are cached by waypoint list
+// and the coordinates each waypoint resolved to, so repeated requests for the
+// same route do not call ORS again, while a changed override or stored
+// coordinate fetches fresh geometry.
+func (o *ORSDistanceProvider) GetDirections(
+	ctx context.Context,
+	waypoints []string,
+	known map[string]domain.Coordinates,
+) (_ *domain.RouteGeometry, err error) {
+	defer obs.Time(ctx, "ors.GetDirections")(&err)
+
+	if len(waypoints) < 2 {
+		return nil, errors.New("get ORS directions: at least two waypoints are required")
+	}
+
+	normWaypoints := make([]string, 0, len(waypoints))
+	seen := make(map[string]struct{}, len(waypoints))
+	unique := make([]string, 0, len(waypoints))
+	for _, w := range waypoints {
+		w = strings.Join(strings.Fields(w), " ")
+		if w == "" {
+			return nil, errors.New("get ORS directions: waypoints must be non-empty")
+		}
+		normWaypoints = append(normWaypoints, w)
+		if _, ok := seen[w]; !ok {
+			seen[w] = struct{}{}
+			unique = append(unique, w)
+		}
+	}
+
+	normKnown := make(map[string]domain.Coordinates, len(known))
+	for a, c := range known {
+		normKnown[strings.Join(strings.Fields(a), " ")] = c
+	}
+
+	coords, failures, err := o.resolveCoordinates(ctx, unique, normKnown)
+	if err != nil {
+		return nil, err
+	}
+	if err := firstAddressError(unique[0], unique[1:], failures); err != nil {
+		return nil, err
+	}
+
+	cacheKey := directionsCacheKey(normWaypoints, coords)
+	if o.directions != nil {
+		geometry, ok, err := o.directions.Get(ctx, cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("ORS get directions cache: %w", err)
+		}
+		if ok {
+			return geometry, nil
+		}
+	}
+
+	geometry, err := o.fetchDirections(ctx, normWaypoints, coords)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.directions != nil {
+		if err := o.directions.Put(ctx, cacheKey, geometry); err != nil {
+			log.Printf("directions cache write failed: %v", err)
+		}
+	}
+
+	return geometry, nil
+}
+
+// directionsCacheKey pairs each waypoint with its resolved coordinates.
+func directionsCacheKey(waypoints []string, coords map[string]domain.Coordinates) []string {
+	key := make([]string, len(waypoints))
+	for i, w := range waypoints {
+		key[i] = w + "@" + coords[w].String()
+	}
+	return key
+}
+
+// fetchDirections requests a single route through all waypoints and maps each
+// ORS segment onto the leg between consecutive waypoints.
+func (o *ORSDistanceProvider) fetchDirections(
+	ctx context.Context,
+	waypoints []string,
+	coords map[string]domain.Coordinates,
+) (_ *domain.RouteGeometry, err error) {
+	defer obs.Time(ctx, "ors.fetchDirections")(&err)
+
+	endpoint := fmt.Sprintf("%s/v2/directions/%s/json", o.baseURL, o.profile)
+
+	locations := make([][]float64, 0, len(waypoints))
+	for _, w := range waypoints {
+		c, ok := coords[w]
+		if !ok {
+			return nil, fmt.Errorf("missing coordinate for waypoint %q", w)
+		}
+		locations = append(locations, c.CoordsToList())
+	}
+
+	payload, err := json.Marshal(directionsRequest{Coordinates: locations, Instructions: true})
+	if err != nil {
+		return nil, fmt.Errorf("marshal directions request: %w", err)
+	}
+
+	resp, err := o.doWithRetry(ctx, func() (*http.Request, error) {
+		body := bytes.NewReader(payload)
+		return o.newRequest(ctx, http.MethodPost, endpoint, body)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("directions request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var dr directionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dr); err != nil {
+		return nil, fmt.Errorf("decode directions response: %w", err)
+	}
+
+	if len(dr.Routes) == 0 {
+		return nil, errors.New("directions response contained no routes")
+	}
+
+	route := dr.Routes[0]
+	if len(route.Segments) != len(waypoints)-1 {
+		return nil, fmt.Errorf(
+			"segment count does not match legs: segments=%d legs=%d",
+			len(route.Segments), len(waypoints)-1,
+		)
+	}
+
+	geometry := &domain.RouteGeometry{
+		Polyline: route.Geometry,
+		Legs:     make([]domain.RouteLeg, 0, len(route.Segments)),
+	}
+	for i, seg := range route.Segments {
+		leg := domain.RouteLeg{
+			From:            waypoints[i],
+			To:              waypoints[i+1],
+			DistanceMeters:  int(math.Round(seg.Distance)),
+			DurationSeconds: int(math.Round(seg.Duration)),
+			Steps:           make([]domain.DirectionStep, 0, len(seg.Steps)),
+		}
+		for _, st := range seg.Steps {
+			leg.Steps = append(leg.Steps, domain.DirectionStep{
+				Instruction:     st.Instruction,
+				Street:          st.Name,
+				DistanceMeters:  int(math.Round(st.Distance)),
+				DurationSeconds: int(math.Round(st.Duration)),
+			})
+		}
+		geometry.Legs = append(geometry.Legs, leg)
+	}
+
+	return geometry, nil
+}
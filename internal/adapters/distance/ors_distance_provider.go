@@ -2,6 +2,7 @@ package distance
 
 import (
 	"context"
+	"delivery-route-service/internal/config"
 	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/platform/obs"
 	"delivery-route-service/internal/ports"
@@ -17,41 +18,71 @@ import (
 //
 // It coordinates:
 //   - Address normalization
+//   - Manual geocode overrides
 //   - Persistent geocode caching
 //   - Persistent distance matrix caching
+//   - Route directions and geometry caching
 //   - External API calls with retry/backoff
 //
 // The provider is safe for concurrent use.
 type ORSDistanceProvider struct {
-	session       *http.Client
-	apiKey        string
-	baseURL       string
-	profile       string
-	distanceCache ports.DistanceCache
-	geocodeCache  ports.GeocodeCache
+	session        *http.Client
+	apiKey         string
+	baseURL        string
+	profile        string
+	maxAttempts    int
+	initialBackoff time.Duration
+	concurrency    int
+	distanceCache  ports.DistanceCache
+	geocodeCache   ports.GeocodeCache
+	overrides      ports.GeocodeOverrideRepository
+	directions     ports.DirectionsCache
 }
 
+// NewORSDistanceProvider builds a provider from cfg. The caches and the
+// override repository are optional; pass nil to disable them.
 func NewORSDistanceProvider(
-	apiKey string,
+	cfg config.ORSConfig,
 	distanceCache ports.DistanceCache,
 	geocodeCache ports.GeocodeCache,
+	overrides ports.GeocodeOverrideRepository,
+	directionsCache ports.DirectionsCache,
 ) (*ORSDistanceProvider, error) {
-	if apiKey == "" {
+	if cfg.APIKey == "" {
 		return nil, errors.New("ORS api key is empty")
 	}
+	if cfg.MaxAttempts < 1 {
+		return nil, fmt.Errorf("ORS max attempts must be at least 1, got %d", cfg.MaxAttempts)
+	}
+	if cfg.Concurrency < 1 {
+		return nil, fmt.Errorf("ORS concurrency must be at least 1, got %d", cfg.Concurrency)
+	}
 
 	provider := &ORSDistanceProvider{
-		session:       &http.Client{Timeout: 10 * time.Second},
-		apiKey:        apiKey,
-		baseURL:       "https://api.openrouteservice.org",
-		profile:       "driving-car",
-		distanceCache: distanceCache,
-		geocodeCache:  geocodeCache,
+		session:        &http.Client{Timeout: cfg.Timeout},
+		apiKey:         cfg.APIKey,
+		baseURL:        strings.TrimRight(cfg.BaseURL, "/"),
+		profile:        cfg.Profile,
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		concurrency:    cfg.Concurrency,
+		distanceCache:  distanceCache,
+		geocodeCache:   geocodeCache,
+		overrides:      overrides,
+		directions:     directionsCache,
 	}
 
 	return provider, nil
 }
 
+// WithTransport sends ORS requests through rt instead of the default
+// transport, e.g. to record or replay responses in tests. It must be called
+// before the provider is used.
+func (o *ORSDistanceProvider) WithTransport(rt http.RoundTripper) *ORSDistanceProvider {
+	o.session.Transport = rt
+	return o
+}
+
 // normalizeAndDedupe collapses whitespace in origin and destinations,
 // removes duplicates, and filters out destinations equal to the origin.
 func (o *ORSDistanceProvider) normalizeAndDedupe(
@@ -111,33 +142,97 @@ func (o *ORSDistanceProvider) resolveDistances(
 	return destinationHits, destinationMisses, nil
 }
 
-// resolveCoordinates resolves addresses to coordinates via cache and ORS geocoding.
-// Fresh results are written back to the geocode cache.
+// resolveCoordinates resolves addresses to coordinates. Sources are tried in
+// order: "lat,lon" literals, manual overrides, caller-known coordinates, the
+// geocode cache and finally ORS geocoding. Addresses that cannot be located are
+// returned in failures (address -> reason) rather than as an error. Fresh
+// results, including failures when the cache supports it, are written back to
+// the geocode cache.
 func (o *ORSDistanceProvider) resolveCoordinates(
 	ctx context.Context,
 	addresses []string,
-) (coords map[string]domain.Coordinates, err error) {
+	known map[string]domain.Coordinates,
+) (coords map[string]domain.Coordinates, failures map[string]string, err error) {
+	// Positional destinations need no lookup at all.
+	literalHits := make(map[string]domain.Coordinates)
+	remaining := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		if c, ok := domain.ParseCoordinates(a); ok {
+			literalHits[a] = c
+			continue
+		}
+		remaining = append(remaining, a)
+	}
+	addresses = remaining
+
+	// Manual overrides win over anything the geocoder or cache would return.
+	overrideHits := make(map[string]domain.Coordinates)
+	if o.overrides != nil && len(addresses) > 0 {
+		overrideHits, err = o.overrides.GetOverrides(ctx, addresses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ORS get geocode overrides: %w", err)
+		}
+	}
+	if len(overrideHits) > 0 {
+		remaining := make([]string, 0, len(addresses))
+		for _, a := range addresses {
+			if _, ok := overrideHits[a]; !ok {
+				remaining = append(remaining, a)
+			}
+		}
+		addresses = remaining
+	}
+
+	// Coordinates the caller already holds (e.g. stored on packages) skip the cache and ORS.
+	knownHits := make(map[string]domain.Coordinates)
+	if len(known) > 0 {
+		remaining := make([]string, 0, len(addresses))
+		for _, a := range addresses {
+			if c, ok := known[a]; ok {
+				knownHits[a] = c
+				continue
+			}
+			remaining = append(remaining, a)
+		}
+		addresses = remaining
+	}
+
 	geocodeHits := make(map[string]domain.Coordinates)
 	// Resolve coordinates via cache before calling ORS geocoding.
-	if o.geocodeCache != nil {
+	if o.geocodeCache != nil && len(addresses) > 0 {
 		geocodeHits, err = o.geocodeCache.GetMany(ctx, addresses)
 		if err != nil {
-			return nil, fmt.Errorf("ORS get geocode cache: %w", err)
+			return nil, nil, fmt.Errorf("ORS get geocode cache: %w", err)
+		}
+	}
+
+	// Known-bad addresses are served from the negative cache instead of re-querying ORS.
+	failures = make(map[string]string)
+	negCache, hasNegCache := o.geocodeCache.(ports.NegativeGeocodeCache)
+	if hasNegCache && len(addresses) > 0 {
+		failures, err = negCache.GetFailures(ctx, addresses)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ORS get geocode failure cache: %w", err)
 		}
 	}
 
 	geocodeMisses := make([]string, 0, len(addresses))
 	for _, a := range addresses {
-		if _, ok := geocodeHits[a]; !ok {
-			geocodeMisses = append(geocodeMisses, a)
+		if _, ok := geocodeHits[a]; ok {
+			continue
 		}
+		if _, ok := failures[a]; ok {
+			continue
+		}
+		geocodeMisses = append(geocodeMisses, a)
 	}
 
 	coordResults := make(map[string]domain.Coordinates)
+	freshFailures := make(map[string]string)
 	if len(geocodeMisses) > 0 {
-		coordResults, err = o.geocodeMany(ctx, geocodeMisses)
+		coordResults, freshFailures, err = o.geocodeMany(ctx, geocodeMisses)
 		if err != nil {
-			return nil, fmt.Errorf("retrieving coordinates: %w", err)
+			return nil, nil, fmt.Errorf("retrieving coordinates: %w", err)
 		}
 	}
 
@@ -146,16 +241,71 @@ func (o *ORSDistanceProvider) resolveCoordinates(
 			log.Printf("geocode cache write failed: %v", err)
 		}
 	}
+	if hasNegCache && len(freshFailures) > 0 {
+		if err := negCache.PutFailures(ctx, freshFailures); err != nil {
+			log.Printf("geocode failure cache write failed: %v", err)
+		}
+	}
 
-	coords = make(map[string]domain.Coordinates, len(geocodeHits)+len(coordResults))
-	for k, v := range geocodeHits {
-		coords[k] = v
+	coords = make(map[string]domain.Coordinates, len(literalHits)+len(overrideHits)+len(knownHits)+len(geocodeHits)+len(coordResults))
+	for _, hits := range []map[string]domain.Coordinates{literalHits, overrideHits, knownHits, geocodeHits, coordResults} {
+		for k, v := range hits {
+			coords[k] = v
+		}
 	}
-	for k, v := range coordResults {
-		coords[k] = v
+	for k, v := range freshFailures {
+		failures[k] = v
 	}
 
-	return coords, nil
+	return coords, failures, nil
+}
+
+// GeocodeAddresses resolves addresses ahead of distance lookups, keyed by the
+// address as given. Addresses ORS cannot locate are reported in failures.
+// Resolved coordinates are cached, so a following GetDistances call does not
+// geocode them again.
+func (o *ORSDistanceProvider) GeocodeAddresses(
+	ctx context.Context,
+	addresses []string,
+) (coords map[string]domain.Coordinates, failures map[string]string, err error) {
+	defer obs.Time(ctx, "ors.GeocodeAddresses")(&err)
+
+	if len(addresses) == 0 {
+		return map[string]domain.Coordinates{}, map[string]string{}, nil
+	}
+
+	normalized := make(map[string][]string, len(addresses))
+	normList := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		n := strings.Join(strings.Fields(a), " ")
+		if n == "" {
+			continue
+		}
+		if _, ok := normalized[n]; !ok {
+			normList = append(normList, n)
+		}
+		normalized[n] = append(normalized[n], a)
+	}
+
+	normCoords, normFailures, err := o.resolveCoordinates(ctx, normList, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coords = make(map[string]domain.Coordinates, len(normCoords))
+	for n, c := range normCoords {
+		for _, a := range normalized[n] {
+			coords[a] = c
+		}
+	}
+	failures = make(map[string]string, len(normFailures))
+	for n, reason := range normFailures {
+		for _, a := range normalized[n] {
+			failures[a] = reason
+		}
+	}
+
+	return coords, failures, nil
 }
 
 // fetchAndCacheDistance fetches distances from ORS for cache misses,
@@ -210,6 +360,20 @@ func (o *ORSDistanceProvider) fetchAndCacheDistances(
 	return distances, nil
 }
 
+// firstAddressError returns an AddressError for the origin, or else the first
+// failed destination in order, so a matrix request is never built with holes.
+func firstAddressError(origin string, destinations []string, failures map[string]string) error {
+	if reason, ok := failures[origin]; ok {
+		return &ports.AddressError{Address: origin, Reason: reason}
+	}
+	for _, d := range destinations {
+		if reason, ok := failures[d]; ok {
+			return &ports.AddressError{Address: d, Reason: reason}
+		}
+	}
+	return nil
+}
+
 // Delegate to batched path to reuse caching and matrix logic.
 func (o *ORSDistanceProvider) GetDistance(
 	ctx context.Context,
@@ -244,6 +408,33 @@ func (o *ORSDistanceProvider) GetDistances(
 ) (out map[string]ports.DistanceResult, err error) {
 	defer obs.Time(ctx, "ors.GetDistances")(&err)
 
+	return o.getDistances(ctx, origin, destinations, nil)
+}
+
+// GetDistancesWithCoordinates behaves like GetDistances but uses known
+// coordinates as-is for any address they cover.
+func (o *ORSDistanceProvider) GetDistancesWithCoordinates(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+	known map[string]domain.Coordinates,
+) (out map[string]ports.DistanceResult, err error) {
+	defer obs.Time(ctx, "ors.GetDistancesWithCoordinates")(&err)
+
+	normKnown := make(map[string]domain.Coordinates, len(known))
+	for a, c := range known {
+		normKnown[strings.Join(strings.Fields(a), " ")] = c
+	}
+
+	return o.getDistances(ctx, origin, destinations, normKnown)
+}
+
+func (o *ORSDistanceProvider) getDistances(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+	known map[string]domain.Coordinates,
+) (map[string]ports.DistanceResult, error) {
 	if len(destinations) == 0 {
 		return map[string]ports.DistanceResult{}, nil
 	}
@@ -262,17 +453,20 @@ func (o *ORSDistanceProvider) GetDistances(
 		return hits, nil
 	}
 
-	coords, err := o.resolveCoordinates(ctx, append([]string{origin}, misses...))
+	coords, failures, err := o.resolveCoordinates(ctx, append([]string{origin}, misses...), known)
 	if err != nil {
 		return nil, err
 	}
+	if err := firstAddressError(origin, misses, failures); err != nil {
+		return nil, err
+	}
 
 	fetched, err := o.fetchAndCacheDistances(ctx, origin, misses, coords)
 	if err != nil {
 		return nil, err
 	}
 
-	out = make(map[string]ports.DistanceResult, len(hits)+len(fetched))
+	out := make(map[string]ports.DistanceResult, len(hits)+len(fetched))
 	for k, v := range hits {
 		out[k] = v
 	}
@@ -0,0 +1,338 @@
+package distance_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"delivery-route-service/internal/adapters/cache"
+	"delivery-route-service/internal/adapters/distance"
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"delivery-route-service/internal/testutil"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var fakePlaces = map[string]domain.Coordinates{
+	"Hub":  {Lat: 33.40, Lon: -111.90},
+	"A St": {Lat: 33.41, Lon: -111.90},
+	"B St": {Lat: 33.40, Lon: -111.92},
+	"Lake": {Lat: 33.50, Lon: -111.80},
+}
+
+func newFakeORSProvider(t *testing.T, maxAttempts int) (*distance.ORSDistanceProvider, *testutil.FakeORS) {
+	t.Helper()
+	srv := testutil.NewFakeORS(fakePlaces)
+	srv.APIKey = "test-key"
+	srv.Unroutable[fakePlaces["Lake"]] = true
+	t.Cleanup(srv.Close)
+
+	p, err := distance.NewORSDistanceProvider(config.ORSConfig{
+		APIKey:         "test-key",
+		BaseURL:        srv.URL,
+		Profile:        "driving-car",
+		Timeout:        5 * time.Second,
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		Concurrency:    2,
+	}, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p, srv
+}
+
+func TestORSDistanceProviderFakeServer(t *testing.T) {
+	p, srv := newFakeORSProvider(t, 1)
+	ctx := context.Background()
+
+	coords, failures, err := p.GeocodeAddresses(ctx, []string{"Hub", " A  St ", "Nowhere"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if coords["Hub"] != fakePlaces["Hub"] || coords[" A  St "] != fakePlaces["A St"] {
+		t.Fatalf("expected Hub and A St to resolve, got %v", coords)
+	}
+	if failures["Nowhere"] == "" || len(failures) != 1 {
+		t.Fatalf("expected only Nowhere to fail, got %v", failures)
+	}
+
+	rs, err := p.GetDistances(ctx, "Hub", []string{"A St", "B St"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 0.01 and 0.02 degrees at 111 km per degree and 10 m/s.
+	want := map[string]ports.DistanceResult{
+		"A St": {DistanceMeters: 1110, DurationSeconds: 111},
+		"B St": {DistanceMeters: 2220, DurationSeconds: 222},
+	}
+	for addr, w := range want {
+		if rs[addr] != w {
+			t.Fatalf("expected %s to be %+v, got %+v", addr, w, rs[addr])
+		}
+	}
+	if n := srv.Requests(testutil.FakeORSMatrix); n != 1 {
+		t.Fatalf("expected one matrix request for the row, got %d", n)
+	}
+
+	if _, err := p.GetDistance(ctx, "Hub", "Nowhere"); !errors.Is(err, ports.ErrAddressNotGeocodable) {
+		t.Fatalf("expected address error for an unknown address, got %v", err)
+	}
+	if _, err := p.GetDistance(ctx, "Hub", "Lake"); !errors.Is(err, ports.ErrAddressNotGeocodable) {
+		t.Fatalf("expected address error for an unroutable address, got %v", err)
+	}
+}
+
+func TestORSDistanceProviderDirectionsCache(t *testing.T) {
+	srv := testutil.NewFakeORS(fakePlaces)
+	t.Cleanup(srv.Close)
+	mr := miniredis.RunT(t)
+	directions := cache.NewRedisDirectionsCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), cache.DefaultTTL)
+	overrides := testutil.NewMockGeocodeOverrideRepository(nil, nil)
+
+	p, err := distance.NewORSDistanceProvider(config.ORSConfig{
+		APIKey:         "test-key",
+		BaseURL:        srv.URL,
+		Profile:        "driving-car",
+		Timeout:        5 * time.Second,
+		MaxAttempts:    1,
+		InitialBackoff: time.Millisecond,
+		Concurrency:    2,
+	}, nil, nil, overrides, directions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	waypoints := []string{"Hub", "A St"}
+
+	steps := []struct {
+		name         string
+		override     *domain.Coordinates
+		known        map[string]domain.Coordinates
+		wantMeters   int
+		wantRequests int
+	}{
+		{name: "geocoded", wantMeters: 1110, wantRequests: 1},
+		{name: "repeat is cached", wantMeters: 1110, wantRequests: 1},
+		{name: "override refetches", override: &domain.Coordinates{Lat: 33.42, Lon: -111.90}, wantMeters: 2220, wantRequests: 2},
+		{name: "repeat override is cached", wantMeters: 2220, wantRequests: 2},
+		{
+			name:         "stored coordinates refetch",
+			known:        map[string]domain.Coordinates{"Hub": {Lat: 33.43, Lon: -111.90}},
+			wantMeters:   1110,
+			wantRequests: 3,
+		},
+	}
+	for _, st := range steps {
+		if st.override != nil {
+			overrides.Overrides["A St"] = domain.GeocodeOverride{Address: "A St", Coordinates: *st.override}
+		}
+		geometry, err := p.GetDirections(ctx, waypoints, st.known)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", st.name, err)
+		}
+		if got := geometry.Legs[0].DistanceMeters; got != st.wantMeters {
+			t.Fatalf("%s: expected %d m, got %d", st.name, st.wantMeters, got)
+		}
+		if n := srv.Requests(testutil.FakeORSDirections); n != st.wantRequests {
+			t.Fatalf("%s: expected %d directions requests, got %d", st.name, st.wantRequests, n)
+		}
+	}
+}
+
+func TestORSDistanceProviderRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpoint     string
+		failures     []int
+		wantErr      error
+		wantStatus   int
+		wantRequests int
+	}{
+		{name: "matrix 429 then success", endpoint: testutil.FakeORSMatrix, failures: []int{429}, wantRequests: 2},
+		{name: "matrix 5xx then success", endpoint: testutil.FakeORSMatrix, failures: []int{502, 503}, wantRequests: 3},
+		{name: "geocode 504 then success", endpoint: testutil.FakeORSGeocode, failures: []int{504}, wantRequests: 2},
+		{
+			name:         "matrix exhausts attempts on 503",
+			endpoint:     testutil.FakeORSMatrix,
+			failures:     []int{503, 503, 503},
+			wantErr:      ports.ErrUpstreamUnavailable,
+			wantStatus:   503,
+			wantRequests: 3,
+		},
+		{
+			name:         "geocode exhausts attempts on 429",
+			endpoint:     testutil.FakeORSGeocode,
+			failures:     []int{429, 500, 429},
+			wantErr:      ports.ErrUpstreamRateLimited,
+			wantStatus:   429,
+			wantRequests: 3,
+		},
+		{name: "matrix 400 is not retried", endpoint: testutil.FakeORSMatrix, failures: []int{400}, wantStatus: 400, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, srv := newFakeORSProvider(t, 3)
+			srv.FailNext(tt.endpoint, tt.failures...)
+			ctx := context.Background()
+
+			var err error
+			if tt.endpoint == testutil.FakeORSGeocode {
+				_, _, err = p.GeocodeAddresses(ctx, []string{"A St"})
+			} else {
+				// Literal coordinates skip geocoding, so only the matrix is called.
+				_, err = p.GetDistance(ctx, "33.40,-111.90", "33.41,-111.90")
+			}
+
+			if n := srv.Requests(tt.endpoint); n != tt.wantRequests {
+				t.Fatalf("expected %d requests, got %d", tt.wantRequests, n)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var upstream *ports.UpstreamError
+			if !errors.As(err, &upstream) || upstream.StatusCode != tt.wantStatus {
+				t.Fatalf("expected upstream error with status %d, got %v", tt.wantStatus, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantStatus == 429 && upstream.RetryAfter != testutil.FakeORSRetryAfter*time.Second {
+				t.Fatalf("expected Retry-After %ds, got %v", testutil.FakeORSRetryAfter, upstream.RetryAfter)
+			}
+		})
+	}
+}
+
+// replayAddresses are the addresses the fixtures in testdata/ors cover.
+var replayAddresses = []string{
+	"1 E Washington St, Phoenix, AZ 85004",
+	"401 E Jefferson St, Phoenix, AZ 85004",
+	"3400 E Sky Harbor Blvd, Phoenix, AZ 85034",
+}
+
+// TestORSDistanceProviderReplay runs the provider against recorded ORS
+// responses. To re-record the fixtures against the live API, run
+//
+//	ORS_RECORD=1 ORS_API_KEY=... go test ./internal/adapters/distance -run Replay
+//
+// ORS_BASE_URL overrides the API host while recording. The committed fixtures
+// were recorded from testutil.FakeORS; assertions avoid exact values so they
+// hold for a live recording too.
+func TestORSDistanceProviderReplay(t *testing.T) {
+	dir := filepath.Join("testdata", "ors")
+	record := os.Getenv("ORS_RECORD") == "1"
+
+	cfg := config.Default().ORS
+	cfg.MaxAttempts = 1
+	cfg.Concurrency = 1
+	if record {
+		cfg.APIKey = os.Getenv("ORS_API_KEY")
+		if cfg.APIKey == "" {
+			t.Fatal("ORS_RECORD=1 needs ORS_API_KEY")
+		}
+		if u := os.Getenv("ORS_BASE_URL"); u != "" {
+			cfg.BaseURL = u
+		}
+	} else {
+		// Replay never reaches the network, so any host will do.
+		cfg.APIKey = "replay"
+		cfg.BaseURL = "http://ors.invalid"
+	}
+
+	p, err := distance.NewORSDistanceProvider(cfg, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.WithTransport(testutil.NewReplayTransport(dir, record))
+	ctx := context.Background()
+
+	coords, failures, err := p.GeocodeAddresses(ctx, replayAddresses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(coords) != len(replayAddresses) || len(failures) != 0 {
+		t.Fatalf("expected every address to resolve, got %v, failures %v", coords, failures)
+	}
+	for a, c := range coords {
+		if !c.Valid() {
+			t.Fatalf("expected valid coordinates for %q, got %+v", a, c)
+		}
+	}
+
+	rs, err := p.GetDistances(ctx, replayAddresses[0], replayAddresses[1:])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, a := range replayAddresses[1:] {
+		if r := rs[a]; r.DistanceMeters <= 0 || r.DurationSeconds <= 0 {
+			t.Fatalf("expected a positive distance to %q, got %+v", a, r)
+		}
+	}
+}
+
+func TestReplayTransportRecordsAndReplays(t *testing.T) {
+	dir := t.TempDir()
+	srv := testutil.NewFakeORS(fakePlaces)
+	srv.APIKey = "secret-key"
+	client := &http.Client{Transport: testutil.NewReplayTransport(dir, true)}
+
+	get := func(c *http.Client, base string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, base+"/geocode/search?text=Hub", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Header.Set("Authorization", "secret-key")
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	status, recorded := get(client, srv.URL)
+	srv.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one fixture file, got %v (%v)", files, err)
+	}
+	raw, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(raw), "secret-key") {
+		t.Fatalf("expected the API key to be left out of the fixture, got %s", raw)
+	}
+
+	// The server is gone and the host differs; the fixture still answers.
+	replay := &http.Client{Transport: testutil.NewReplayTransport(dir, false)}
+	replayedStatus, replayed := get(replay, "http://ors.invalid")
+	if replayedStatus != status || replayed != recorded {
+		t.Fatalf("expected replay to return %d %q, got %d %q", status, recorded, replayedStatus, replayed)
+	}
+
+	if _, err := replay.Get("http://ors.invalid/geocode/search?text=unrecorded"); err == nil {
+		t.Fatalf("expected an error for a request without a fixture")
+	}
+}
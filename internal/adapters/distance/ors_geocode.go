@@ -4,7 +4,9 @@ import (
 	"context"
 	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/platform/obs"
+	"delivery-route-service/internal/ports"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -60,19 +62,26 @@ func (o *ORSDistanceProvider) geocodeSingle(
 	}
 
 	if len(decoded.Features) == 0 {
-		return domain.Coordinates{}, fmt.Errorf("no geocode results for %q", address)
+		return domain.Coordinates{}, &ports.AddressError{Address: address, Reason: "no geocode results"}
 	}
 
 	coords := decoded.Features[0].Geometry.Coordinates
+	if len(coords) < 2 {
+		return domain.Coordinates{}, &ports.AddressError{Address: address, Reason: "geocode result has no coordinates"}
+	}
 	return domain.Coordinates{Lon: coords[0], Lat: coords[1]}, nil
 }
 
 // geocodeMany resolves addresses individually using OpenRouteService (/geocode/search).
 // Calls are deduplicated and may be retried via doWithRetry.
+//
+// Addresses ORS cannot locate are reported in failures (address -> reason) and
+// do not affect other lookups. Any other error (upstream outage, rate limiting)
+// cancels the remaining lookups and is returned.
 func (o *ORSDistanceProvider) geocodeMany(
 	ctx context.Context,
 	addresses []string,
-) (_ map[string]domain.Coordinates, err error) {
+) (_ map[string]domain.Coordinates, failures map[string]string, err error) {
 	defer obs.Time(ctx, "ors.geocodeMany")(&err)
 
 	seen := make(map[string]struct{}, len(addresses))
@@ -89,7 +98,7 @@ func (o *ORSDistanceProvider) geocodeMany(
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	sem := make(chan struct{}, 5)
+	sem := make(chan struct{}, o.concurrency)
 	resultsCh := make(chan geocodeResult, len(addrList))
 	var wg sync.WaitGroup
 
@@ -104,7 +113,10 @@ func (o *ORSDistanceProvider) geocodeMany(
 			coords, err := o.geocodeSingle(ctx, addr)
 			if err != nil {
 				resultsCh <- geocodeResult{address: addr, err: err}
-				cancel()
+				// A single unknown address is not a reason to abandon the others.
+				if !errors.Is(err, ports.ErrAddressNotGeocodable) {
+					cancel()
+				}
 				return
 			}
 			resultsCh <- geocodeResult{address: addr, result: coords}
@@ -115,10 +127,16 @@ func (o *ORSDistanceProvider) geocodeMany(
 	close(resultsCh)
 
 	out := make(map[string]domain.Coordinates)
+	failures = make(map[string]string)
 	var geocodeErr error
 	for res := range resultsCh {
 		if res.err != nil {
-			if geocodeErr == nil {
+			var addrErr *ports.AddressError
+			if errors.As(res.err, &addrErr) {
+				failures[res.address] = addrErr.Reason
+				continue
+			}
+			if geocodeErr == nil || errors.Is(geocodeErr, context.Canceled) {
 				geocodeErr = res.err
 			}
 			continue
@@ -126,8 +144,8 @@ func (o *ORSDistanceProvider) geocodeMany(
 		out[res.address] = res.result
 	}
 	if geocodeErr != nil {
-		return nil, geocodeErr
+		return nil, nil, geocodeErr
 	}
 
-	return out, nil
+	return out, failures, nil
 }
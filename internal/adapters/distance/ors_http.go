@@ -2,20 +2,25 @@ package distance
 
 import (
 	"context"
+	"delivery-route-service/internal/ports"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type httpStatusError struct {
-	Code int
-	Body string
+	Code       int
+	Body       string
+	RetryAfter time.Duration
 }
 
+const upstreamService = "openrouteservice"
+
 func (o *ORSDistanceProvider) newRequest(
 	ctx context.Context,
 	method string,
@@ -46,8 +51,9 @@ func (o *ORSDistanceProvider) do(req *http.Request) (*http.Response, error) {
 		b, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		return nil, &httpStatusError{
-			Code: resp.StatusCode,
-			Body: strings.TrimSpace(string(b)),
+			Code:       resp.StatusCode,
+			Body:       strings.TrimSpace(string(b)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
 	}
 	return resp, nil
@@ -68,12 +74,13 @@ func (o *ORSDistanceProvider) isRetryable(err error) bool {
 
 // doWithRetry retires transient failures (network errors, 5xx responses)
 // using exponential backoff while respecting context cancellation.
+// Attempt count and initial backoff come from the provider configuration.
 func (o *ORSDistanceProvider) doWithRetry(
 	ctx context.Context,
 	makeReq func() (*http.Request, error),
 ) (*http.Response, error) {
-	const maxAttempts = 4
-	backoff := 200 * time.Millisecond
+	maxAttempts := o.maxAttempts
+	backoff := o.initialBackoff
 
 	var lastErr error
 
@@ -94,7 +101,7 @@ func (o *ORSDistanceProvider) doWithRetry(
 		lastErr = err
 
 		if !o.isRetryable(err) || attempt == maxAttempts {
-			return nil, lastErr
+			return nil, toUpstreamError(lastErr)
 		}
 
 		timer := time.NewTimer(backoff)
@@ -108,7 +115,43 @@ func (o *ORSDistanceProvider) doWithRetry(
 		backoff *= 2
 	}
 
-	return nil, lastErr
+	return nil, toUpstreamError(lastErr)
+}
+
+// toUpstreamError classifies a final request failure so callers can map it
+// to ports.ErrUpstreamUnavailable or ports.ErrUpstreamRateLimited.
+// Context cancellation is returned unchanged since it is not an upstream fault.
+func toUpstreamError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var he *httpStatusError
+	if errors.As(err, &he) {
+		return &ports.UpstreamError{
+			Service:    upstreamService,
+			StatusCode: he.Code,
+			RetryAfter: he.RetryAfter,
+			Err:        err,
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return &ports.UpstreamError{Service: upstreamService, Err: err}
+	}
+
+	return err
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds.
+// HTTP-date values are rare for ORS and are ignored.
+func parseRetryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
 }
 
 func (e *httpStatusError) Error() string {
@@ -104,8 +104,9 @@ func (o *ORSDistanceProvider) fetchMatrixRow(
 		metersPtr := rowDistances[i]
 		secondsPtr := rowDurations[i]
 
+		// ORS returns null metrics when a location cannot be snapped to the road network.
 		if metersPtr == nil || secondsPtr == nil {
-			return nil, fmt.Errorf("matrix returned invalid metrics for %q:", dest)
+			return nil, &ports.AddressError{Address: dest, Reason: "no routable road near address"}
 		}
 
 		meters := *metersPtr
@@ -2,53 +2,39 @@ package repositories
 
 import (
 	"database/sql"
+	"delivery-route-service/internal/domain"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 )
 
-// InitSchema initializes the database schema (Postgres).
-func InitSchema(db *sql.DB) error {
-	if db == nil {
-		return errors.New("init schema: DB is nil")
-	}
-
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("init schema: begin tx: %w", err)
-	}
-	defer func() { _ = tx.Rollback() }()
-
-	createPackagesQuery := `
-	CREATE TABLE IF NOT EXISTS packages (
-		package_id INTEGER PRIMARY KEY,
-		destination TEXT NOT NULL
-	);
-	`
-
-	statements := []string{createPackagesQuery}
-
-	for i, stmt := range statements {
-		if _, err := tx.Exec(stmt); err != nil {
-			return fmt.Errorf("init schema: exec statement #%d: %w", i+1, err)
-		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("init schema: commit tx: %w", err)
-	}
-
-	return nil
-}
-
 type PackageSeed struct {
-	PackageID   int    `json:"package_id"`
-	Destination string `json:"destination"`
+	PackageID   int      `json:"package_id"`
+	Destination string   `json:"destination"`
+	Lat         *float64 `json:"lat,omitempty"`
+	Lon         *float64 `json:"lon,omitempty"`
+	// Service time override for this package; omitted uses the plan's default.
+	ServiceSeconds *int `json:"service_seconds,omitempty"`
+	// Counted against truck weight and volume limits; omitted is zero.
+	WeightKg float64 `json:"weight_kg,omitempty"`
+	VolumeM3 float64 `json:"volume_m3,omitempty"`
+	// Hub the package must be delivered from; omitted leaves it unassigned.
+	HubID int `json:"hub_id,omitempty"`
+	// Where the truck collects the package before delivering it; omitted
+	// means it is loaded at the hub.
+	Pickup string `json:"pickup,omitempty"`
+	// Delivery promise; omitted is standard. Deadline, when set, replaces
+	// the service level's default promise.
+	ServiceLevel domain.ServiceLevel `json:"service_level,omitempty"`
+	Deadline     *time.Time          `json:"deadline,omitempty"`
 }
 
 // Populate the database with package data from a JSON file.
+// A package may give lat/lon instead of, or in addition to, a destination
+// address; position-only packages use the "lat,lon" label as destination.
 func SeedFromJSON(db *sql.DB, jsonPath string) error {
 	bytes, err := os.ReadFile(jsonPath)
 	if err != nil {
@@ -67,11 +53,53 @@ func SeedFromJSON(db *sql.DB, jsonPath string) error {
 			return fmt.Errorf("seed packages: invalid packageID at index %d: %d", i+1, packageID)
 		}
 
+		if (item.Lat == nil) != (item.Lon == nil) {
+			return fmt.Errorf("seed packages: item at index %d: lat and lon must be given together", i+1)
+		}
+		if item.Lat != nil {
+			c := domain.Coordinates{Lon: *item.Lon, Lat: *item.Lat}
+			if !c.Valid() {
+				return fmt.Errorf("seed packages: item at index %d: coordinates out of range", i+1)
+			}
+		}
+
+		if item.ServiceSeconds != nil && *item.ServiceSeconds < 0 {
+			return fmt.Errorf("seed packages: item at index %d: service_seconds must not be negative", i+1)
+		}
+		if item.WeightKg < 0 || item.VolumeM3 < 0 {
+			return fmt.Errorf("seed packages: item at index %d: weight_kg and volume_m3 must not be negative", i+1)
+		}
+		if item.HubID < 0 {
+			return fmt.Errorf("seed packages: item at index %d: hub_id must not be negative", i+1)
+		}
+
 		dest := strings.TrimSpace(item.Destination)
+		if dest == "" && item.Lat != nil {
+			dest = domain.Coordinates{Lon: *item.Lon, Lat: *item.Lat}.String()
+		}
 		if dest == "" {
 			return fmt.Errorf("seed packages: item dest at index %d: destination cannot be empty", i+1)
 		}
-		rows = append(rows, PackageSeed{PackageID: packageID, Destination: dest})
+		pickup := strings.Join(strings.Fields(item.Pickup), " ")
+		if pickup != "" && pickup == strings.Join(strings.Fields(dest), " ") {
+			return fmt.Errorf("seed packages: item at index %d: pickup must differ from the destination", i+1)
+		}
+		if item.ServiceLevel != "" && !slices.Contains(domain.ServiceLevels, item.ServiceLevel) {
+			return fmt.Errorf("seed packages: item at index %d: unknown service_level %q", i+1, item.ServiceLevel)
+		}
+		rows = append(rows, PackageSeed{
+			PackageID:      packageID,
+			Destination:    dest,
+			Lat:            item.Lat,
+			Lon:            item.Lon,
+			ServiceSeconds: item.ServiceSeconds,
+			WeightKg:       item.WeightKg,
+			VolumeM3:       item.VolumeM3,
+			HubID:          item.HubID,
+			Pickup:         pickup,
+			ServiceLevel:   item.ServiceLevel,
+			Deadline:       item.Deadline,
+		})
 	}
 
 	tx, err := db.Begin()
@@ -80,21 +108,17 @@ func SeedFromJSON(db *sql.DB, jsonPath string) error {
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	query := `
-	INSERT INTO packages (package_id, destination)
-	VALUES ($1, $2)
-	ON CONFLICT (package_id) DO UPDATE
-	SET destination = EXCLUDED.destination;
-	`
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.Prepare(upsertPackageQuery)
 	if err != nil {
 		return fmt.Errorf("seed packages: prepare insert: %w", err)
 	}
 	defer stmt.Close()
 
 	for _, p := range rows {
-		if _, err := stmt.Exec(p.PackageID, p.Destination); err != nil {
-			return fmt.Errorf("seed packages: insert package_id=%d: %w", p.PackageID, err)
+		_, err := stmt.Exec(p.PackageID, p.Destination, p.Lat, p.Lon, p.ServiceSeconds, p.WeightKg, p.VolumeM3, storedHubID(p.HubID), p.Pickup,
+			storedServiceLevel(p.ServiceLevel), p.Deadline)
+		if err != nil {
+			return fmt.Errorf("seed packages: insert package_id=%d: %w", p.PackageID, unknownHub(p.PackageID, p.HubID, err))
 		}
 	}
 
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"sort"
+	"sync"
+)
+
+// In-memory implementation of the PackageRepository port, for tools that plan
+// from a file without a database. It also implements PackageWriter and
+// PackageCoordinateWriter with the same semantics as SQLPackageRepository.
+type MemoryPackageRepository struct {
+	mu       sync.Mutex
+	packages map[int]*domain.Package
+}
+
+func NewMemoryPackageRepository() *MemoryPackageRepository {
+	return &MemoryPackageRepository{packages: make(map[int]*domain.Package)}
+}
+
+// Return copies of all packages ordered by package ID.
+func (m *MemoryPackageRepository) ListPackages(ctx context.Context) ([]*domain.Package, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]*domain.Package, 0, len(m.packages))
+	for _, p := range m.packages {
+		cp := *p
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].PackageID < out[j].PackageID })
+
+	return out, nil
+}
+
+// Create or replace packages. Stored coordinates survive unless the
+// destination changed or new coordinates are given.
+func (m *MemoryPackageRepository) UpsertPackages(ctx context.Context, pkgs []*domain.Package) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range pkgs {
+		next := *p
+		if prev, ok := m.packages[p.PackageID]; ok && next.Coordinates == nil && prev.Destination == next.Destination {
+			next.Coordinates = prev.Coordinates
+		}
+		m.packages[p.PackageID] = &next
+	}
+
+	return nil
+}
+
+// Set coordinates for the given package IDs; unknown IDs are ignored.
+func (m *MemoryPackageRepository) SetPackageCoordinates(ctx context.Context, coords map[int]domain.Coordinates) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, c := range coords {
+		if p, ok := m.packages[id]; ok {
+			c := c
+			p.Coordinates = &c
+		}
+	}
+
+	return nil
+}
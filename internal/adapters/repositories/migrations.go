@@ -0,0 +1,303 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock key held while migrating, so
+// concurrently starting instances apply each migration exactly once.
+const migrationLockID int64 = 0x64656c6976657279 // "delivery"
+
+// Migration is one versioned schema change with its SQL in both directions.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied.
+type MigrationState struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations in version order.
+func Migrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	return ParseMigrations(sub)
+}
+
+// ParseMigrations reads NNNN_name.up.sql and NNNN_name.down.sql files from the
+// root of fsys. Every version needs an up file and a down file, and versions
+// must be unique.
+func ParseMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("parse migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(e.Name(), ".sql")
+		direction := path.Ext(base)
+		if direction != ".up" && direction != ".down" {
+			return nil, fmt.Errorf("parse migrations: %q must end in .up.sql or .down.sql", e.Name())
+		}
+		base = strings.TrimSuffix(base, direction)
+
+		vStr, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(vStr)
+		if !ok || err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("parse migrations: %q must be named NNNN_name%s.sql", e.Name(), direction)
+		}
+
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("parse migrations: read %q: %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("parse migrations: version %d is used by %q and %q", version, m.Name, name)
+		}
+		if direction == ".up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("parse migrations: version %d (%s) needs non-empty up and down files", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	return out, nil
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// advisory lock, after making sure the schema_migrations table exists.
+func withMigrationLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	if db == nil {
+		return errors.New("migrate: DB is nil")
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, migrationLockID); err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+	defer func() {
+		// Unlock on a fresh context so a cancelled ctx cannot leave the lock held.
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1);`, migrationLockID)
+	}()
+
+	createQuery := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	`
+	if _, err := conn.ExecContext(ctx, createQuery); err != nil {
+		return fmt.Errorf("migrate: create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedMigrations returns applied versions and when they were applied.
+func appliedMigrations(ctx context.Context, q interface {
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+}) (map[int]time.Time, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations;`)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list applied: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var v int
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, fmt.Errorf("migrate: scan applied: %w", err)
+		}
+		applied[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("migrate: applied iteration: %w", err)
+	}
+
+	return applied, nil
+}
+
+// runMigration executes one migration body and records the change in a single
+// transaction, so a failed migration leaves no partial schema behind.
+func runMigration(ctx context.Context, conn *sql.Conn, m Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	body, record := m.Down, `DELETE FROM schema_migrations WHERE version = $1;`
+	args := []any{m.Version}
+	if up {
+		body, record = m.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`
+		args = append(args, m.Name)
+	}
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("record version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// MigrateUp applies all pending migrations in version order and returns the
+// ones it applied. It is safe to call from several processes at once.
+func MigrateUp(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	err = withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, m, true); err != nil {
+				return fmt.Errorf("migrate up %04d_%s: %w", m.Version, m.Name, err)
+			}
+			ran = append(ran, m)
+		}
+		return nil
+	})
+
+	return ran, err
+}
+
+// MigrateDown reverts the latest applied migrations, newest first, and returns
+// the ones it reverted. steps <= 0 reverts every applied migration.
+func MigrateDown(ctx context.Context, db *sql.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	err = withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			if steps > 0 && len(reverted) == steps {
+				break
+			}
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if err := runMigration(ctx, conn, m, false); err != nil {
+				return fmt.Errorf("migrate down %04d_%s: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// MigrationStatus lists every known migration with its applied time, or nil
+// when pending. Applied versions missing from this build are returned as an
+// error since the binary is older than the database.
+func MigrationStatus(ctx context.Context, db *sql.DB) ([]MigrationState, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []MigrationState
+	err = withMigrationLock(ctx, db, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int]struct{}, len(migrations))
+		for _, m := range migrations {
+			known[m.Version] = struct{}{}
+			st := MigrationState{Version: m.Version, Name: m.Name}
+			if at, ok := applied[m.Version]; ok {
+				st.AppliedAt = &at
+			}
+			out = append(out, st)
+		}
+
+		var unknown []string
+		for v := range applied {
+			if _, ok := known[v]; !ok {
+				unknown = append(unknown, strconv.Itoa(v))
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("migration status: database has versions unknown to this build: %s", strings.Join(unknown, ", "))
+		}
+		return nil
+	})
+
+	return out, err
+}
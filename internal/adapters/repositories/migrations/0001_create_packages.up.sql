@@ -1,3 +1,4 @@
+-- IF NOT EXISTS lets databases created before versioned migrations adopt this baseline.
 CREATE TABLE IF NOT EXISTS packages (
 	package_id INTEGER PRIMARY KEY,
 	destination TEXT NOT NULL
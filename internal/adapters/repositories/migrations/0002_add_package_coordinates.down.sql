@@ -0,0 +1,3 @@
+ALTER TABLE packages
+	DROP COLUMN IF EXISTS lat,
+	DROP COLUMN IF EXISTS lon;
@@ -0,0 +1,3 @@
+ALTER TABLE packages
+	ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION,
+	ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION;
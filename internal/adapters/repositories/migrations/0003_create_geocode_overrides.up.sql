@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS geocode_overrides (
+	address TEXT PRIMARY KEY,
+	lon DOUBLE PRECISION NOT NULL,
+	lat DOUBLE PRECISION NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
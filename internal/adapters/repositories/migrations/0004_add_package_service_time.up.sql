@@ -0,0 +1,2 @@
+ALTER TABLE packages
+	ADD COLUMN IF NOT EXISTS service_seconds INTEGER CHECK (service_seconds >= 0);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS trucks (
+	truck_id INTEGER PRIMARY KEY CHECK (truck_id > 0),
+	name TEXT NOT NULL,
+	capacity INTEGER NOT NULL CHECK (capacity > 0),
+	home_hub TEXT NOT NULL DEFAULT '',
+	vehicle_type TEXT NOT NULL DEFAULT '',
+	cost_per_km DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (cost_per_km >= 0),
+	cost_per_hour DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (cost_per_hour >= 0),
+	available BOOLEAN NOT NULL DEFAULT true
+);
@@ -0,0 +1,7 @@
+ALTER TABLE trucks
+	DROP COLUMN IF EXISTS max_volume_m3,
+	DROP COLUMN IF EXISTS max_weight_kg;
+
+ALTER TABLE packages
+	DROP COLUMN IF EXISTS volume_m3,
+	DROP COLUMN IF EXISTS weight_kg;
@@ -0,0 +1,7 @@
+ALTER TABLE packages
+	ADD COLUMN IF NOT EXISTS weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (weight_kg >= 0),
+	ADD COLUMN IF NOT EXISTS volume_m3 DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (volume_m3 >= 0);
+
+ALTER TABLE trucks
+	ADD COLUMN IF NOT EXISTS max_weight_kg DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (max_weight_kg >= 0),
+	ADD COLUMN IF NOT EXISTS max_volume_m3 DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (max_volume_m3 >= 0);
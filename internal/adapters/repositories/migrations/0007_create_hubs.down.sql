@@ -0,0 +1,4 @@
+ALTER TABLE packages
+	DROP COLUMN IF EXISTS hub_id;
+
+DROP TABLE IF EXISTS hubs;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS hubs (
+	hub_id INTEGER PRIMARY KEY CHECK (hub_id > 0),
+	name TEXT NOT NULL,
+	address TEXT NOT NULL
+);
+
+ALTER TABLE packages
+	ADD COLUMN IF NOT EXISTS hub_id INTEGER CHECK (hub_id > 0) REFERENCES hubs (hub_id) ON DELETE SET NULL;
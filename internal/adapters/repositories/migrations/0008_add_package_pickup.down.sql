@@ -0,0 +1,2 @@
+ALTER TABLE packages
+	DROP COLUMN IF EXISTS pickup;
@@ -0,0 +1,3 @@
+ALTER TABLE packages
+	DROP COLUMN IF EXISTS deadline,
+	DROP COLUMN IF EXISTS service_level;
@@ -0,0 +1,4 @@
+ALTER TABLE packages
+	ADD COLUMN IF NOT EXISTS service_level TEXT NOT NULL DEFAULT 'standard'
+		CHECK (service_level IN ('standard', 'same_day', 'express')),
+	ADD COLUMN IF NOT EXISTS deadline TIMESTAMPTZ;
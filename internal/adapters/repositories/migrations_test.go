@@ -0,0 +1,100 @@
+package repositories_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"delivery-route-service/internal/adapters/repositories"
+)
+
+func TestMigrationsAreOrderedPairs(t *testing.T) {
+	migrations, err := repositories.Migrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations, got none")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("expected version %d at position %d, got %d (%s)", i+1, i, m.Version, m.Name)
+		}
+	}
+}
+
+func TestParseMigrations(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	tests := []struct {
+		name        string
+		fsys        fstest.MapFS
+		wantNames   []string
+		errContains string
+	}{
+		{
+			name: "sorted by version and ignores other files",
+			fsys: fstest.MapFS{
+				"0010_b.up.sql":   file("CREATE TABLE b ();"),
+				"0010_b.down.sql": file("DROP TABLE b;"),
+				"0002_a.up.sql":   file("CREATE TABLE a ();"),
+				"0002_a.down.sql": file("DROP TABLE a;"),
+				"README.md":       file("notes"),
+			},
+			wantNames: []string{"a", "b"},
+		},
+		{
+			name: "missing down file",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql": file("CREATE TABLE a ();"),
+			},
+			errContains: "version 1",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql":   file("CREATE TABLE a ();"),
+				"0001_a.down.sql": file("DROP TABLE a;"),
+				"0001_b.up.sql":   file("CREATE TABLE b ();"),
+			},
+			errContains: "version 1 is used by",
+		},
+		{
+			name: "bad name",
+			fsys: fstest.MapFS{
+				"create_a.up.sql": file("CREATE TABLE a ();"),
+			},
+			errContains: "NNNN_name",
+		},
+		{
+			name: "missing direction",
+			fsys: fstest.MapFS{
+				"0001_a.sql": file("CREATE TABLE a ();"),
+			},
+			errContains: ".up.sql or .down.sql",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			migrations, err := repositories.ParseMigrations(tc.fsys)
+			if tc.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errContains) {
+					t.Fatalf("expected error containing %q, got %v", tc.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, m := range migrations {
+				names = append(names, m.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.wantNames, ",") {
+				t.Fatalf("expected migrations %v, got %v", tc.wantNames, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"delivery-route-service/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Postgres implementation of the GeocodeOverrideRepository port.
+type SQLGeocodeOverrideRepository struct{ DB *sql.DB }
+
+func NewSQLGeocodeOverrideRepository(db *sql.DB) *SQLGeocodeOverrideRepository {
+	return &SQLGeocodeOverrideRepository{DB: db}
+}
+
+// Return all overrides ordered by address.
+func (s *SQLGeocodeOverrideRepository) ListOverrides(ctx context.Context) ([]domain.GeocodeOverride, error) {
+	if s.DB == nil {
+		return nil, errors.New("postgres geocode override repository: DB is nil")
+	}
+
+	query := `
+	SELECT
+		address,
+		lon,
+		lat,
+		created_at
+	FROM geocode_overrides
+	ORDER BY address;
+	`
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list geocode overrides: query: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := make([]domain.GeocodeOverride, 0, 16)
+	for rows.Next() {
+		var o domain.GeocodeOverride
+		if err := rows.Scan(&o.Address, &o.Coordinates.Lon, &o.Coordinates.Lat, &o.CreatedAt); err != nil {
+			return nil, fmt.Errorf("list geocode overrides: scan row: %w", err)
+		}
+		overrides = append(overrides, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list geocode overrides: row iteration: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// Return coordinates for the addresses that have an override.
+func (s *SQLGeocodeOverrideRepository) GetOverrides(
+	ctx context.Context,
+	addresses []string,
+) (map[string]domain.Coordinates, error) {
+	if s.DB == nil {
+		return nil, errors.New("postgres geocode override repository: DB is nil")
+	}
+	if len(addresses) == 0 {
+		return map[string]domain.Coordinates{}, nil
+	}
+
+	query := `
+	SELECT
+		address,
+		lon,
+		lat
+	FROM geocode_overrides
+	WHERE address = ANY($1);
+	`
+	rows, err := s.DB.QueryContext(ctx, query, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("get geocode overrides: query: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]domain.Coordinates)
+	for rows.Next() {
+		var addr string
+		var c domain.Coordinates
+		if err := rows.Scan(&addr, &c.Lon, &c.Lat); err != nil {
+			return nil, fmt.Errorf("get geocode overrides: scan row: %w", err)
+		}
+		out[addr] = c
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("get geocode overrides: row iteration: %w", err)
+	}
+
+	return out, nil
+}
+
+// Create or replace the override for an address.
+func (s *SQLGeocodeOverrideRepository) PutOverride(ctx context.Context, o domain.GeocodeOverride) error {
+	if s.DB == nil {
+		return errors.New("postgres geocode override repository: DB is nil")
+	}
+	if strings.TrimSpace(o.Address) == "" {
+		return errors.New("put geocode override: address must not be empty")
+	}
+
+	createdAt := o.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+
+	query := `
+	INSERT INTO geocode_overrides (address, lon, lat, created_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (address) DO UPDATE
+	SET lon = EXCLUDED.lon, lat = EXCLUDED.lat, created_at = EXCLUDED.created_at;
+	`
+	if _, err := s.DB.ExecContext(ctx, query, o.Address, o.Coordinates.Lon, o.Coordinates.Lat, createdAt); err != nil {
+		return fmt.Errorf("put geocode override %q: %w", o.Address, err)
+	}
+
+	return nil
+}
+
+// Remove the override for an address.
+func (s *SQLGeocodeOverrideRepository) DeleteOverride(ctx context.Context, address string) (bool, error) {
+	if s.DB == nil {
+		return false, errors.New("postgres geocode override repository: DB is nil")
+	}
+
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM geocode_overrides WHERE address = $1;`, address)
+	if err != nil {
+		return false, fmt.Errorf("delete geocode override %q: %w", address, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete geocode override %q: rows affected: %w", address, err)
+	}
+
+	return n > 0, nil
+}
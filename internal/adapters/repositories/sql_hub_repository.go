@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"delivery-route-service/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Postgres implementation of the HubRepository port.
+type SQLHubRepository struct{ DB *sql.DB }
+
+func NewSQLHubRepository(db *sql.DB) *SQLHubRepository {
+	return &SQLHubRepository{DB: db}
+}
+
+// Return all hubs ordered by hub ID.
+func (s *SQLHubRepository) ListHubs(ctx context.Context) ([]*domain.Hub, error) {
+	if s.DB == nil {
+		return nil, errors.New("postgres hub repository: DB is nil")
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `SELECT hub_id, name, address FROM hubs ORDER BY hub_id;`)
+	if err != nil {
+		return nil, fmt.Errorf("list hubs: query: %w", err)
+	}
+	defer rows.Close()
+
+	hubs := make([]*domain.Hub, 0, 4)
+	for rows.Next() {
+		h := &domain.Hub{}
+		if err := rows.Scan(&h.HubID, &h.Name, &h.Address); err != nil {
+			return nil, fmt.Errorf("list hubs: scan row: %w", err)
+		}
+		hubs = append(hubs, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list hubs: row iteration: %w", err)
+	}
+	return hubs, nil
+}
+
+// Create or replace the hub with the given ID.
+func (s *SQLHubRepository) PutHub(ctx context.Context, h *domain.Hub) error {
+	if s.DB == nil {
+		return errors.New("postgres hub repository: DB is nil")
+	}
+	if h.HubID <= 0 {
+		return fmt.Errorf("put hub: hub ID must be positive, got %d", h.HubID)
+	}
+	if strings.TrimSpace(h.Address) == "" {
+		return fmt.Errorf("put hub %d: address must not be empty", h.HubID)
+	}
+
+	query := `
+	INSERT INTO hubs (hub_id, name, address)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (hub_id) DO UPDATE
+	SET name = EXCLUDED.name,
+		address = EXCLUDED.address;
+	`
+	if _, err := s.DB.ExecContext(ctx, query, h.HubID, h.Name, h.Address); err != nil {
+		return fmt.Errorf("put hub %d: %w", h.HubID, err)
+	}
+
+	return nil
+}
+
+// Remove the hub with the given ID; the schema unassigns its packages.
+func (s *SQLHubRepository) DeleteHub(ctx context.Context, id int) (bool, error) {
+	if s.DB == nil {
+		return false, errors.New("postgres hub repository: DB is nil")
+	}
+
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM hubs WHERE hub_id = $1;`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete hub %d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete hub %d: rows affected: %w", id, err)
+	}
+	return n > 0, nil
+}
@@ -6,8 +6,40 @@ import (
 	"delivery-route-service/internal/domain"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// foreignKeyViolation is the Postgres SQLSTATE for a row referencing a
+// missing parent row.
+const foreignKeyViolation = "23503"
+
+// upsertPackageQuery creates or replaces a package. Stored coordinates survive
+// a re-import unless the destination changed; the service time, weight,
+// volume, hub, pickup, service level and deadline are replaced.
+const upsertPackageQuery = `
+	INSERT INTO packages (package_id, destination, lat, lon, service_seconds, weight_kg, volume_m3, hub_id, pickup, service_level, deadline)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11)
+	ON CONFLICT (package_id) DO UPDATE
+	SET destination = EXCLUDED.destination,
+		service_seconds = EXCLUDED.service_seconds,
+		weight_kg = EXCLUDED.weight_kg,
+		volume_m3 = EXCLUDED.volume_m3,
+		hub_id = EXCLUDED.hub_id,
+		pickup = EXCLUDED.pickup,
+		service_level = EXCLUDED.service_level,
+		deadline = EXCLUDED.deadline,
+		lat = CASE
+			WHEN EXCLUDED.lat IS NOT NULL THEN EXCLUDED.lat
+			WHEN packages.destination = EXCLUDED.destination THEN packages.lat
+		END,
+		lon = CASE
+			WHEN EXCLUDED.lon IS NOT NULL THEN EXCLUDED.lon
+			WHEN packages.destination = EXCLUDED.destination THEN packages.lon
+		END;
+	`
+
 // Postgres implementation of the PackageRepository port.
 type SQLPackageRepository struct{ DB *sql.DB }
 
@@ -24,11 +56,20 @@ func (s *SQLPackageRepository) ListPackages(ctx context.Context) ([]*domain.Pack
 	query := `
 	SELECT
 		package_id,
-		destination
+		destination,
+		lat,
+		lon,
+		service_seconds,
+		weight_kg,
+		volume_m3,
+		hub_id,
+		pickup,
+		service_level,
+		deadline
 	FROM packages
 	ORDER BY package_id;
 	`
-	rows, err := s.DB.Query(query)
+	rows, err := s.DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("list packages: query packages table: %w", err)
 	}
@@ -38,11 +79,32 @@ func (s *SQLPackageRepository) ListPackages(ctx context.Context) ([]*domain.Pack
 	for rows.Next() {
 		var id int
 		var dest string
-		err := rows.Scan(&id, &dest)
+		var lat, lon sql.NullFloat64
+		var serviceSeconds sql.NullInt64
+		var weight, volume float64
+		var hubID sql.NullInt64
+		var pickup sql.NullString
+		var level string
+		var deadline sql.NullTime
+		err := rows.Scan(&id, &dest, &lat, &lon, &serviceSeconds, &weight, &volume, &hubID, &pickup, &level, &deadline)
 		if err != nil {
 			return nil, fmt.Errorf("list packages: scan row: %w", err)
 		}
-		packages = append(packages, &domain.Package{PackageID: id, Destination: dest})
+		pkg := &domain.Package{
+			PackageID: id, Destination: dest, WeightKg: weight, VolumeM3: volume,
+			HubID: int(hubID.Int64), Pickup: pickup.String, ServiceLevel: domain.ServiceLevel(level),
+		}
+		if deadline.Valid {
+			pkg.Deadline = &deadline.Time
+		}
+		if lat.Valid && lon.Valid {
+			pkg.Coordinates = &domain.Coordinates{Lon: lon.Float64, Lat: lat.Float64}
+		}
+		if serviceSeconds.Valid {
+			d := time.Duration(serviceSeconds.Int64) * time.Second
+			pkg.ServiceTime = &d
+		}
+		packages = append(packages, pkg)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -51,3 +113,119 @@ func (s *SQLPackageRepository) ListPackages(ctx context.Context) ([]*domain.Pack
 
 	return packages, nil
 }
+
+// Store resolved coordinates on packages in a single transaction.
+func (s *SQLPackageRepository) SetPackageCoordinates(ctx context.Context, coords map[int]domain.Coordinates) error {
+	if s.DB == nil {
+		return errors.New("postgres package repository: DB is nil")
+	}
+	if len(coords) == 0 {
+		return nil
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("set package coordinates: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE packages SET lat = $2, lon = $3 WHERE package_id = $1;`)
+	if err != nil {
+		return fmt.Errorf("set package coordinates: prepare update: %w", err)
+	}
+	defer stmt.Close()
+
+	for id, c := range coords {
+		if _, err := stmt.ExecContext(ctx, id, c.Lat, c.Lon); err != nil {
+			return fmt.Errorf("set package coordinates: update package_id=%d: %w", id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("set package coordinates: commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// Create or replace packages in a single transaction.
+func (s *SQLPackageRepository) UpsertPackages(ctx context.Context, pkgs []*domain.Package) error {
+	if s.DB == nil {
+		return errors.New("postgres package repository: DB is nil")
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("upsert packages: begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, upsertPackageQuery)
+	if err != nil {
+		return fmt.Errorf("upsert packages: prepare insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range pkgs {
+		var lat, lon *float64
+		if p.Coordinates != nil {
+			lat, lon = &p.Coordinates.Lat, &p.Coordinates.Lon
+		}
+		_, err := stmt.ExecContext(ctx, p.PackageID, p.Destination, lat, lon, serviceSeconds(p), p.WeightKg, p.VolumeM3, storedHubID(p.HubID), p.Pickup,
+			storedServiceLevel(p.ServiceLevel), p.Deadline)
+		if err != nil {
+			return fmt.Errorf("upsert packages: insert package_id=%d: %w", p.PackageID, unknownHub(p.PackageID, p.HubID, err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("upsert packages: commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// serviceSeconds returns the package's service time override for storage, or
+// nil when it uses the plan's default.
+func serviceSeconds(p *domain.Package) *int64 {
+	if p.ServiceTime == nil {
+		return nil
+	}
+	secs := int64(*p.ServiceTime / time.Second)
+	return &secs
+}
+
+// storedServiceLevel returns a package's service level for storage, where
+// standard is spelled out.
+func storedServiceLevel(l domain.ServiceLevel) string {
+	if l == "" {
+		return string(domain.ServiceLevelStandard)
+	}
+	return string(l)
+}
+
+// unknownHub turns a foreign key violation on a package's hub into a
+// validation error, since it means no hub with that ID is stored; other
+// errors pass through.
+func unknownHub(packageID, hubID int, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != foreignKeyViolation {
+		return err
+	}
+	return &domain.ValidationError{
+		Field:     "hub_id",
+		PackageID: packageID,
+		Reason:    fmt.Sprintf("hub %d does not exist", hubID),
+	}
+}
+
+// storedHubID returns a package's hub for storage, or nil when it is unassigned.
+func storedHubID(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"delivery-route-service/internal/domain"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Postgres implementation of the TruckRepository port.
+type SQLTruckRepository struct{ DB *sql.DB }
+
+func NewSQLTruckRepository(db *sql.DB) *SQLTruckRepository {
+	return &SQLTruckRepository{DB: db}
+}
+
+const selectTrucksQuery = `
+	SELECT
+		truck_id,
+		name,
+		capacity,
+		max_weight_kg,
+		max_volume_m3,
+		home_hub,
+		vehicle_type,
+		cost_per_km,
+		cost_per_hour,
+		available
+	FROM trucks
+`
+
+// Return all trucks ordered by truck ID.
+func (s *SQLTruckRepository) ListTrucks(ctx context.Context) ([]*domain.Truck, error) {
+	if s.DB == nil {
+		return nil, errors.New("postgres truck repository: DB is nil")
+	}
+
+	rows, err := s.DB.QueryContext(ctx, selectTrucksQuery+"ORDER BY truck_id;")
+	if err != nil {
+		return nil, fmt.Errorf("list trucks: query: %w", err)
+	}
+	defer rows.Close()
+
+	trucks, err := scanTrucks(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list trucks: %w", err)
+	}
+	return trucks, nil
+}
+
+// Return the trucks with the given IDs, keyed by ID.
+func (s *SQLTruckRepository) GetTrucks(ctx context.Context, ids []int) (map[int]*domain.Truck, error) {
+	if s.DB == nil {
+		return nil, errors.New("postgres truck repository: DB is nil")
+	}
+	if len(ids) == 0 {
+		return map[int]*domain.Truck{}, nil
+	}
+
+	rows, err := s.DB.QueryContext(ctx, selectTrucksQuery+"WHERE truck_id = ANY($1);", ids)
+	if err != nil {
+		return nil, fmt.Errorf("get trucks: query: %w", err)
+	}
+	defer rows.Close()
+
+	trucks, err := scanTrucks(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get trucks: %w", err)
+	}
+	out := make(map[int]*domain.Truck, len(trucks))
+	for _, t := range trucks {
+		out[t.TruckID] = t
+	}
+	return out, nil
+}
+
+func scanTrucks(rows *sql.Rows) ([]*domain.Truck, error) {
+	trucks := make([]*domain.Truck, 0, 16)
+	for rows.Next() {
+		t := &domain.Truck{}
+		if err := rows.Scan(
+			&t.TruckID, &t.Name, &t.Capacity, &t.MaxWeightKg, &t.MaxVolumeM3, &t.StartLocation,
+			&t.VehicleType, &t.CostPerKm, &t.CostPerHour, &t.Available,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		trucks = append(trucks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration: %w", err)
+	}
+	return trucks, nil
+}
+
+// Create or replace the truck with the given ID.
+func (s *SQLTruckRepository) PutTruck(ctx context.Context, t *domain.Truck) error {
+	if s.DB == nil {
+		return errors.New("postgres truck repository: DB is nil")
+	}
+	if t.TruckID <= 0 {
+		return fmt.Errorf("put truck: truck ID must be positive, got %d", t.TruckID)
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("put truck %d: name must not be empty", t.TruckID)
+	}
+
+	query := `
+	INSERT INTO trucks (
+		truck_id, name, capacity, max_weight_kg, max_volume_m3,
+		home_hub, vehicle_type, cost_per_km, cost_per_hour, available
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (truck_id) DO UPDATE
+	SET name = EXCLUDED.name,
+		capacity = EXCLUDED.capacity,
+		max_weight_kg = EXCLUDED.max_weight_kg,
+		max_volume_m3 = EXCLUDED.max_volume_m3,
+		home_hub = EXCLUDED.home_hub,
+		vehicle_type = EXCLUDED.vehicle_type,
+		cost_per_km = EXCLUDED.cost_per_km,
+		cost_per_hour = EXCLUDED.cost_per_hour,
+		available = EXCLUDED.available;
+	`
+	if _, err := s.DB.ExecContext(ctx, query,
+		t.TruckID, t.Name, t.Capacity, t.MaxWeightKg, t.MaxVolumeM3, t.StartLocation,
+		t.VehicleType, t.CostPerKm, t.CostPerHour, t.Available,
+	); err != nil {
+		return fmt.Errorf("put truck %d: %w", t.TruckID, err)
+	}
+
+	return nil
+}
+
+// Remove the truck with the given ID.
+func (s *SQLTruckRepository) DeleteTruck(ctx context.Context, id int) (bool, error) {
+	if s.DB == nil {
+		return false, errors.New("postgres truck repository: DB is nil")
+	}
+
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM trucks WHERE truck_id = $1;`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete truck %d: %w", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete truck %d: rows affected: %w", id, err)
+	}
+
+	return n > 0, nil
+}
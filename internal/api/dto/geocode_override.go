@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type GeocodeOverrideRequest struct {
+	Address string   `json:"address"`
+	Lat     *float64 `json:"lat"`
+	Lon     *float64 `json:"lon"`
+}
+
+type GeocodeOverrideResponse struct {
+	Address   string    `json:"address"`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListGeocodeOverridesResponse struct {
+	Overrides []GeocodeOverrideResponse `json:"overrides"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type HubRequest struct {
+	HubID int    `json:"hub_id"`
+	Name  string `json:"name"`
+	// Street address or "lat,lon"; fleet trucks with this home_hub belong to the hub.
+	Address string `json:"address"`
+}
+
+type HubResponse struct {
+	HubID   int    `json:"hub_id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type ListHubsResponse struct {
+	Hubs []HubResponse `json:"hubs"`
+}
@@ -3,12 +3,39 @@ package dto
 import "time"
 
 type PackageResponse struct {
-	PackageID   int        `json:"package_id"`
-	Destination string     `json:"destination"`
-	LoadedAt    *time.Time `json:"loaded_at"`
-	DeliveredAt *time.Time `json:"delivered_at"`
+	PackageID   int      `json:"package_id"`
+	Destination string   `json:"destination"`
+	Lat         *float64 `json:"lat"`
+	Lon         *float64 `json:"lon"`
+	WeightKg    float64  `json:"weight_kg"`
+	VolumeM3    float64  `json:"volume_m3"`
+	// Null when the package is not tied to a hub.
+	HubID *int `json:"hub_id"`
+	// Where the truck collects the package; omitted when it is loaded at the hub.
+	Pickup string `json:"pickup,omitempty"`
+	// "standard", "same_day" or "express"; deadline is null unless the
+	// package has its own.
+	ServiceLevel string     `json:"service_level"`
+	Deadline     *time.Time `json:"deadline"`
+	LoadedAt     *time.Time `json:"loaded_at"`
+	DeliveredAt  *time.Time `json:"delivered_at"`
 }
 
 type ListPackagesResponse struct {
 	Packages []PackageResponse `json:"packages"`
 }
+
+type ImportRowErrorResponse struct {
+	Row       int    `json:"row"`
+	PackageID int    `json:"package_id,omitempty"`
+	Field     string `json:"field,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+type ImportPackagesResponse struct {
+	Mode     string                   `json:"mode"`
+	DryRun   bool                     `json:"dry_run"`
+	Total    int                      `json:"total"`
+	Imported int                      `json:"imported"`
+	Errors   []ImportRowErrorResponse `json:"errors"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"delivery-route-service/internal/domain"
+	"math"
+	"time"
+)
 
 type PlanRequest struct {
 	Hub           string     `json:"hub"`
@@ -8,22 +12,345 @@ type PlanRequest struct {
 	ReturnToStart bool       `json:"return_to_start"`
 	TruckCount    int        `json:"truck_count"`
 	TruckCapacity int        `json:"truck_capacity"`
+	// Weight and volume limits per truck; omitted or zero means no limit.
+	TruckMaxWeightKg float64 `json:"truck_max_weight_kg"`
+	TruckMaxVolumeM3 float64 `json:"truck_max_volume_m3"`
+	// Fleet trucks to plan for; replaces truck_count, truck_capacity and the truck limits.
+	TruckIDs []int `json:"truck_ids"`
+	// Include the encoded road polyline and turn-by-turn directions per truck.
+	IncludeGeometry bool `json:"include_geometry"`
+	// Stop ordering: "nearest_neighbor" (default) or "two_opt".
+	Strategy string `json:"strategy"`
+	// What planning minimizes: "duration" (default), "distance", "cost" or "makespan".
+	Objective string `json:"objective"`
+	// Even out workloads between trucks; omitted leaves the assignment as is.
+	Balance *BalanceRequest `json:"balance"`
+	// Time spent at stops; omitted fields use the configured defaults.
+	ServiceTime *ServiceTimeRequest `json:"service_time"`
+	// Driver shift limits; omitted fields use the configured defaults.
+	Shift *ShiftRequest `json:"shift"`
+	// Plan from every stored hub instead of hub, with truck_count trucks per
+	// hub or the truck_ids trucks at their home hubs.
+	MultiDepot bool `json:"multi_depot"`
+	// Let each truck return to its hub, reload for reload_seconds and run
+	// further trips until its shift ends; omitted reload time uses the default.
+	MultiTrip     bool `json:"multi_trip"`
+	ReloadSeconds *int `json:"reload_seconds"`
+	// Promise windows of priority service levels; omitted fields use the defaults.
+	Promises *PromisesRequest `json:"promises"`
+}
+
+type BalanceRequest struct {
+	// "duration" or "packages".
+	Mode string `json:"mode"`
+	// Fraction of the average workload the busiest truck may be ahead of the
+	// idlest; omitted uses the configured default.
+	Tolerance *float64 `json:"tolerance"`
+}
+
+type PromisesRequest struct {
+	SameDaySeconds *int `json:"same_day_seconds"`
+	ExpressSeconds *int `json:"express_seconds"`
+}
+
+type ServiceTimeRequest struct {
+	PerStopSeconds    *int `json:"per_stop_seconds"`
+	PerPackageSeconds *int `json:"per_package_seconds"`
+	// Whole-stop service time by destination address, replacing the computed one.
+	Destinations map[string]int `json:"destinations"`
+}
+
+type ShiftRequest struct {
+	MaxDurationSeconds   *int `json:"max_duration_seconds"`
+	BreakAfterSeconds    *int `json:"break_after_seconds"`
+	BreakDurationSeconds *int `json:"break_duration_seconds"`
 }
 
 type PlanStopResponse struct {
-	Destination string    `json:"destination"`
-	ArriveAt    time.Time `json:"arrive_at"`
-	PackageIDs  []int     `json:"package_ids"`
+	Destination    string    `json:"destination"`
+	ArriveAt       time.Time `json:"arrive_at"`
+	DepartAt       time.Time `json:"depart_at"`
+	ServiceSeconds int       `json:"service_seconds"`
+	BreakSeconds   int       `json:"break_seconds"`
+	PackageIDs     []int     `json:"package_ids"`
+	// Packages collected at this stop for delivery later in the route.
+	PickupIDs                []int         `json:"pickup_ids,omitempty"`
+	OnBoard                  CargoResponse `json:"on_board"`
+	CumulativeDistanceMeters int           `json:"cumulative_distance_meters"`
+}
+
+// CargoResponse is what a truck carries when it leaves a stop.
+type CargoResponse struct {
+	Packages int     `json:"packages"`
+	WeightKg float64 `json:"weight_kg"`
+	VolumeM3 float64 `json:"volume_m3"`
 }
 
 type PlanResponse struct {
-	TruckID              int                `json:"truck_id"`
-	DepartAt             time.Time          `json:"depart_at"`
-	TotalDistanceMeters  int                `json:"total_distance_meters"`
-	TotalDurationSeconds int                `json:"total_duration_seconds"`
-	Stops                []PlanStopResponse `json:"stops"`
+	TruckID              int                 `json:"truck_id"`
+	TruckName            string              `json:"truck_name,omitempty"`
+	Trip                 int                 `json:"trip,omitempty"`
+	StartLocation        string              `json:"start_location"`
+	DepartAt             time.Time           `json:"depart_at"`
+	TotalDistanceMeters  int                 `json:"total_distance_meters"`
+	TotalDurationSeconds int                 `json:"total_duration_seconds"`
+	TotalServiceSeconds  int                 `json:"total_service_seconds"`
+	TotalBreakSeconds    int                 `json:"total_break_seconds"`
+	TotalCost            float64             `json:"total_cost"`
+	Utilization          UtilizationResponse `json:"utilization"`
+	Stops                []PlanStopResponse  `json:"stops"`
+	// Present only when include_geometry was requested.
+	Geometry *PlanGeometryResponse `json:"geometry,omitempty"`
+}
+
+// UtilizationResponse reports a truck's load against its limit in each
+// capacity dimension.
+type UtilizationResponse struct {
+	Packages DimensionUsageResponse `json:"packages"`
+	WeightKg DimensionUsageResponse `json:"weight_kg"`
+	VolumeM3 DimensionUsageResponse `json:"volume_m3"`
+}
+
+type DimensionUsageResponse struct {
+	Used float64 `json:"used"`
+	// Limit and Ratio (Used / Limit) are null when the truck has no limit.
+	Limit *float64 `json:"limit"`
+	Ratio *float64 `json:"ratio"`
+}
+
+type PlanGeometryResponse struct {
+	// Encoded polyline (precision 5) covering the whole route.
+	Polyline string            `json:"polyline"`
+	Legs     []PlanLegResponse `json:"legs"`
+}
+
+type PlanLegResponse struct {
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	DistanceMeters  int                `json:"distance_meters"`
+	DurationSeconds int                `json:"duration_seconds"`
+	Steps           []PlanStepResponse `json:"steps"`
+}
+
+type PlanStepResponse struct {
+	Instruction     string `json:"instruction"`
+	Street          string `json:"street,omitempty"`
+	DistanceMeters  int    `json:"distance_meters"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+type UngeocodableResponse struct {
+	Address    string `json:"address"`
+	Reason     string `json:"reason"`
+	PackageIDs []int  `json:"package_ids"`
+}
+
+type UnassignedResponse struct {
+	Address    string `json:"address"`
+	Reason     string `json:"reason"`
+	PackageIDs []int  `json:"package_ids"`
+}
+
+// MissedPromiseResponse flags a package the plan delivers after its
+// deadline, or not at all, in which case truck_id is omitted and arrive_at
+// is null.
+type MissedPromiseResponse struct {
+	PackageID    int        `json:"package_id"`
+	ServiceLevel string     `json:"service_level"`
+	Deadline     time.Time  `json:"deadline"`
+	TruckID      int        `json:"truck_id,omitempty"`
+	Trip         int        `json:"trip,omitempty"`
+	ArriveAt     *time.Time `json:"arrive_at"`
+	LateSeconds  int        `json:"late_seconds,omitempty"`
+}
+
+// PlanMetricsResponse totals the plans in every objective, whichever one
+// planning minimized. finish_at is null when nothing is planned.
+type PlanMetricsResponse struct {
+	Objective            string     `json:"objective"`
+	TotalDistanceMeters  int        `json:"total_distance_meters"`
+	TotalDurationSeconds int        `json:"total_duration_seconds"`
+	TotalCost            float64    `json:"total_cost"`
+	MakespanSeconds      int        `json:"makespan_seconds"`
+	FinishAt             *time.Time `json:"finish_at"`
+	// Spread of route durations across trucks, a truck's trips together.
+	RouteDurations RouteDurationsResponse `json:"route_durations"`
+}
+
+type RouteDurationsResponse struct {
+	MinSeconds    int     `json:"min_seconds"`
+	MaxSeconds    int     `json:"max_seconds"`
+	StdDevSeconds float64 `json:"stddev_seconds"`
 }
 
 type ListPlanResponse struct {
-	Plans []PlanResponse `json:"plans"`
+	Plans          []PlanResponse          `json:"plans"`
+	Metrics        PlanMetricsResponse     `json:"metrics"`
+	Ungeocodable   []UngeocodableResponse  `json:"ungeocodable"`
+	Unassigned     []UnassignedResponse    `json:"unassigned"`
+	MissedPromises []MissedPromiseResponse `json:"missed_promises"`
+}
+
+// TruckTripsResponse nests the trips of one truck in a multi-trip plan.
+// Totals run from the first departure until the last trip is back, and
+// include the reloads between trips.
+type TruckTripsResponse struct {
+	TruckID              int            `json:"truck_id"`
+	TruckName            string         `json:"truck_name,omitempty"`
+	StartLocation        string         `json:"start_location"`
+	DepartAt             time.Time      `json:"depart_at"`
+	ReturnAt             time.Time      `json:"return_at"`
+	TotalDistanceMeters  int            `json:"total_distance_meters"`
+	TotalDurationSeconds int            `json:"total_duration_seconds"`
+	TotalReloadSeconds   int            `json:"total_reload_seconds"`
+	TotalCost            float64        `json:"total_cost"`
+	Trips                []PlanResponse `json:"trips"`
+}
+
+type ListTruckTripsResponse struct {
+	Trucks         []TruckTripsResponse    `json:"trucks"`
+	Metrics        PlanMetricsResponse     `json:"metrics"`
+	Ungeocodable   []UngeocodableResponse  `json:"ungeocodable"`
+	Unassigned     []UnassignedResponse    `json:"unassigned"`
+	MissedPromises []MissedPromiseResponse `json:"missed_promises"`
+}
+
+type DepotPlansResponse struct {
+	HubID   int            `json:"hub_id"`
+	Name    string         `json:"name"`
+	Address string         `json:"address"`
+	Plans   []PlanResponse `json:"plans"`
+}
+
+type ListDepotPlansResponse struct {
+	Depots         []DepotPlansResponse    `json:"depots"`
+	Metrics        PlanMetricsResponse     `json:"metrics"`
+	Ungeocodable   []UngeocodableResponse  `json:"ungeocodable"`
+	Unassigned     []UnassignedResponse    `json:"unassigned"`
+	MissedPromises []MissedPromiseResponse `json:"missed_promises"`
+}
+
+// NewTruckTripsResponses groups multi-trip plans by truck, keeping the order
+// in which each truck first appears and its trips in plan order.
+func NewTruckTripsResponses(plans []*domain.RoutePlan) []TruckTripsResponse {
+	res := make([]TruckTripsResponse, 0, len(plans))
+	index := make(map[int]int, len(plans))
+	for _, p := range plans {
+		back := p.DepartAt.Add(time.Duration(p.TotalDurationSeconds) * time.Second)
+
+		i, ok := index[p.TruckID]
+		if !ok {
+			i = len(res)
+			index[p.TruckID] = i
+			res = append(res, TruckTripsResponse{
+				TruckID:       p.TruckID,
+				TruckName:     p.TruckName,
+				StartLocation: p.StartLocation,
+				DepartAt:      p.DepartAt,
+				Trips:         []PlanResponse{},
+			})
+		} else {
+			res[i].TotalReloadSeconds += int(p.DepartAt.Sub(res[i].ReturnAt) / time.Second)
+		}
+
+		t := &res[i]
+		t.ReturnAt = back
+		t.TotalDistanceMeters += p.TotalDistanceMeters
+		t.TotalDurationSeconds = int(back.Sub(t.DepartAt) / time.Second)
+		t.TotalCost = roundTo(t.TotalCost+p.TotalCost, 2)
+		t.Trips = append(t.Trips, NewPlanResponse(p))
+	}
+	return res
+}
+
+// NewPlanResponse maps a route plan onto its response shape.
+func NewPlanResponse(p *domain.RoutePlan) PlanResponse {
+	stops := make([]PlanStopResponse, 0, len(p.Stops))
+	for _, s := range p.Stops {
+		stops = append(stops, PlanStopResponse{
+			Destination:    s.Destination,
+			ArriveAt:       s.ArriveAt,
+			DepartAt:       s.DepartAt,
+			ServiceSeconds: s.ServiceSeconds,
+			BreakSeconds:   s.BreakSeconds,
+			PackageIDs:     s.PackageIDs,
+			PickupIDs:      s.PickupIDs,
+			OnBoard: CargoResponse{
+				Packages: s.OnBoard.Packages,
+				WeightKg: roundTo(s.OnBoard.WeightKg, 3),
+				VolumeM3: roundTo(s.OnBoard.VolumeM3, 3),
+			},
+			CumulativeDistanceMeters: s.CumulativeDistanceMeters,
+		})
+	}
+
+	return PlanResponse{
+		TruckID:              p.TruckID,
+		TruckName:            p.TruckName,
+		Trip:                 p.Trip,
+		StartLocation:        p.StartLocation,
+		DepartAt:             p.DepartAt,
+		TotalDistanceMeters:  p.TotalDistanceMeters,
+		TotalDurationSeconds: p.TotalDurationSeconds,
+		TotalServiceSeconds:  p.TotalServiceSeconds,
+		TotalBreakSeconds:    p.TotalBreakSeconds,
+		TotalCost:            roundTo(p.TotalCost, 2),
+		Utilization: UtilizationResponse{
+			Packages: newDimensionUsage(float64(p.Load.Packages), float64(p.Capacity.Packages)),
+			WeightKg: newDimensionUsage(p.Load.WeightKg, p.Capacity.WeightKg),
+			VolumeM3: newDimensionUsage(p.Load.VolumeM3, p.Capacity.VolumeM3),
+		},
+		Stops:    stops,
+		Geometry: newGeometryResponse(p.Geometry),
+	}
+}
+
+// newDimensionUsage reports used against limit, leaving the limit and ratio
+// null when limit is zero. Values are rounded to keep summed weights readable.
+func newDimensionUsage(used, limit float64) DimensionUsageResponse {
+	u := DimensionUsageResponse{Used: roundTo(used, 3)}
+	if limit > 0 {
+		l := roundTo(limit, 3)
+		r := roundTo(used/limit, 4)
+		u.Limit, u.Ratio = &l, &r
+	}
+	return u
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// newGeometryResponse maps route geometry onto its response shape; nil stays
+// nil so the field is omitted when geometry was not requested.
+func newGeometryResponse(g *domain.RouteGeometry) *PlanGeometryResponse {
+	if g == nil {
+		return nil
+	}
+
+	res := &PlanGeometryResponse{
+		Polyline: g.Polyline,
+		Legs:     make([]PlanLegResponse, 0, len(g.Legs)),
+	}
+	for _, l := range g.Legs {
+		steps := make([]PlanStepResponse, 0, len(l.Steps))
+		for _, s := range l.Steps {
+			steps = append(steps, PlanStepResponse{
+				Instruction:     s.Instruction,
+				Street:          s.Street,
+				DistanceMeters:  s.DistanceMeters,
+				DurationSeconds: s.DurationSeconds,
+			})
+		}
+		res.Legs = append(res.Legs, PlanLegResponse{
+			From:            l.From,
+			To:              l.To,
+			DistanceMeters:  l.DistanceMeters,
+			DurationSeconds: l.DurationSeconds,
+			Steps:           steps,
+		})
+	}
+
+	return res
 }
@@ -0,0 +1,34 @@
+package dto
+
+type TruckRequest struct {
+	TruckID  int    `json:"truck_id"`
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+	// Total package weight and volume limits; zero means no limit.
+	MaxWeightKg float64 `json:"max_weight_kg"`
+	MaxVolumeM3 float64 `json:"max_volume_m3"`
+	// Start location for plans; empty starts from the plan's hub.
+	HomeHub     string  `json:"home_hub"`
+	VehicleType string  `json:"vehicle_type"`
+	CostPerKm   float64 `json:"cost_per_km"`
+	CostPerHour float64 `json:"cost_per_hour"`
+	// Omitted means available.
+	Available *bool `json:"available"`
+}
+
+type TruckResponse struct {
+	TruckID     int     `json:"truck_id"`
+	Name        string  `json:"name"`
+	Capacity    int     `json:"capacity"`
+	MaxWeightKg float64 `json:"max_weight_kg"`
+	MaxVolumeM3 float64 `json:"max_volume_m3"`
+	HomeHub     string  `json:"home_hub"`
+	VehicleType string  `json:"vehicle_type"`
+	CostPerKm   float64 `json:"cost_per_km"`
+	CostPerHour float64 `json:"cost_per_hour"`
+	Available   bool    `json:"available"`
+}
+
+type ListTrucksResponse struct {
+	Trucks []TruckResponse `json:"trucks"`
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Problem type URIs identify each error class independently of the HTTP status.
+const (
+	problemValidation          = "/problems/validation"
+	problemCapacityExceeded    = "/problems/capacity-exceeded"
+	problemAddressNotFound     = "/problems/address-not-geocodable"
+	problemUpstreamLimited     = "/problems/upstream-rate-limited"
+	problemUpstreamUnavailable = "/problems/upstream-unavailable"
+)
+
+// writeValidationError writes a 400 validation problem about a request field.
+func writeValidationError(w http.ResponseWriter, r *http.Request, field, detail string) {
+	writeProblem(w, r, problem{
+		Type:   problemValidation,
+		Status: http.StatusBadRequest,
+		Detail: detail,
+		Field:  field,
+	})
+}
+
+// writeServiceError maps typed service and adapter errors to an HTTP status
+// and problem body. Unclassified errors are logged and reported as 500
+// without leaking internal details.
+func writeServiceError(w http.ResponseWriter, r *http.Request, op string, err error) {
+	var (
+		validationErr *domain.ValidationError
+		capacityErr   *domain.CapacityError
+		addressErr    *ports.AddressError
+		upstreamErr   *ports.UpstreamError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		p := problem{
+			Type:   problemValidation,
+			Title:  "Invalid request",
+			Status: http.StatusBadRequest,
+			Detail: validationErr.Error(),
+			Field:  validationErr.Field,
+		}
+		if validationErr.PackageID != 0 {
+			p.PackageIDs = []int{validationErr.PackageID}
+		}
+		writeProblem(w, r, p)
+
+	case errors.As(err, &capacityErr):
+		writeProblem(w, r, problem{
+			Type:       problemCapacityExceeded,
+			Title:      "Truck capacity exceeded",
+			Status:     http.StatusUnprocessableEntity,
+			Detail:     capacityErr.Error(),
+			TruckID:    capacityErr.TruckID,
+			PackageIDs: []int{capacityErr.PackageID},
+		})
+
+	case errors.As(err, &addressErr):
+		writeProblem(w, r, problem{
+			Type:       problemAddressNotFound,
+			Title:      "Address not geocodable",
+			Status:     http.StatusUnprocessableEntity,
+			Detail:     addressErr.Error(),
+			Address:    addressErr.Address,
+			PackageIDs: addressErr.PackageIDs,
+		})
+
+	case errors.Is(err, ports.ErrUpstreamRateLimited):
+		log.Printf("%s failed: %v", op, err)
+		if errors.As(err, &upstreamErr) && upstreamErr.RetryAfter > 0 {
+			secs := int(math.Ceil(upstreamErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+		}
+		writeProblem(w, r, problem{
+			Type:   problemUpstreamLimited,
+			Title:  "Routing provider rate limited",
+			Status: http.StatusTooManyRequests,
+			Detail: "the routing provider is rate limiting requests; retry later",
+		})
+
+	case errors.Is(err, ports.ErrUpstreamUnavailable):
+		log.Printf("%s failed: %v", op, err)
+		writeProblem(w, r, problem{
+			Type:   problemUpstreamUnavailable,
+			Title:  "Routing provider unavailable",
+			Status: http.StatusServiceUnavailable,
+			Detail: "the routing provider is temporarily unavailable; retry later",
+		})
+
+	default:
+		log.Printf("%s failed: %v", op, err)
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+	}
+}
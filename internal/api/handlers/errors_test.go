@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteServiceError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantType       string
+		wantRetryAfter string
+		check          func(t *testing.T, p problem)
+	}{
+		{
+			name:       "validation error maps to 400 with field",
+			err:        fmt.Errorf("plan deliveries: %w", &domain.ValidationError{Field: "hub", Reason: "hub address must not be empty"}),
+			wantStatus: http.StatusBadRequest,
+			wantType:   problemValidation,
+			check: func(t *testing.T, p problem) {
+				if p.Field != "hub" {
+					t.Fatalf("expected field %q, got %q", "hub", p.Field)
+				}
+			},
+		},
+		{
+			name:       "capacity error maps to 422 naming truck and package",
+			err:        fmt.Errorf("assign packages: %w", &domain.CapacityError{TruckID: 2, Capacity: 1, PackageID: 7}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   problemCapacityExceeded,
+			check: func(t *testing.T, p problem) {
+				if p.TruckID != 2 || len(p.PackageIDs) != 1 || p.PackageIDs[0] != 7 {
+					t.Fatalf("expected truck 2 and package 7, got truck=%d packages=%v", p.TruckID, p.PackageIDs)
+				}
+			},
+		},
+		{
+			name:       "address error maps to 422 naming address",
+			err:        fmt.Errorf("retrieving coordinates: %w", &ports.AddressError{Address: "Nowhere", Reason: "no geocode results", PackageIDs: []int{3}}),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantType:   problemAddressNotFound,
+			check: func(t *testing.T, p problem) {
+				if p.Address != "Nowhere" || len(p.PackageIDs) != 1 || p.PackageIDs[0] != 3 {
+					t.Fatalf("expected address Nowhere and package 3, got address=%q packages=%v", p.Address, p.PackageIDs)
+				}
+			},
+		},
+		{
+			name:           "upstream 429 maps to 429 with Retry-After",
+			err:            fmt.Errorf("matrix request failed: %w", &ports.UpstreamError{Service: "ors", StatusCode: 429, RetryAfter: 3 * time.Second, Err: errors.New("slow down")}),
+			wantStatus:     http.StatusTooManyRequests,
+			wantType:       problemUpstreamLimited,
+			wantRetryAfter: "3",
+		},
+		{
+			name:       "upstream 5xx maps to 503",
+			err:        &ports.UpstreamError{Service: "ors", StatusCode: 502, Err: errors.New("bad gateway")},
+			wantStatus: http.StatusServiceUnavailable,
+			wantType:   problemUpstreamUnavailable,
+		},
+		{
+			name:       "upstream transport failure maps to 503",
+			err:        &ports.UpstreamError{Service: "ors", Err: context.DeadlineExceeded},
+			wantStatus: http.StatusServiceUnavailable,
+			wantType:   problemUpstreamUnavailable,
+		},
+		{
+			name:       "unclassified error maps to 500",
+			err:        errors.New("database unavailable"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "about:blank",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/plans", nil)
+			w := httptest.NewRecorder()
+
+			writeServiceError(w, r, "test", tc.err)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Fatalf("expected problem+json content type, got %q", ct)
+			}
+			if got := w.Header().Get("Retry-After"); got != tc.wantRetryAfter {
+				t.Fatalf("expected Retry-After %q, got %q", tc.wantRetryAfter, got)
+			}
+
+			var p problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("decode problem body: %v", err)
+			}
+			if p.Type != tc.wantType || p.Status != tc.wantStatus {
+				t.Fatalf("expected type=%q status=%d, got type=%q status=%d", tc.wantType, tc.wantStatus, p.Type, p.Status)
+			}
+			if tc.check != nil {
+				tc.check(t, p)
+			}
+		})
+	}
+}
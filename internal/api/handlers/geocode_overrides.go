@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GeocodeOverrideHandler manages manual address -> coordinate overrides.
+type GeocodeOverrideHandler struct {
+	Repo ports.GeocodeOverrideRepository
+	// Optional; cached distances for an address are dropped when its override changes.
+	Invalidator ports.DistanceCacheInvalidator
+}
+
+// Route dispatches /geocode-overrides by method.
+func (h *GeocodeOverrideHandler) Route(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *GeocodeOverrideHandler) List(w http.ResponseWriter, r *http.Request) {
+	overrides, err := h.Repo.ListOverrides(r.Context())
+	if err != nil {
+		writeServiceError(w, r, "list geocode overrides", err)
+		return
+	}
+
+	res := dto.ListGeocodeOverridesResponse{
+		Overrides: make([]dto.GeocodeOverrideResponse, 0, len(overrides)),
+	}
+	for _, o := range overrides {
+		res.Overrides = append(res.Overrides, toGeocodeOverrideResponse(o))
+	}
+
+	writeJSON(w, r, http.StatusOK, res)
+}
+
+// Create stores or replaces the override for an address.
+func (h *GeocodeOverrideHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.GeocodeOverrideRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	address := normalizeAddress(req.Address)
+	if address == "" {
+		writeValidationError(w, r, "address", "address is required")
+		return
+	}
+	if req.Lat == nil || *req.Lat < -90 || *req.Lat > 90 {
+		writeValidationError(w, r, "lat", "lat is required and must be between -90 and 90")
+		return
+	}
+	if req.Lon == nil || *req.Lon < -180 || *req.Lon > 180 {
+		writeValidationError(w, r, "lon", "lon is required and must be between -180 and 180")
+		return
+	}
+
+	override := domain.GeocodeOverride{
+		Address:     address,
+		Coordinates: domain.Coordinates{Lon: *req.Lon, Lat: *req.Lat},
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := h.Repo.PutOverride(r.Context(), override); err != nil {
+		writeServiceError(w, r, "put geocode override", err)
+		return
+	}
+	h.invalidate(r, address)
+
+	writeJSON(w, r, http.StatusCreated, toGeocodeOverrideResponse(override))
+}
+
+// Delete removes the override named by the address query parameter.
+func (h *GeocodeOverrideHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	address := normalizeAddress(r.URL.Query().Get("address"))
+	if address == "" {
+		writeValidationError(w, r, "address", "address query parameter is required")
+		return
+	}
+
+	found, err := h.Repo.DeleteOverride(r.Context(), address)
+	if err != nil {
+		writeServiceError(w, r, "delete geocode override", err)
+		return
+	}
+	if !found {
+		writeError(w, r, http.StatusNotFound, "no override for address")
+		return
+	}
+	h.invalidate(r, address)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// invalidate drops cached distances computed from the address's previous
+// coordinates. Failure is logged only; stale entries expire with the cache TTL.
+func (h *GeocodeOverrideHandler) invalidate(r *http.Request, address string) {
+	if h.Invalidator == nil {
+		return
+	}
+	if err := h.Invalidator.InvalidateAddress(r.Context(), address); err != nil {
+		log.Printf("invalidate distance cache for %q failed: %v", address, err)
+	}
+}
+
+// normalizeAddress collapses whitespace the same way the distance provider
+// does, so overrides match the keys used during planning.
+func normalizeAddress(address string) string {
+	return strings.Join(strings.Fields(address), " ")
+}
+
+func toGeocodeOverrideResponse(o domain.GeocodeOverride) dto.GeocodeOverrideResponse {
+	return dto.GeocodeOverrideResponse{
+		Address:   o.Address,
+		Lat:       o.Coordinates.Lat,
+		Lon:       o.Coordinates.Lon,
+		CreatedAt: o.CreatedAt,
+	}
+}
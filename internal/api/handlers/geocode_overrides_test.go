@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"context"
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type recordingInvalidator struct{ addresses []string }
+
+func (r *recordingInvalidator) InvalidateAddress(ctx context.Context, address string) error {
+	r.addresses = append(r.addresses, address)
+	return nil
+}
+
+func TestGeocodeOverrideHandler(t *testing.T) {
+	existing := domain.GeocodeOverride{Address: "1 Main St", Coordinates: domain.Coordinates{Lon: -112.1, Lat: 33.4}}
+
+	tests := []struct {
+		name            string
+		method          string
+		target          string
+		body            string
+		wantStatus      int
+		wantField       string
+		wantInvalidated []string
+		wantStored      map[string]domain.Coordinates
+	}{
+		{
+			name:       "list returns stored overrides",
+			method:     http.MethodGet,
+			target:     "/geocode-overrides",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:            "create normalizes address and invalidates cached distances",
+			method:          http.MethodPost,
+			target:          "/geocode-overrides",
+			body:            `{"address":"  9  Dock Rd ","lat":33.5,"lon":-111.9}`,
+			wantStatus:      http.StatusCreated,
+			wantInvalidated: []string{"9 Dock Rd"},
+			wantStored:      map[string]domain.Coordinates{"9 Dock Rd": {Lon: -111.9, Lat: 33.5}},
+		},
+		{
+			name:       "create rejects out of range latitude",
+			method:     http.MethodPost,
+			target:     "/geocode-overrides",
+			body:       `{"address":"9 Dock Rd","lat":95,"lon":-111.9}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "lat",
+		},
+		{
+			name:       "create rejects missing coordinates",
+			method:     http.MethodPost,
+			target:     "/geocode-overrides",
+			body:       `{"address":"9 Dock Rd"}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "lat",
+		},
+		{
+			name:       "create rejects out of range longitude",
+			method:     http.MethodPost,
+			target:     "/geocode-overrides",
+			body:       `{"address":"9 Dock Rd","lat":33.5,"lon":190}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "lon",
+		},
+		{
+			name:       "create requires an address",
+			method:     http.MethodPost,
+			target:     "/geocode-overrides",
+			body:       `{"address":"  ","lat":33.5,"lon":-111.9}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "address",
+		},
+		{
+			name:            "delete removes existing override",
+			method:          http.MethodDelete,
+			target:          "/geocode-overrides?address=1+Main+St",
+			wantStatus:      http.StatusNoContent,
+			wantInvalidated: []string{"1 Main St"},
+		},
+		{
+			name:       "delete without address is 400",
+			method:     http.MethodDelete,
+			target:     "/geocode-overrides",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "address",
+		},
+		{
+			name:       "delete unknown address is 404",
+			method:     http.MethodDelete,
+			target:     "/geocode-overrides?address=Elsewhere",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unsupported method is 405",
+			method:     http.MethodPut,
+			target:     "/geocode-overrides",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockGeocodeOverrideRepository([]domain.GeocodeOverride{existing}, nil)
+			inv := &recordingInvalidator{}
+			h := &handlers.GeocodeOverrideHandler{Repo: repo, Invalidator: inv}
+
+			r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			h.Route(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if tc.wantField != "" {
+				assertValidationProblem(t, w, tc.wantField)
+			}
+			if strings.Join(inv.addresses, ",") != strings.Join(tc.wantInvalidated, ",") {
+				t.Fatalf("expected invalidated %v, got %v", tc.wantInvalidated, inv.addresses)
+			}
+			for addr, want := range tc.wantStored {
+				got, ok := repo.Overrides[addr]
+				if !ok || got.Coordinates != want {
+					t.Fatalf("expected stored override %q=%+v, got %+v (found=%v)", addr, want, got.Coordinates, ok)
+				}
+			}
+		})
+	}
+}
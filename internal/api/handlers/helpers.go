@@ -2,18 +2,88 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"strings"
 )
 
+// problem is an RFC 9457 problem details body.
+// Extension members name the offending request field, package or address when known.
+type problem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Instance   string `json:"instance,omitempty"`
+	Field      string `json:"field,omitempty"`
+	PackageIDs []int  `json:"package_ids,omitempty"`
+	TruckID    int    `json:"truck_id,omitempty"`
+	Address    string `json:"address,omitempty"`
+}
+
 func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
-	w.Header().Set("Content-Type", "application/json")
+	writeJSONAs(w, r, status, "application/json", v)
+}
+
+// writeJSONAs encodes v as JSON under a JSON-based media type such as application/geo+json.
+func writeJSONAs(w http.ResponseWriter, r *http.Request, status int, contentType string, v any) {
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
 		log.Printf("encode failed: method=%s path=%s err=%v", r.Method, r.URL.Path, err)
 	}
 }
 
+func writeProblem(w http.ResponseWriter, r *http.Request, p problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("encode failed: method=%s path=%s err=%v", r.Method, r.URL.Path, err)
+	}
+}
+
 func writeError(w http.ResponseWriter, r *http.Request, status int, msg string) {
-	writeJSON(w, r, status, map[string]string{"error": msg})
+	writeProblem(w, r, problem{Status: status, Detail: msg})
+}
+
+// decodeJSONBody strictly decodes a single JSON object from the request body.
+// On failure it writes a 400 response and returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json body")
+		return false
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		writeError(w, r, http.StatusBadRequest, "body must contain only one JSON object")
+		return false
+	}
+	return true
+}
+
+// accepts reports whether the Accept header explicitly lists mediaType.
+// Wildcards are ignored so JSON stays the default representation.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && strings.EqualFold(mt, mediaType) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HubHandler manages the depots used by multi-depot planning.
+type HubHandler struct {
+	Repo ports.HubRepository
+}
+
+// Route dispatches /hubs by method.
+func (h *HubHandler) Route(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *HubHandler) List(w http.ResponseWriter, r *http.Request) {
+	hubs, err := h.Repo.ListHubs(r.Context())
+	if err != nil {
+		writeServiceError(w, r, "list hubs", err)
+		return
+	}
+
+	res := dto.ListHubsResponse{
+		Hubs: make([]dto.HubResponse, 0, len(hubs)),
+	}
+	for _, hub := range hubs {
+		res.Hubs = append(res.Hubs, toHubResponse(hub))
+	}
+
+	writeJSON(w, r, http.StatusOK, res)
+}
+
+// Create stores or replaces the hub with the given ID.
+func (h *HubHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.HubRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	hub := &domain.Hub{
+		HubID:   req.HubID,
+		Name:    strings.TrimSpace(req.Name),
+		Address: normalizeAddress(req.Address),
+	}
+	field, reason := "", ""
+	switch {
+	case hub.HubID < 1:
+		field, reason = "hub_id", "hub_id must be a positive integer"
+	case hub.Name == "":
+		field, reason = "name", "name is required"
+	case hub.Address == "":
+		field, reason = "address", "address is required"
+	}
+	if field != "" {
+		writeValidationError(w, r, field, reason)
+		return
+	}
+
+	if err := h.Repo.PutHub(r.Context(), hub); err != nil {
+		writeServiceError(w, r, "put hub", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, toHubResponse(hub))
+}
+
+// Delete removes the hub named by the hub_id query parameter. Its packages
+// become unassigned.
+func (h *HubHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("hub_id"))
+	if err != nil || id < 1 {
+		writeValidationError(w, r, "hub_id", "hub_id query parameter must be a positive integer")
+		return
+	}
+
+	found, err := h.Repo.DeleteHub(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, "delete hub", err)
+		return
+	}
+	if !found {
+		writeError(w, r, http.StatusNotFound, "no hub with that hub_id")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toHubResponse(h *domain.Hub) dto.HubResponse {
+	return dto.HubResponse{HubID: h.HubID, Name: h.Name, Address: h.Address}
+}
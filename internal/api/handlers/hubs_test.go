@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHubHandler(t *testing.T) {
+	existing := &domain.Hub{HubID: 1, Name: "North", Address: "1 Main St"}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantField  string
+		wantStored *domain.Hub
+	}{
+		{
+			name:       "list returns stored hubs",
+			method:     http.MethodGet,
+			target:     "/hubs",
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"North"`,
+		},
+		{
+			name:       "create normalizes the address",
+			method:     http.MethodPost,
+			target:     "/hubs",
+			body:       `{"hub_id":2,"name":" South ","address":" 9  Dock Rd "}`,
+			wantStatus: http.StatusCreated,
+			wantStored: &domain.Hub{HubID: 2, Name: "South", Address: "9 Dock Rd"},
+		},
+		{
+			name:       "create rejects non-positive hub_id",
+			method:     http.MethodPost,
+			target:     "/hubs",
+			body:       `{"hub_id":0,"name":"South","address":"9 Dock Rd"}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "hub_id",
+		},
+		{
+			name:       "create requires an address",
+			method:     http.MethodPost,
+			target:     "/hubs",
+			body:       `{"hub_id":2,"name":"South","address":"  "}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "address",
+		},
+		{
+			name:       "delete removes existing hub",
+			method:     http.MethodDelete,
+			target:     "/hubs?hub_id=1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete unknown hub is 404",
+			method:     http.MethodDelete,
+			target:     "/hubs?hub_id=9",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "delete without a valid hub_id is 400",
+			method:     http.MethodDelete,
+			target:     "/hubs?hub_id=abc",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "hub_id",
+		},
+		{
+			name:       "unsupported method is 405",
+			method:     http.MethodPut,
+			target:     "/hubs",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockHubRepository([]*domain.Hub{existing}, nil)
+			h := &handlers.HubHandler{Repo: repo}
+
+			r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			h.Route(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %q, got %s", tc.wantBody, w.Body.String())
+			}
+			if tc.wantField != "" {
+				assertValidationProblem(t, w, tc.wantField)
+			}
+			if tc.wantStored != nil {
+				got, ok := repo.Hubs[tc.wantStored.HubID]
+				if !ok || !reflect.DeepEqual(got, tc.wantStored) {
+					t.Fatalf("expected stored hub %+v, got %+v (found=%v)", *tc.wantStored, got, ok)
+				}
+			}
+			if tc.method == http.MethodDelete && tc.wantStatus == http.StatusNoContent {
+				if _, ok := repo.Hubs[1]; ok {
+					t.Fatalf("expected hub 1 to be deleted")
+				}
+			}
+		})
+	}
+}
@@ -2,14 +2,26 @@ package handlers
 
 import (
 	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/ports"
-	"log"
+	"delivery-route-service/internal/services"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
 )
 
-// PackageHandler exposes read-only package retrieval endpoints.
+// maxImportBytes bounds the size of a bulk import request body.
+const maxImportBytes = 32 << 20
+
+// PackageHandler exposes package retrieval and bulk import endpoints.
 type PackageHandler struct {
 	Repo ports.PackageRepository
+	// Target of bulk imports; Import is only routed when set.
+	Writer ports.PackageWriter
+	// Hubs imported hub_id values must name; unchecked when nil.
+	Hubs ports.HubRepository
 }
 
 func (h *PackageHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -21,8 +33,7 @@ func (h *PackageHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	pkgs, err := h.Repo.ListPackages(r.Context())
 	if err != nil {
-		log.Printf("list packages failed: %v", err)
-		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		writeServiceError(w, r, "list packages", err)
 		return
 	}
 
@@ -30,13 +41,124 @@ func (h *PackageHandler) List(w http.ResponseWriter, r *http.Request) {
 		Packages: make([]dto.PackageResponse, 0, len(pkgs)),
 	}
 	for _, p := range pkgs {
-		res.Packages = append(res.Packages, dto.PackageResponse{
-			PackageID:   p.PackageID,
-			Destination: p.Destination,
-			LoadedAt:    p.LoadedAt,
-			DeliveredAt: p.DeliveredAt,
-		})
+		item := dto.PackageResponse{
+			PackageID:    p.PackageID,
+			Destination:  p.Destination,
+			WeightKg:     p.WeightKg,
+			VolumeM3:     p.VolumeM3,
+			Pickup:       p.Pickup,
+			ServiceLevel: string(p.ServiceLevel),
+			Deadline:     p.Deadline,
+			LoadedAt:     p.LoadedAt,
+			DeliveredAt:  p.DeliveredAt,
+		}
+		if p.Coordinates != nil {
+			lat, lon := p.Coordinates.Lat, p.Coordinates.Lon
+			item.Lat, item.Lon = &lat, &lon
+		}
+		if item.ServiceLevel == "" {
+			item.ServiceLevel = string(domain.ServiceLevelStandard)
+		}
+		if p.HubID != 0 {
+			hub := p.HubID
+			item.HubID = &hub
+		}
+		res.Packages = append(res.Packages, item)
 	}
 
 	writeJSON(w, r, http.StatusOK, res)
 }
+
+// Import streams a CSV or JSON body into the package table.
+// Query parameters: format (csv|json, defaults from Content-Type), mode
+// (atomic|best_effort, default atomic), dry_run, and repeated map=field=column.
+// Atomic imports that reject any row respond 422 with the row errors.
+func (h *PackageHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+
+	format := services.ImportFormat(q.Get("format"))
+	if format == "" {
+		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mt {
+		case "text/csv":
+			format = services.ImportFormatCSV
+		case "application/json", "application/x-ndjson":
+			format = services.ImportFormatJSON
+		default:
+			writeError(w, r, http.StatusUnsupportedMediaType,
+				"Content-Type must be text/csv, application/json or application/x-ndjson, or set ?format=")
+			return
+		}
+	}
+
+	mode := services.ImportMode(q.Get("mode"))
+	if mode == "" {
+		mode = services.ImportModeAtomic
+	}
+
+	dryRun := false
+	if v := q.Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeValidationError(w, r, "dry_run", fmt.Sprintf("dry_run must be a boolean, got %q", v))
+			return
+		}
+		dryRun = b
+	}
+
+	mapping, err := services.ParseImportMapping(q["map"])
+	if err != nil {
+		writeServiceError(w, r, "import packages", err)
+		return
+	}
+
+	req := services.ImportPackagesRequest{Format: format, Mode: mode, Mapping: mapping, DryRun: dryRun}
+	if h.Hubs != nil {
+		if req.Hubs, err = h.Hubs.ListHubs(r.Context()); err != nil {
+			writeServiceError(w, r, "list hubs", err)
+			return
+		}
+	}
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	defer body.Close()
+
+	result, err := services.ImportPackages(r.Context(), body, req, h.Writer)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("import body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeServiceError(w, r, "import packages", err)
+		return
+	}
+
+	res := dto.ImportPackagesResponse{
+		Mode:     string(result.Mode),
+		DryRun:   result.DryRun,
+		Total:    result.Total,
+		Imported: result.Imported,
+		Errors:   make([]dto.ImportRowErrorResponse, 0, len(result.Errors)),
+	}
+	for _, e := range result.Errors {
+		res.Errors = append(res.Errors, dto.ImportRowErrorResponse{
+			Row:       e.Row,
+			PackageID: e.PackageID,
+			Field:     e.Field,
+			Reason:    e.Reason,
+		})
+	}
+
+	status := http.StatusOK
+	if result.Mode == services.ImportModeAtomic && len(result.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, r, status, res)
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPackageHandlerImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantWrites  int
+	}{
+		{
+			name:        "csv import with column mapping",
+			target:      "/packages/import?map=package_id=Order+No&map=destination=Ship+To",
+			contentType: "text/csv",
+			body:        "Order No,Ship To\n1,1 Main St\n2,2 Main St\n",
+			wantStatus:  http.StatusOK,
+			wantBody:    `"imported":2`,
+			wantWrites:  1,
+		},
+		{
+			name:        "atomic import with a bad row is 422 and writes nothing",
+			target:      "/packages/import",
+			contentType: "text/csv",
+			body:        "package_id,destination\n1,1 Main St\n-4,Bad St\n",
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantBody:    `"row":3`,
+		},
+		{
+			name:        "best-effort import writes the valid rows",
+			target:      "/packages/import?mode=best_effort",
+			contentType: "text/csv",
+			body:        "package_id,destination\n1,1 Main St\n-4,Bad St\n",
+			wantStatus:  http.StatusOK,
+			wantBody:    `"imported":1`,
+			wantWrites:  1,
+		},
+		{
+			name:        "unknown hub_id is a row error",
+			target:      "/packages/import?mode=best_effort",
+			contentType: "text/csv",
+			body:        "package_id,destination,hub_id\n1,1 Main St,1\n2,2 Main St,7\n",
+			wantStatus:  http.StatusOK,
+			wantBody:    `"row":3,"package_id":2,"field":"hub_id"`,
+			wantWrites:  1,
+		},
+		{
+			name:        "dry run reports without writing",
+			target:      "/packages/import?dry_run=true",
+			contentType: "application/json",
+			body:        `[{"package_id": 1, "destination": "1 Main St"}]`,
+			wantStatus:  http.StatusOK,
+			wantBody:    `"dry_run":true`,
+		},
+		{
+			name:        "unknown content type is 415",
+			target:      "/packages/import",
+			contentType: "text/plain",
+			body:        "package_id\n1\n",
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "bad mapping is 400",
+			target:      "/packages/import?map=weight=Kg",
+			contentType: "text/csv",
+			body:        "package_id,destination\n",
+			wantStatus:  http.StatusBadRequest,
+			wantBody:    `"field":"map"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockPackageRepository(nil, nil)
+			hubs := testutil.NewMockHubRepository([]*domain.Hub{{HubID: 1, Name: "Main", Address: "Hub"}}, nil)
+			h := &handlers.PackageHandler{Repo: repo, Writer: repo, Hubs: hubs}
+
+			r := httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			h.Import(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %q, got %s", tc.wantBody, w.Body.String())
+			}
+			if len(repo.Upserted) != tc.wantWrites {
+				t.Fatalf("expected %d writes, got %d", tc.wantWrites, len(repo.Upserted))
+			}
+		})
+	}
+}
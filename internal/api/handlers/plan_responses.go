@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/services"
+	"math"
+	"time"
+)
+
+// The mappers below turn planning results into their response shapes. They
+// are exported for cmd/plan, whose JSON output matches the API's.
+
+// NewPlanMetricsResponse maps the totals of a plan onto their response shape,
+// naming objective, or the default when it is empty.
+func NewPlanMetricsResponse(m services.PlanMetrics, objective services.Objective) dto.PlanMetricsResponse {
+	if objective == "" {
+		objective = services.Objectives[0]
+	}
+	res := dto.PlanMetricsResponse{
+		Objective:            string(objective),
+		TotalDistanceMeters:  m.TotalDistanceMeters,
+		TotalDurationSeconds: m.TotalDurationSeconds,
+		TotalCost:            math.Round(m.TotalCost*100) / 100,
+		MakespanSeconds:      m.MakespanSeconds,
+		RouteDurations: dto.RouteDurationsResponse{
+			MinSeconds:    m.MinRouteDurationSeconds,
+			MaxSeconds:    m.MaxRouteDurationSeconds,
+			StdDevSeconds: math.Round(m.RouteDurationStdDevSeconds*10) / 10,
+		},
+	}
+	if !m.FinishAt.IsZero() {
+		finish := m.FinishAt
+		res.FinishAt = &finish
+	}
+	return res
+}
+
+// NewUngeocodableResponses maps skipped destinations onto their response shape.
+func NewUngeocodableResponses(skipped []services.UngeocodableDestination) []dto.UngeocodableResponse {
+	res := make([]dto.UngeocodableResponse, 0, len(skipped))
+	for _, u := range skipped {
+		res = append(res, dto.UngeocodableResponse{
+			Address:    u.Address,
+			Reason:     u.Reason,
+			PackageIDs: u.PackageIDs,
+		})
+	}
+	return res
+}
+
+// NewUnassignedResponses maps destinations left off every truck onto their response shape.
+func NewUnassignedResponses(unassigned []services.UnassignedDestination) []dto.UnassignedResponse {
+	res := make([]dto.UnassignedResponse, 0, len(unassigned))
+	for _, u := range unassigned {
+		res = append(res, dto.UnassignedResponse{
+			Address:    u.Address,
+			Reason:     u.Reason,
+			PackageIDs: u.PackageIDs,
+		})
+	}
+	return res
+}
+
+// NewMissedPromiseResponses maps packages whose promise the plan misses onto
+// their response shape.
+func NewMissedPromiseResponses(missed []services.MissedPromise) []dto.MissedPromiseResponse {
+	res := make([]dto.MissedPromiseResponse, 0, len(missed))
+	for _, m := range missed {
+		r := dto.MissedPromiseResponse{
+			PackageID:    m.PackageID,
+			ServiceLevel: string(m.ServiceLevel),
+			Deadline:     m.Deadline,
+			TruckID:      m.TruckID,
+			Trip:         m.Trip,
+		}
+		if r.ServiceLevel == "" {
+			r.ServiceLevel = string(domain.ServiceLevelStandard)
+		}
+		if !m.ArriveAt.IsZero() {
+			arrive := m.ArriveAt
+			r.ArriveAt = &arrive
+			r.LateSeconds = int(m.ArriveAt.Sub(m.Deadline) / time.Second)
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// toDepotPlansResponse maps one hub's plans onto their response shape.
+func toDepotPlansResponse(d services.DepotPlans) dto.DepotPlansResponse {
+	res := dto.DepotPlansResponse{
+		HubID:   d.Hub.HubID,
+		Name:    d.Hub.Name,
+		Address: d.Hub.Address,
+		Plans:   make([]dto.PlanResponse, 0, len(d.Plans)),
+	}
+	for _, p := range d.Plans {
+		res.Plans = append(res.Plans, dto.NewPlanResponse(p))
+	}
+	return res
+}
@@ -2,20 +2,43 @@ package handlers
 
 import (
 	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
 	"delivery-route-service/internal/ports"
 	"delivery-route-service/internal/services"
-	"encoding/json"
-	"io"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Alternative plan representations selected via the Accept header.
+const (
+	mediaTypeGeoJSON = "application/geo+json"
+	mediaTypeGPX     = "application/gpx+xml"
+	mediaTypeKML     = "application/vnd.google-earth.kml+xml"
+	mediaTypeCSV     = "text/csv"
+)
+
+// CSV manifest row orders selected with ?order=.
+const (
+	manifestOrderDelivery = "delivery"
+	manifestOrderLoad     = "load"
+)
+
 type PlanHandler struct {
-	Repo       ports.PackageRepository
-	Provider   ports.DistanceProvider
+	Repo     ports.PackageRepository
+	Provider ports.DistanceProvider
+	// Optional; required to plan for fleet trucks with truck_ids.
+	Trucks ports.TruckRepository
+	// Optional; required for multi-depot planning.
+	Hubs       ports.HubRepository
 	DefaultHub string
+	// Request defaults, accepted ranges and fan-out limits.
+	Planning config.PlanningConfig
 }
 
 // Plan orchestrates package assignment and route planning for all trucks.
@@ -27,18 +50,30 @@ func (h *PlanHandler) Plan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req dto.PlanRequest
-
-	dec := json.NewDecoder(r.Body)
-	defer r.Body.Close()
-	dec.DisallowUnknownFields()
+	// Optional ?truck_id= narrows the response to one truck's route,
+	// e.g. to hand a single driver their GPX or KML file.
+	truckID := 0
+	if v := r.URL.Query().Get("truck_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			writeValidationError(w, r, "truck_id", "truck_id must be a positive integer")
+			return
+		}
+		truckID = id
+	}
 
-	if err := dec.Decode(&req); err != nil {
-		writeError(w, r, http.StatusBadRequest, "invalid json body")
+	// ?order= picks the CSV manifest variant: delivery order or reverse load order.
+	order := r.URL.Query().Get("order")
+	if order == "" {
+		order = manifestOrderDelivery
+	}
+	if order != manifestOrderDelivery && order != manifestOrderLoad {
+		writeValidationError(w, r, "order", fmt.Sprintf("order must be %q or %q", manifestOrderDelivery, manifestOrderLoad))
 		return
 	}
-	if err := dec.Decode(&struct{}{}); err != io.EOF {
-		writeError(w, r, http.StatusBadRequest, "body must contain only one JSON object")
+
+	var req dto.PlanRequest
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
@@ -46,27 +81,42 @@ func (h *PlanHandler) Plan(w http.ResponseWriter, r *http.Request) {
 	if hub == "" {
 		hub = strings.TrimSpace(h.DefaultHub)
 	}
-	if hub == "" {
-		writeError(w, r, http.StatusBadRequest, "hub is required")
+	// Multi-depot plans start from the stored hubs instead.
+	if hub == "" && !req.MultiDepot {
+		writeValidationError(w, r, "hub", "hub is required")
 		return
 	}
 
+	limits := h.Planning
+
 	truckCount := req.TruckCount
 	if truckCount == 0 {
-		truckCount = 3
+		truckCount = limits.DefaultTruckCount
 	}
-	if truckCount < 1 || truckCount > 10 {
-		writeError(w, r, http.StatusBadRequest, "truck_count must be between 1 and 10")
-		return
-	}
-
 	truckCap := req.TruckCapacity
 	if truckCap == 0 {
-		truckCap = 16
+		truckCap = limits.DefaultTruckCapacity
 	}
-	if truckCap < 1 || truckCap > 100 {
-		writeError(w, r, http.StatusBadRequest, "truck_capacity must be between 1 and 100")
-		return
+
+	var fleet []*domain.Truck
+	if len(req.TruckIDs) > 0 {
+		var ok bool
+		if fleet, ok = h.fleetTrucks(w, r, req.TruckIDs); !ok {
+			return
+		}
+	} else {
+		if truckCount < limits.MinTruckCount || truckCount > limits.MaxTruckCount {
+			writeValidationError(w, r, "truck_count", fmt.Sprintf(
+				"truck_count must be between %d and %d", limits.MinTruckCount, limits.MaxTruckCount,
+			))
+			return
+		}
+		if truckCap < limits.MinTruckCapacity || truckCap > limits.MaxTruckCapacity {
+			writeValidationError(w, r, "truck_capacity", fmt.Sprintf(
+				"truck_capacity must be between %d and %d", limits.MinTruckCapacity, limits.MaxTruckCapacity,
+			))
+			return
+		}
 	}
 
 	depart := time.Now()
@@ -75,39 +125,375 @@ func (h *PlanHandler) Plan(w http.ResponseWriter, r *http.Request) {
 	}
 
 	svcReq := services.PlanDeliveriesRequest{
-		Hub:           hub,
-		TruckCount:    truckCount,
-		TruckCapacity: truckCap,
-		DepartAt:      depart,
-		ReturnToStart: req.ReturnToStart,
+		Hub:              hub,
+		TruckCount:       truckCount,
+		TruckCapacity:    truckCap,
+		TruckMaxWeightKg: req.TruckMaxWeightKg,
+		TruckMaxVolumeM3: req.TruckMaxVolumeM3,
+		Trucks:           fleet,
+		DepartAt:         depart,
+		ReturnToStart:    req.ReturnToStart,
+		Concurrency:      limits.Concurrency,
+
+		IncludeGeometry: req.IncludeGeometry,
+		Strategy:        services.RouteStrategy(req.Strategy),
+		Objective:       services.Objective(req.Objective),
+		Balance:         balance(req.Balance, limits),
+		ServiceTime:     serviceTime(req.ServiceTime, limits),
+		Shift:           shift(req.Shift, limits),
+
+		MultiTrip:  req.MultiTrip,
+		ReloadTime: limits.ReloadTime,
+		Promises:   promises(req.Promises, limits),
+	}
+	if req.ReloadSeconds != nil {
+		svcReq.ReloadTime = time.Duration(*req.ReloadSeconds) * time.Second
+	}
+
+	if req.MultiDepot {
+		h.planMultiDepot(w, r, svcReq, truckID, order)
+		return
 	}
 
-	plans, err := services.PlanDeliveries(r.Context(), svcReq, h.Repo, h.Provider)
+	result, err := services.PlanDeliveries(r.Context(), svcReq, h.Repo, h.Provider)
 	if err != nil {
-		log.Printf("plan deliveries failed: %v", err)
-		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		writeServiceError(w, r, "plan deliveries", err)
 		return
 	}
 
-	res := dto.ListPlanResponse{Plans: make([]dto.PlanResponse, 0, len(plans))}
-	for _, p := range plans {
-		stops := make([]dto.PlanStopResponse, 0, len(p.Stops))
-		for _, s := range p.Stops {
-			stops = append(stops, dto.PlanStopResponse{
-				Destination: s.Destination,
-				ArriveAt:    s.ArriveAt,
-				PackageIDs:  s.PackageIDs,
-			})
-		}
+	plans := truckPlans(result.Plans, truckID)
+	if len(plans) == 0 && truckID != 0 {
+		writeError(w, r, http.StatusNotFound, fmt.Sprintf("truck %d has no planned route", truckID))
+		return
+	}
 
-		res.Plans = append(res.Plans, dto.PlanResponse{
-			TruckID:              p.TruckID,
-			DepartAt:             p.DepartAt,
-			TotalDistanceMeters:  p.TotalDistanceMeters,
-			TotalDurationSeconds: p.TotalDurationSeconds,
-			Stops:                stops,
+	ungeocodable := NewUngeocodableResponses(result.Ungeocodable)
+	unassigned := NewUnassignedResponses(result.Unassigned)
+	missed := NewMissedPromiseResponses(truckMissedPromises(result.MissedPromises, truckID))
+	if writePlanRepresentation(w, r, plans, truckID, order, ungeocodable, unassigned, missed) {
+		return
+	}
+	// Totals follow the truck_id filter, like the plans they add up.
+	metrics := NewPlanMetricsResponse(services.MetricsOf(plans, depart), svcReq.Objective)
+
+	if req.MultiTrip {
+		writeJSON(w, r, http.StatusOK, dto.ListTruckTripsResponse{
+			Trucks:         dto.NewTruckTripsResponses(plans),
+			Metrics:        metrics,
+			Ungeocodable:   ungeocodable,
+			Unassigned:     unassigned,
+			MissedPromises: missed,
 		})
+		return
+	}
+
+	res := dto.ListPlanResponse{
+		Plans:          make([]dto.PlanResponse, 0, len(plans)),
+		Metrics:        metrics,
+		Ungeocodable:   ungeocodable,
+		Unassigned:     unassigned,
+		MissedPromises: missed,
+	}
+	for _, p := range plans {
+		res.Plans = append(res.Plans, dto.NewPlanResponse(p))
+	}
+	writeJSON(w, r, http.StatusOK, res)
+}
+
+// planMultiDepot plans from every stored hub and responds with the plans
+// grouped by hub. File and GeoJSON representations list all hubs' plans together.
+func (h *PlanHandler) planMultiDepot(
+	w http.ResponseWriter,
+	r *http.Request,
+	svcReq services.PlanDeliveriesRequest,
+	truckID int,
+	order string,
+) {
+	if h.Hubs == nil {
+		writeValidationError(w, r, "multi_depot", "multi_depot is not supported without a hub repository")
+		return
+	}
+	hubs, err := h.Hubs.ListHubs(r.Context())
+	if err != nil {
+		writeServiceError(w, r, "list hubs", err)
+		return
+	}
+
+	result, err := services.PlanMultiDepot(r.Context(), svcReq, hubs, h.Repo, h.Provider)
+	if err != nil {
+		writeServiceError(w, r, "plan multi-depot", err)
+		return
+	}
+
+	var all []*domain.RoutePlan
+	for i := range result.Depots {
+		result.Depots[i].Plans = truckPlans(result.Depots[i].Plans, truckID)
+		all = append(all, result.Depots[i].Plans...)
+	}
+	if len(all) == 0 && truckID != 0 {
+		writeError(w, r, http.StatusNotFound, fmt.Sprintf("truck %d has no planned route", truckID))
+		return
+	}
+
+	ungeocodable := NewUngeocodableResponses(result.Ungeocodable)
+	unassigned := NewUnassignedResponses(result.Unassigned)
+	missed := NewMissedPromiseResponses(truckMissedPromises(result.MissedPromises, truckID))
+	if writePlanRepresentation(w, r, all, truckID, order, ungeocodable, unassigned, missed) {
+		return
 	}
 
+	res := dto.ListDepotPlansResponse{
+		Depots:         make([]dto.DepotPlansResponse, 0, len(result.Depots)),
+		Metrics:        NewPlanMetricsResponse(services.MetricsOf(all, svcReq.DepartAt), svcReq.Objective),
+		Ungeocodable:   ungeocodable,
+		Unassigned:     unassigned,
+		MissedPromises: missed,
+	}
+	for _, d := range result.Depots {
+		// A truck_id narrows the response to the hub running that truck.
+		if truckID != 0 && len(d.Plans) == 0 {
+			continue
+		}
+		res.Depots = append(res.Depots, toDepotPlansResponse(d))
+	}
 	writeJSON(w, r, http.StatusOK, res)
 }
+
+// truckPlans returns the plans of the truck with truckID, or all plans when it is zero.
+func truckPlans(plans []*domain.RoutePlan, truckID int) []*domain.RoutePlan {
+	if truckID == 0 {
+		return plans
+	}
+	var out []*domain.RoutePlan
+	for _, p := range plans {
+		if p.TruckID == truckID {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// truckMissedPromises returns the missed promises of the truck with truckID,
+// or all of them when it is zero. Packages left out of the plan belong to no
+// truck, so they are only listed for the whole plan.
+func truckMissedPromises(missed []services.MissedPromise, truckID int) []services.MissedPromise {
+	if truckID == 0 {
+		return missed
+	}
+	var out []services.MissedPromise
+	for _, m := range missed {
+		if m.TruckID == truckID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// writePlanRepresentation writes plans as GeoJSON, GPX, KML or a CSV manifest
+// when the client accepts one, and reports whether it did.
+func writePlanRepresentation(
+	w http.ResponseWriter,
+	r *http.Request,
+	plans []*domain.RoutePlan,
+	truckID int,
+	order string,
+	ungeocodable []dto.UngeocodableResponse,
+	unassigned []dto.UnassignedResponse,
+	missed []dto.MissedPromiseResponse,
+) bool {
+	switch {
+	case accepts(r, mediaTypeGeoJSON):
+		writePlansGeoJSON(w, r, plans, ungeocodable, unassigned, missed)
+	case accepts(r, mediaTypeGPX):
+		writePlansFile(w, r, plans, mediaTypeGPX, planFileName(truckID, "", "gpx"), export.PlansGPX)
+	case accepts(r, mediaTypeKML):
+		writePlansFile(w, r, plans, mediaTypeKML, planFileName(truckID, "", "kml"), export.PlansKML)
+	case accepts(r, mediaTypeCSV):
+		if order == manifestOrderLoad {
+			writePlansFile(w, r, plans, mediaTypeCSV, planFileName(truckID, "load-order", "csv"), export.LoadOrderCSV)
+		} else {
+			writePlansFile(w, r, plans, mediaTypeCSV, planFileName(truckID, "manifest", "csv"), export.ManifestCSV)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// fleetTrucks loads the requested fleet trucks in request order. Unknown,
+// repeated or unavailable trucks are a 400 naming the truck_ids field.
+func (h *PlanHandler) fleetTrucks(w http.ResponseWriter, r *http.Request, ids []int) ([]*domain.Truck, bool) {
+	invalid := func(detail string) ([]*domain.Truck, bool) {
+		writeValidationError(w, r, "truck_ids", detail)
+		return nil, false
+	}
+
+	if h.Trucks == nil {
+		return invalid("truck_ids is not supported without a truck repository")
+	}
+	if limit := h.Planning.MaxTruckCount; len(ids) > limit {
+		return invalid(fmt.Sprintf("truck_ids must list at most %d trucks", limit))
+	}
+
+	byID, err := h.Trucks.GetTrucks(r.Context(), ids)
+	if err != nil {
+		writeServiceError(w, r, "get trucks", err)
+		return nil, false
+	}
+
+	trucks := make([]*domain.Truck, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		t, ok := byID[id]
+		switch {
+		case seen[id]:
+			return invalid(fmt.Sprintf("truck %d is listed more than once", id))
+		case !ok:
+			return invalid(fmt.Sprintf("truck %d does not exist", id))
+		case !t.Available:
+			return invalid(fmt.Sprintf("truck %d is not available", id))
+		}
+		seen[id] = true
+		trucks = append(trucks, t)
+	}
+	return trucks, true
+}
+
+// serviceTime overlays the request's service time on the configured defaults.
+// Negative values are passed through for the planner to reject.
+func serviceTime(req *dto.ServiceTimeRequest, limits config.PlanningConfig) domain.ServiceTime {
+	st := domain.ServiceTime{
+		PerStop:    limits.ServiceTimePerStop,
+		PerPackage: limits.ServiceTimePerPackage,
+	}
+	if req == nil {
+		return st
+	}
+	if req.PerStopSeconds != nil {
+		st.PerStop = time.Duration(*req.PerStopSeconds) * time.Second
+	}
+	if req.PerPackageSeconds != nil {
+		st.PerPackage = time.Duration(*req.PerPackageSeconds) * time.Second
+	}
+	if len(req.Destinations) > 0 {
+		st.Destinations = make(map[string]time.Duration, len(req.Destinations))
+		for d, secs := range req.Destinations {
+			st.Destinations[d] = time.Duration(secs) * time.Second
+		}
+	}
+	return st
+}
+
+// shift overlays the request's shift limits on the configured defaults.
+// Negative values are passed through for the planner to reject.
+func shift(req *dto.ShiftRequest, limits config.PlanningConfig) domain.Shift {
+	s := domain.Shift{
+		MaxDuration:   limits.MaxShift,
+		BreakAfter:    limits.BreakAfter,
+		BreakDuration: limits.BreakDuration,
+	}
+	if req == nil {
+		return s
+	}
+	if req.MaxDurationSeconds != nil {
+		s.MaxDuration = time.Duration(*req.MaxDurationSeconds) * time.Second
+	}
+	if req.BreakAfterSeconds != nil {
+		s.BreakAfter = time.Duration(*req.BreakAfterSeconds) * time.Second
+	}
+	if req.BreakDurationSeconds != nil {
+		s.BreakDuration = time.Duration(*req.BreakDurationSeconds) * time.Second
+	}
+	return s
+}
+
+// balance reads the request's balancing mode, defaulting the tolerance to the
+// configured one. Invalid values are passed through for the planner to reject.
+func balance(req *dto.BalanceRequest, limits config.PlanningConfig) services.Balance {
+	if req == nil {
+		return services.Balance{}
+	}
+	b := services.Balance{Mode: services.BalanceMode(req.Mode), Tolerance: limits.BalanceTolerance}
+	if req.Tolerance != nil {
+		b.Tolerance = *req.Tolerance
+	}
+	return b
+}
+
+// promises overlays the request's promise windows on the configured defaults.
+// Negative values are passed through for the planner to reject.
+func promises(req *dto.PromisesRequest, limits config.PlanningConfig) domain.Promises {
+	p := domain.Promises{SameDay: limits.SameDayWindow, Express: limits.ExpressWindow}
+	if req == nil {
+		return p
+	}
+	if req.SameDaySeconds != nil {
+		p.SameDay = time.Duration(*req.SameDaySeconds) * time.Second
+	}
+	if req.ExpressSeconds != nil {
+		p.Express = time.Duration(*req.ExpressSeconds) * time.Second
+	}
+	return p
+}
+
+// writePlansGeoJSON renders plans as a GeoJSON FeatureCollection. Skipped
+// and unassigned destinations and missed promises are kept as
+// "ungeocodable", "unassigned" and "missed_promises" foreign members.
+func writePlansGeoJSON(
+	w http.ResponseWriter,
+	r *http.Request,
+	plans []*domain.RoutePlan,
+	ungeocodable []dto.UngeocodableResponse,
+	unassigned []dto.UnassignedResponse,
+	missed []dto.MissedPromiseResponse,
+) {
+	fc, err := export.PlansGeoJSON(plans)
+	if err != nil {
+		writeServiceError(w, r, "render geojson", err)
+		return
+	}
+
+	res := struct {
+		*export.FeatureCollection
+		Ungeocodable   []dto.UngeocodableResponse  `json:"ungeocodable"`
+		Unassigned     []dto.UnassignedResponse    `json:"unassigned"`
+		MissedPromises []dto.MissedPromiseResponse `json:"missed_promises"`
+	}{fc, ungeocodable, unassigned, missed}
+	writeJSONAs(w, r, http.StatusOK, mediaTypeGeoJSON, res)
+}
+
+// planFileName names a download after the selected truck, or the whole plan
+// when truckID is zero, e.g. "plan-truck-2-manifest.csv".
+func planFileName(truckID int, variant string, ext string) string {
+	name := "plan"
+	if truckID != 0 {
+		name += fmt.Sprintf("-truck-%d", truckID)
+	}
+	if variant != "" {
+		name += "-" + variant
+	}
+	return name + "." + ext
+}
+
+// writePlansFile renders plans with render and serves them as a file download.
+func writePlansFile(
+	w http.ResponseWriter,
+	r *http.Request,
+	plans []*domain.RoutePlan,
+	contentType string,
+	name string,
+	render func([]*domain.RoutePlan) ([]byte, error),
+) {
+	body, err := render(plans)
+	if err != nil {
+		writeServiceError(w, r, "render "+name, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("write failed: method=%s path=%s err=%v", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,305 @@
+package handlers_test
+
+import (
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/config"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/testutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlanHandlerRepresentations(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+	}
+
+	tests := []struct {
+		name            string
+		target          string
+		body            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantDisposition string
+		wantField       string
+		noDefaultHub    bool
+	}{
+		{
+			name:            "json by default",
+			accept:          "",
+			wantContentType: "application/json",
+			wantBody:        `"plans":`,
+		},
+		{
+			name:            "wildcard keeps json",
+			accept:          "*/*",
+			wantContentType: "application/json",
+			wantBody:        `"plans":`,
+		},
+		{
+			name:            "geojson when requested",
+			accept:          "application/geo+json, application/json;q=0.5",
+			wantContentType: "application/geo+json",
+			wantBody:        `"features":`,
+		},
+		{
+			name:            "gpx for a single truck",
+			target:          "/plans?truck_id=1",
+			accept:          "application/gpx+xml",
+			wantContentType: "application/gpx+xml",
+			wantBody:        "<rte>",
+			wantDisposition: `attachment; filename="plan-truck-1.gpx"`,
+		},
+		{
+			name:            "kml for all trucks",
+			accept:          "application/vnd.google-earth.kml+xml",
+			wantContentType: "application/vnd.google-earth.kml+xml",
+			wantBody:        "<Folder>",
+			wantDisposition: `attachment; filename="plan.kml"`,
+		},
+		{
+			name:            "csv manifest in load order",
+			target:          "/plans?order=load&truck_id=1",
+			accept:          "text/csv",
+			wantContentType: "text/csv",
+			wantBody:        "truck_id,trip,stop_sequence,destination,package_id,eta,cumulative_distance_meters",
+			wantDisposition: `attachment; filename="plan-truck-1-load-order.csv"`,
+		},
+		{
+			name:            "unknown manifest order is 400",
+			target:          "/plans?order=random",
+			accept:          "text/csv",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "order",
+		},
+		{
+			name:            "truck without a route is 404",
+			target:          "/plans?truck_id=9",
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/problem+json",
+		},
+		{
+			name:            "service time delays departure",
+			body:            `{"truck_count":1,"service_time":{"per_stop_seconds":120,"per_package_seconds":30}}`,
+			wantContentType: "application/json",
+			wantBody:        `"service_seconds":150`,
+		},
+		{
+			name:            "negative service time is 400",
+			body:            `{"truck_count":1,"service_time":{"destinations":{"DestA":-60}}}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "service_time",
+		},
+		{
+			name:            "break rule without break duration is 400",
+			body:            `{"truck_count":1,"shift":{"break_after_seconds":3600}}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "shift",
+		},
+		{
+			name:            "negative promise window is 400",
+			body:            `{"truck_count":1,"promises":{"express_seconds":-60}}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "promises",
+		},
+		{
+			name:            "unknown objective is 400",
+			body:            `{"truck_count":1,"objective":"fastest"}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "objective",
+		},
+		{
+			name:            "metrics name the objective",
+			body:            `{"truck_count":1,"objective":"distance"}`,
+			wantContentType: "application/json",
+			wantBody:        `"metrics":{"objective":"distance","total_distance_meters":1000,`,
+		},
+		{
+			name:            "unknown balance mode is 400",
+			body:            `{"truck_count":1,"balance":{"mode":"stops"}}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "balance",
+		},
+		{
+			name:            "route duration spread",
+			body:            `{"truck_count":1,"balance":{"mode":"duration"}}`,
+			wantContentType: "application/json",
+			wantBody:        `"route_durations":{"min_seconds":60,"max_seconds":60,"stddev_seconds":0}`,
+		},
+		{
+			name:            "no missed promises without priority packages",
+			body:            `{"truck_count":1,"promises":{"same_day_seconds":60}}`,
+			wantContentType: "application/json",
+			wantBody:        `"missed_promises":[]`,
+		},
+		{
+			name:            "utilization per dimension",
+			body:            `{"truck_count":1,"truck_capacity":4,"truck_max_weight_kg":100}`,
+			wantContentType: "application/json",
+			wantBody: `"utilization":{"packages":{"used":1,"limit":4,"ratio":0.25},` +
+				`"weight_kg":{"used":40,"limit":100,"ratio":0.4},"volume_m3":{"used":0,"limit":null,"ratio":null}}`,
+		},
+		{
+			name:            "package over the weight limit is 422",
+			body:            `{"truck_count":1,"truck_max_weight_kg":25}`,
+			wantStatus:      http.StatusUnprocessableEntity,
+			wantContentType: "application/problem+json",
+			wantBody:        `weight_kg limit of 25`,
+		},
+		{
+			name:            "fleet trucks by id",
+			body:            `{"truck_ids":[5]}`,
+			wantContentType: "application/json",
+			wantBody:        `"truck_name":"Van 5","start_location":"Hub"`,
+		},
+		{
+			name:            "unknown fleet truck is 400",
+			body:            `{"truck_ids":[5,8]}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "truck_ids",
+		},
+		{
+			name:            "unavailable fleet truck is 400",
+			body:            `{"truck_ids":[6]}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantBody:        `truck 6 is not available`,
+		},
+		{
+			name:            "multi_depot groups plans by hub",
+			body:            `{"truck_count":1,"multi_depot":true}`,
+			wantContentType: "application/json",
+			wantBody:        `"depots":[{"hub_id":1,"name":"Main","address":"Hub","plans":`,
+		},
+		{
+			name:            "multi_trip nests trips under each truck",
+			body:            `{"truck_count":1,"multi_trip":true,"reload_seconds":600}`,
+			wantContentType: "application/json",
+			wantBody:        `"trucks":[{"truck_id":1,"start_location":"Hub",`,
+		},
+		{
+			name:            "multi_trip with multi_depot is 400",
+			body:            `{"truck_count":1,"multi_trip":true,"multi_depot":true}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "multi_trip",
+		},
+		{
+			name:            "missing hub is 400",
+			body:            `{"truck_count":1,"hub":" "}`,
+			noDefaultHub:    true,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "hub",
+		},
+		{
+			name:            "truck_count out of range is 400",
+			body:            `{"truck_count":-1}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "truck_count",
+		},
+		{
+			name:            "truck_capacity out of range is 400",
+			body:            `{"truck_count":1,"truck_capacity":-1}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "truck_capacity",
+		},
+		{
+			name:            "invalid truck_id is 400",
+			target:          "/plans?truck_id=abc",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantField:       "truck_id",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testutil.NewMockDistanceProvider(pairs)
+			provider.Coordinates = map[string]domain.Coordinates{
+				hub:     {Lon: -112.0, Lat: 33.4},
+				"DestA": {Lon: -112.1, Lat: 33.5},
+			}
+			repo := testutil.NewMockPackageRepository([]*domain.Package{{PackageID: 1, Destination: "DestA", WeightKg: 40}}, nil)
+			trucks := testutil.NewMockTruckRepository([]*domain.Truck{
+				{TruckID: 5, Name: "Van 5", Capacity: 2, Available: true},
+				{TruckID: 6, Name: "Van 6", Capacity: 2},
+			}, nil)
+			hubs := testutil.NewMockHubRepository([]*domain.Hub{{HubID: 1, Name: "Main", Address: hub}}, nil)
+			h := &handlers.PlanHandler{
+				Repo: repo, Provider: provider, Trucks: trucks, Hubs: hubs,
+				DefaultHub: hub, Planning: config.Default().Planning,
+			}
+			if tc.noDefaultHub {
+				h.DefaultHub = ""
+			}
+
+			target := tc.target
+			if target == "" {
+				target = "/plans"
+			}
+			wantStatus := tc.wantStatus
+			if wantStatus == 0 {
+				wantStatus = http.StatusOK
+			}
+
+			body := tc.body
+			if body == "" {
+				body = `{"truck_count":1}`
+			}
+			r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			w := httptest.NewRecorder()
+			h.Plan(w, r)
+
+			if w.Code != wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", wantStatus, w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tc.wantContentType {
+				t.Fatalf("expected content type %q, got %q", tc.wantContentType, ct)
+			}
+			if !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %q, got %s", tc.wantBody, w.Body.String())
+			}
+			if cd := w.Header().Get("Content-Disposition"); cd != tc.wantDisposition {
+				t.Fatalf("expected content disposition %q, got %q", tc.wantDisposition, cd)
+			}
+			if tc.wantField != "" {
+				assertValidationProblem(t, w, tc.wantField)
+			}
+		})
+	}
+}
+
+// assertValidationProblem checks that w holds a validation problem about field.
+func assertValidationProblem(t *testing.T, w *httptest.ResponseRecorder, field string) {
+	t.Helper()
+	var p struct {
+		Type  string `json:"type"`
+		Field string `json:"field"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode problem: %v: %s", err, w.Body.String())
+	}
+	if p.Type != "/problems/validation" || p.Field != field {
+		t.Fatalf("expected validation problem on %q, got type %q field %q", field, p.Type, p.Field)
+	}
+}
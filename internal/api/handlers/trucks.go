@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"delivery-route-service/internal/api/dto"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TruckHandler manages the trucks of the fleet.
+type TruckHandler struct {
+	Repo ports.TruckRepository
+}
+
+// Route dispatches /trucks by method.
+func (h *TruckHandler) Route(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.List(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *TruckHandler) List(w http.ResponseWriter, r *http.Request) {
+	trucks, err := h.Repo.ListTrucks(r.Context())
+	if err != nil {
+		writeServiceError(w, r, "list trucks", err)
+		return
+	}
+
+	res := dto.ListTrucksResponse{
+		Trucks: make([]dto.TruckResponse, 0, len(trucks)),
+	}
+	for _, t := range trucks {
+		res.Trucks = append(res.Trucks, toTruckResponse(t))
+	}
+
+	writeJSON(w, r, http.StatusOK, res)
+}
+
+// Create stores or replaces the truck with the given ID.
+func (h *TruckHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.TruckRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	truck := &domain.Truck{
+		TruckID:       req.TruckID,
+		Name:          strings.TrimSpace(req.Name),
+		Capacity:      req.Capacity,
+		MaxWeightKg:   req.MaxWeightKg,
+		MaxVolumeM3:   req.MaxVolumeM3,
+		StartLocation: normalizeAddress(req.HomeHub),
+		VehicleType:   strings.TrimSpace(req.VehicleType),
+		CostPerKm:     req.CostPerKm,
+		CostPerHour:   req.CostPerHour,
+		Available:     req.Available == nil || *req.Available,
+	}
+	if field, reason := invalidTruck(truck); field != "" {
+		writeValidationError(w, r, field, reason)
+		return
+	}
+
+	if err := h.Repo.PutTruck(r.Context(), truck); err != nil {
+		writeServiceError(w, r, "put truck", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, toTruckResponse(truck))
+}
+
+// Delete removes the truck named by the truck_id query parameter.
+func (h *TruckHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.URL.Query().Get("truck_id"))
+	if err != nil || id < 1 {
+		writeValidationError(w, r, "truck_id", "truck_id query parameter must be a positive integer")
+		return
+	}
+
+	found, err := h.Repo.DeleteTruck(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, "delete truck", err)
+		return
+	}
+	if !found {
+		writeError(w, r, http.StatusNotFound, "no truck with that truck_id")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// invalidTruck returns the first invalid field of t and why, or "" when t is valid.
+func invalidTruck(t *domain.Truck) (field string, reason string) {
+	switch {
+	case t.TruckID < 1:
+		return "truck_id", "truck_id must be a positive integer"
+	case t.Name == "":
+		return "name", "name is required"
+	case t.Capacity < 1:
+		return "capacity", "capacity must be a positive integer"
+	case t.MaxWeightKg < 0:
+		return "max_weight_kg", "max_weight_kg must not be negative"
+	case t.MaxVolumeM3 < 0:
+		return "max_volume_m3", "max_volume_m3 must not be negative"
+	case t.CostPerKm < 0:
+		return "cost_per_km", "cost_per_km must not be negative"
+	case t.CostPerHour < 0:
+		return "cost_per_hour", "cost_per_hour must not be negative"
+	}
+	return "", ""
+}
+
+func toTruckResponse(t *domain.Truck) dto.TruckResponse {
+	return dto.TruckResponse{
+		TruckID:     t.TruckID,
+		Name:        t.Name,
+		Capacity:    t.Capacity,
+		MaxWeightKg: t.MaxWeightKg,
+		MaxVolumeM3: t.MaxVolumeM3,
+		HomeHub:     t.StartLocation,
+		VehicleType: t.VehicleType,
+		CostPerKm:   t.CostPerKm,
+		CostPerHour: t.CostPerHour,
+		Available:   t.Available,
+	}
+}
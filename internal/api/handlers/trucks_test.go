@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/testutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTruckHandler(t *testing.T) {
+	existing := &domain.Truck{TruckID: 1, Name: "Van 1", Capacity: 16, Available: true}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantField  string
+		wantStored *domain.Truck
+	}{
+		{
+			name:       "list returns stored trucks",
+			method:     http.MethodGet,
+			target:     "/trucks",
+			wantStatus: http.StatusOK,
+			wantBody:   `"name":"Van 1"`,
+		},
+		{
+			name:       "create defaults to available and normalizes the home hub",
+			method:     http.MethodPost,
+			target:     "/trucks",
+			body:       `{"truck_id":2,"name":" Box truck ","capacity":40,"max_weight_kg":3500,"max_volume_m3":18.5,"home_hub":" 9  Dock Rd ","vehicle_type":"box","cost_per_km":0.6,"cost_per_hour":28}`,
+			wantStatus: http.StatusCreated,
+			wantStored: &domain.Truck{
+				TruckID: 2, Name: "Box truck", Capacity: 40, MaxWeightKg: 3500, MaxVolumeM3: 18.5, StartLocation: "9 Dock Rd",
+				VehicleType: "box", CostPerKm: 0.6, CostPerHour: 28, Available: true,
+			},
+		},
+		{
+			name:       "create keeps a truck out of service",
+			method:     http.MethodPost,
+			target:     "/trucks",
+			body:       `{"truck_id":1,"name":"Van 1","capacity":16,"available":false}`,
+			wantStatus: http.StatusCreated,
+			wantStored: &domain.Truck{TruckID: 1, Name: "Van 1", Capacity: 16},
+		},
+		{
+			name:       "create rejects non-positive capacity",
+			method:     http.MethodPost,
+			target:     "/trucks",
+			body:       `{"truck_id":2,"name":"Box truck","capacity":0}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "capacity",
+		},
+		{
+			name:       "create rejects negative cost",
+			method:     http.MethodPost,
+			target:     "/trucks",
+			body:       `{"truck_id":2,"name":"Box truck","capacity":4,"cost_per_km":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "cost_per_km",
+		},
+		{
+			name:       "create rejects negative weight limit",
+			method:     http.MethodPost,
+			target:     "/trucks",
+			body:       `{"truck_id":2,"name":"Box truck","capacity":4,"max_weight_kg":-5}`,
+			wantStatus: http.StatusBadRequest,
+			wantField:  "max_weight_kg",
+		},
+		{
+			name:       "delete removes existing truck",
+			method:     http.MethodDelete,
+			target:     "/trucks?truck_id=1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "delete unknown truck is 404",
+			method:     http.MethodDelete,
+			target:     "/trucks?truck_id=9",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "delete without truck_id is 400",
+			method:     http.MethodDelete,
+			target:     "/trucks",
+			wantStatus: http.StatusBadRequest,
+			wantField:  "truck_id",
+		},
+		{
+			name:       "unsupported method is 405",
+			method:     http.MethodPut,
+			target:     "/trucks",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockTruckRepository([]*domain.Truck{existing}, nil)
+			h := &handlers.TruckHandler{Repo: repo}
+
+			r := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			h.Route(w, r)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Fatalf("expected body to contain %q, got %s", tc.wantBody, w.Body.String())
+			}
+			if tc.wantField != "" {
+				assertValidationProblem(t, w, tc.wantField)
+			}
+			if tc.wantStored != nil {
+				got, ok := repo.Trucks[tc.wantStored.TruckID]
+				if !ok || !reflect.DeepEqual(got, tc.wantStored) {
+					t.Fatalf("expected stored truck %+v, got %+v (found=%v)", *tc.wantStored, got, ok)
+				}
+			}
+			if tc.method == http.MethodDelete && tc.wantStatus == http.StatusNoContent {
+				if _, ok := repo.Trucks[1]; ok {
+					t.Fatalf("expected truck 1 to be deleted")
+				}
+			}
+		})
+	}
+}
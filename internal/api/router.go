@@ -2,25 +2,67 @@ package api
 
 import (
 	"delivery-route-service/internal/api/handlers"
+	"delivery-route-service/internal/config"
 	"delivery-route-service/internal/ports"
 	"net/http"
 )
 
+// Dependencies groups the ports and settings the HTTP layer is built from.
+// Optional ports may be nil; their endpoints are then not registered.
+type Dependencies struct {
+	Packages  ports.PackageRepository
+	Provider  ports.DistanceProvider
+	Overrides ports.GeocodeOverrideRepository
+	Trucks    ports.TruckRepository
+	Hubs      ports.HubRepository
+	// Dropped entries for an address when its override changes (optional).
+	DistanceInvalidator ports.DistanceCacheInvalidator
+	DefaultHub          string
+	Planning            config.PlanningConfig
+}
+
 // NewRouter wires HTTP handlers with their dependencies and returns an http.Handler.
 // This is the API composition root (handlers stay unaware of concrete adapters).
-func NewRouter(repo ports.PackageRepository, provider ports.DistanceProvider, hub string) http.Handler {
+func NewRouter(deps Dependencies) http.Handler {
 	mux := http.NewServeMux()
 
-	pkgHandler := &handlers.PackageHandler{Repo: repo}
+	pkgHandler := &handlers.PackageHandler{Repo: deps.Packages, Hubs: deps.Hubs}
+	if w, ok := deps.Packages.(ports.PackageWriter); ok {
+		pkgHandler.Writer = w
+	}
 	planHandler := &handlers.PlanHandler{
-		Repo:       repo,
-		Provider:   provider,
-		DefaultHub: hub,
+		Repo:       deps.Packages,
+		Provider:   deps.Provider,
+		Trucks:     deps.Trucks,
+		Hubs:       deps.Hubs,
+		DefaultHub: deps.DefaultHub,
+		Planning:   deps.Planning,
 	}
 
 	mux.HandleFunc("/health", handlers.Health)
 	mux.HandleFunc("/packages", pkgHandler.List)
+	if pkgHandler.Writer != nil {
+		mux.HandleFunc("/packages/import", pkgHandler.Import)
+	}
 	mux.HandleFunc("/plans", planHandler.Plan)
 
+	if deps.Overrides != nil {
+		overrideHandler := &handlers.GeocodeOverrideHandler{
+			Repo:        deps.Overrides,
+			Invalidator: deps.DistanceInvalidator,
+		}
+		mux.HandleFunc("/geocode-overrides", overrideHandler.Route)
+	}
+
+	if deps.Trucks != nil {
+		truckHandler := &handlers.TruckHandler{Repo: deps.Trucks}
+		mux.HandleFunc("/trucks", truckHandler.Route)
+	}
+
+	if deps.Hubs != nil {
+		hubHandler := &handlers.HubHandler{Repo: deps.Hubs}
+		mux.HandleFunc("/hubs", hubHandler.Route)
+	}
+
 	return loggingMiddleware(mux)
 }
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the typed application configuration.
+//
+// Values are resolved in order: built-in defaults, then the optional YAML
+// file named by CONFIG_FILE, then environment variables. The result is
+// validated once at startup so misconfiguration fails fast with a clear message.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Planning PlanningConfig `yaml:"planning"`
+	ORS      ORSConfig      `yaml:"ors"`
+	Cache    CacheConfig    `yaml:"cache"`
+	Database DatabaseConfig `yaml:"database"`
+	Redis    RedisConfig    `yaml:"redis"`
+}
+
+// ServerConfig holds HTTP listener settings.
+type ServerConfig struct {
+	Port              string        `yaml:"port"`
+	HubAddress        string        `yaml:"hub_address"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+}
+
+// PlanningConfig holds plan request defaults, accepted ranges and fan-out limits.
+type PlanningConfig struct {
+	DefaultTruckCount    int `yaml:"default_truck_count"`
+	MinTruckCount        int `yaml:"min_truck_count"`
+	MaxTruckCount        int `yaml:"max_truck_count"`
+	DefaultTruckCapacity int `yaml:"default_truck_capacity"`
+	MinTruckCapacity     int `yaml:"min_truck_capacity"`
+	MaxTruckCapacity     int `yaml:"max_truck_capacity"`
+	// Maximum number of concurrent distance lookups during pairwise fetching.
+	Concurrency int `yaml:"concurrency"`
+	// Default service time per stop and per package when a request gives none.
+	ServiceTimePerStop    time.Duration `yaml:"service_time_per_stop"`
+	ServiceTimePerPackage time.Duration `yaml:"service_time_per_package"`
+	// Default driver shift limits; zero disables a rule.
+	MaxShift      time.Duration `yaml:"max_shift"`
+	BreakAfter    time.Duration `yaml:"break_after"`
+	BreakDuration time.Duration `yaml:"break_duration"`
+	// Default time to reload a truck at its hub between multi-trip trips.
+	ReloadTime time.Duration `yaml:"reload_time"`
+	// Default promise of same-day and express packages without their own
+	// deadline, counted from departure; zero sets none.
+	SameDayWindow time.Duration `yaml:"same_day_window"`
+	ExpressWindow time.Duration `yaml:"express_window"`
+	// Default gap allowed between the busiest and idlest truck when a request
+	// balances workloads, as a fraction of the average workload.
+	BalanceTolerance float64 `yaml:"balance_tolerance"`
+}
+
+// ORSConfig holds OpenRouteService client settings.
+type ORSConfig struct {
+	APIKey         string        `yaml:"api_key"`
+	BaseURL        string        `yaml:"base_url"`
+	Profile        string        `yaml:"profile"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	// Maximum number of concurrent geocode requests.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// CacheConfig holds Redis cache entry settings.
+type CacheConfig struct {
+	TTL time.Duration `yaml:"ttl"`
+	// Lifetime of remembered geocode failures.
+	NegativeTTL time.Duration `yaml:"negative_ttl"`
+}
+
+type DatabaseConfig struct {
+	URL      string `yaml:"url"`
+	SeedPath string `yaml:"seed_path"`
+	// Apply pending schema migrations when a binary starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type RedisConfig struct {
+	URL string `yaml:"url"`
+}
+
+// Default returns the configuration used when neither a file nor env overrides a value.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              "8080",
+			HubAddress:        "1901 W Madison St, Phoenix, AZ 85009",
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      120 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		},
+		Planning: PlanningConfig{
+			DefaultTruckCount:    3,
+			MinTruckCount:        1,
+			MaxTruckCount:        10,
+			DefaultTruckCapacity: 16,
+			MinTruckCapacity:     1,
+			MaxTruckCapacity:     100,
+			Concurrency:          5,
+			SameDayWindow:        10 * time.Hour,
+			ExpressWindow:        4 * time.Hour,
+			BalanceTolerance:     0.1,
+		},
+		ORS: ORSConfig{
+			BaseURL:        "https://api.openrouteservice.org",
+			Profile:        "driving-car",
+			Timeout:        10 * time.Second,
+			MaxAttempts:    4,
+			InitialBackoff: 200 * time.Millisecond,
+			Concurrency:    5,
+		},
+		Cache: CacheConfig{
+			TTL:         24 * time.Hour,
+			NegativeTTL: 6 * time.Hour,
+		},
+		Database: DatabaseConfig{
+			SeedPath: "data/seeds/packages.json",
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the optional CONFIG_FILE and
+// environment variables, then validates it.
+func Load() (Config, error) {
+	cfg := Default()
+
+	if path := strings.TrimSpace(os.Getenv("CONFIG_FILE")); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile overlays values from a YAML file onto cfg.
+// Keys absent from the file keep their current value.
+func (c *Config) loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("config: unsupported config file extension %q (expected .yaml or .yml)", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: open %q: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config: parse %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv overlays environment variables onto cfg.
+// Unset or empty variables leave the current value untouched.
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	str := func(key string, dst *string) {
+		if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+			*dst = v
+		}
+	}
+	num := func(key string, dst *int) {
+		v := strings.TrimSpace(os.Getenv(key))
+		if v == "" {
+			return
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid integer %q", key, v))
+			return
+		}
+		*dst = n
+	}
+	fraction := func(key string, dst *float64) {
+		v := strings.TrimSpace(os.Getenv(key))
+		if v == "" {
+			return
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid number %q", key, v))
+			return
+		}
+		*dst = f
+	}
+	dur := func(key string, dst *time.Duration) {
+		v := strings.TrimSpace(os.Getenv(key))
+		if v == "" {
+			return
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid duration %q", key, v))
+			return
+		}
+		*dst = d
+	}
+	boolean := func(key string, dst *bool) {
+		v := strings.TrimSpace(os.Getenv(key))
+		if v == "" {
+			return
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid boolean %q", key, v))
+			return
+		}
+		*dst = b
+	}
+
+	str("PORT", &c.Server.Port)
+	str("HUB_ADDRESS", &c.Server.HubAddress)
+	dur("SERVER_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout)
+	dur("SERVER_READ_TIMEOUT", &c.Server.ReadTimeout)
+	dur("SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout)
+	dur("SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout)
+
+	num("PLAN_DEFAULT_TRUCK_COUNT", &c.Planning.DefaultTruckCount)
+	num("PLAN_MIN_TRUCK_COUNT", &c.Planning.MinTruckCount)
+	num("PLAN_MAX_TRUCK_COUNT", &c.Planning.MaxTruckCount)
+	num("PLAN_DEFAULT_TRUCK_CAPACITY", &c.Planning.DefaultTruckCapacity)
+	num("PLAN_MIN_TRUCK_CAPACITY", &c.Planning.MinTruckCapacity)
+	num("PLAN_MAX_TRUCK_CAPACITY", &c.Planning.MaxTruckCapacity)
+	num("PLAN_CONCURRENCY", &c.Planning.Concurrency)
+	dur("PLAN_SERVICE_TIME_PER_STOP", &c.Planning.ServiceTimePerStop)
+	dur("PLAN_SERVICE_TIME_PER_PACKAGE", &c.Planning.ServiceTimePerPackage)
+	dur("PLAN_MAX_SHIFT", &c.Planning.MaxShift)
+	dur("PLAN_BREAK_AFTER", &c.Planning.BreakAfter)
+	dur("PLAN_BREAK_DURATION", &c.Planning.BreakDuration)
+	dur("PLAN_RELOAD_TIME", &c.Planning.ReloadTime)
+	dur("PLAN_SAME_DAY_WINDOW", &c.Planning.SameDayWindow)
+	dur("PLAN_EXPRESS_WINDOW", &c.Planning.ExpressWindow)
+	fraction("PLAN_BALANCE_TOLERANCE", &c.Planning.BalanceTolerance)
+
+	str("ORS_API_KEY", &c.ORS.APIKey)
+	str("ORS_BASE_URL", &c.ORS.BaseURL)
+	str("ORS_PROFILE", &c.ORS.Profile)
+	dur("ORS_TIMEOUT", &c.ORS.Timeout)
+	num("ORS_MAX_ATTEMPTS", &c.ORS.MaxAttempts)
+	dur("ORS_INITIAL_BACKOFF", &c.ORS.InitialBackoff)
+	num("ORS_CONCURRENCY", &c.ORS.Concurrency)
+
+	dur("CACHE_TTL", &c.Cache.TTL)
+	dur("CACHE_NEGATIVE_TTL", &c.Cache.NegativeTTL)
+
+	str("DATABASE_URL", &c.Database.URL)
+	str("SEED_PATH", &c.Database.SeedPath)
+	boolean("DATABASE_AUTO_MIGRATE", &c.Database.AutoMigrate)
+	str("REDIS_URL", &c.Redis.URL)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate checks value ranges and cross-field consistency.
+// Presence of deployment secrets (API keys, connection URLs) is checked by
+// each entrypoint since not every binary needs all of them.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	if p, err := strconv.Atoi(c.Server.Port); err != nil || p < 1 || p > 65535 {
+		check(false, "server.port must be a number between 1 and 65535, got %q", c.Server.Port)
+	}
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive, got %s", c.Server.ReadHeaderTimeout)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive, got %s", c.Server.ReadTimeout)
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive, got %s", c.Server.WriteTimeout)
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive, got %s", c.Server.IdleTimeout)
+
+	p := c.Planning
+	check(p.MinTruckCount >= 1, "planning.min_truck_count must be at least 1, got %d", p.MinTruckCount)
+	check(p.MaxTruckCount >= p.MinTruckCount,
+		"planning.max_truck_count (%d) must be >= planning.min_truck_count (%d)", p.MaxTruckCount, p.MinTruckCount)
+	check(p.DefaultTruckCount >= p.MinTruckCount && p.DefaultTruckCount <= p.MaxTruckCount,
+		"planning.default_truck_count must be between %d and %d, got %d", p.MinTruckCount, p.MaxTruckCount, p.DefaultTruckCount)
+	check(p.MinTruckCapacity >= 1, "planning.min_truck_capacity must be at least 1, got %d", p.MinTruckCapacity)
+	check(p.MaxTruckCapacity >= p.MinTruckCapacity,
+		"planning.max_truck_capacity (%d) must be >= planning.min_truck_capacity (%d)", p.MaxTruckCapacity, p.MinTruckCapacity)
+	check(p.DefaultTruckCapacity >= p.MinTruckCapacity && p.DefaultTruckCapacity <= p.MaxTruckCapacity,
+		"planning.default_truck_capacity must be between %d and %d, got %d", p.MinTruckCapacity, p.MaxTruckCapacity, p.DefaultTruckCapacity)
+	check(p.Concurrency >= 1, "planning.concurrency must be at least 1, got %d", p.Concurrency)
+	check(p.ServiceTimePerStop >= 0, "planning.service_time_per_stop must not be negative, got %s", p.ServiceTimePerStop)
+	check(p.ServiceTimePerPackage >= 0, "planning.service_time_per_package must not be negative, got %s", p.ServiceTimePerPackage)
+	check(p.MaxShift >= 0, "planning.max_shift must not be negative, got %s", p.MaxShift)
+	check(p.BreakAfter >= 0, "planning.break_after must not be negative, got %s", p.BreakAfter)
+	check(p.BreakDuration >= 0, "planning.break_duration must not be negative, got %s", p.BreakDuration)
+	check(p.BreakAfter == 0 || p.BreakDuration > 0,
+		"planning.break_duration must be positive when planning.break_after is set, got %s", p.BreakDuration)
+	check(p.ReloadTime >= 0, "planning.reload_time must not be negative, got %s", p.ReloadTime)
+	check(p.SameDayWindow >= 0, "planning.same_day_window must not be negative, got %s", p.SameDayWindow)
+	check(p.ExpressWindow >= 0, "planning.express_window must not be negative, got %s", p.ExpressWindow)
+	check(p.BalanceTolerance >= 0, "planning.balance_tolerance must not be negative, got %g", p.BalanceTolerance)
+
+	o := c.ORS
+	check(strings.HasPrefix(o.BaseURL, "http://") || strings.HasPrefix(o.BaseURL, "https://"),
+		"ors.base_url must be an http(s) URL, got %q", o.BaseURL)
+	check(strings.TrimSpace(o.Profile) != "", "ors.profile must not be empty")
+	check(o.Timeout > 0, "ors.timeout must be positive, got %s", o.Timeout)
+	check(o.MaxAttempts >= 1, "ors.max_attempts must be at least 1, got %d", o.MaxAttempts)
+	check(o.InitialBackoff >= 0, "ors.initial_backoff must not be negative, got %s", o.InitialBackoff)
+	check(o.Concurrency >= 1, "ors.concurrency must be at least 1, got %d", o.Concurrency)
+
+	check(c.Cache.TTL > 0, "cache.ttl must be positive, got %s", c.Cache.TTL)
+	check(c.Cache.NegativeTTL > 0, "cache.negative_ttl must be positive, got %s", c.Cache.NegativeTTL)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package config_test
+
+import (
+	"delivery-route-service/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		env         map[string]string
+		wantErr     bool
+		errContains string
+		check       func(t *testing.T, cfg config.Config)
+	}{
+		{
+			name: "defaults when no file or env is set",
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Planning.DefaultTruckCount != 3 || cfg.Planning.DefaultTruckCapacity != 16 {
+					t.Fatalf("unexpected planning defaults: %+v", cfg.Planning)
+				}
+				if cfg.ORS.Profile != "driving-car" || cfg.ORS.MaxAttempts != 4 {
+					t.Fatalf("unexpected ORS defaults: %+v", cfg.ORS)
+				}
+				if cfg.Cache.TTL != 24*time.Hour {
+					t.Fatalf("expected 24h cache ttl, got %s", cfg.Cache.TTL)
+				}
+			},
+		},
+		{
+			name: "file values override defaults",
+			file: "planning:\n  default_truck_count: 5\nors:\n  profile: driving-hgv\ncache:\n  ttl: 1h\n",
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Planning.DefaultTruckCount != 5 {
+					t.Fatalf("expected default_truck_count 5, got %d", cfg.Planning.DefaultTruckCount)
+				}
+				if cfg.ORS.Profile != "driving-hgv" {
+					t.Fatalf("expected profile driving-hgv, got %q", cfg.ORS.Profile)
+				}
+				if cfg.Cache.TTL != time.Hour {
+					t.Fatalf("expected 1h cache ttl, got %s", cfg.Cache.TTL)
+				}
+				if cfg.Planning.MaxTruckCount != 10 {
+					t.Fatalf("expected untouched max_truck_count 10, got %d", cfg.Planning.MaxTruckCount)
+				}
+			},
+		},
+		{
+			name: "env overrides file",
+			file: "planning:\n  default_truck_count: 5\n",
+			env:  map[string]string{"PLAN_DEFAULT_TRUCK_COUNT": "7"},
+			check: func(t *testing.T, cfg config.Config) {
+				if cfg.Planning.DefaultTruckCount != 7 {
+					t.Fatalf("expected default_truck_count 7, got %d", cfg.Planning.DefaultTruckCount)
+				}
+			},
+		},
+		{
+			name: "auto migrate from env",
+			file: "database:\n  auto_migrate: false\n",
+			env:  map[string]string{"DATABASE_AUTO_MIGRATE": "true"},
+			check: func(t *testing.T, cfg config.Config) {
+				if !cfg.Database.AutoMigrate {
+					t.Fatalf("expected auto_migrate enabled by env")
+				}
+			},
+		},
+		{
+			name:        "error on malformed env boolean",
+			env:         map[string]string{"DATABASE_AUTO_MIGRATE": "sometimes"},
+			wantErr:     true,
+			errContains: "DATABASE_AUTO_MIGRATE",
+		},
+		{
+			name:        "error on unknown file key",
+			file:        "planning:\n  truck_count: 5\n",
+			wantErr:     true,
+			errContains: "truck_count",
+		},
+		{
+			name:        "error on malformed env integer",
+			env:         map[string]string{"ORS_MAX_ATTEMPTS": "many"},
+			wantErr:     true,
+			errContains: "ORS_MAX_ATTEMPTS",
+		},
+		{
+			name:        "error when default is outside range",
+			env:         map[string]string{"PLAN_DEFAULT_TRUCK_CAPACITY": "200"},
+			wantErr:     true,
+			errContains: "planning.default_truck_capacity",
+		},
+		{
+			name:        "error when max is below min",
+			env:         map[string]string{"PLAN_MIN_TRUCK_COUNT": "4", "PLAN_MAX_TRUCK_COUNT": "2"},
+			wantErr:     true,
+			errContains: "planning.max_truck_count",
+		},
+		{
+			name:        "error on negative service time",
+			env:         map[string]string{"PLAN_SERVICE_TIME_PER_STOP": "-1m"},
+			wantErr:     true,
+			errContains: "planning.service_time_per_stop",
+		},
+		{
+			name:        "error on break rule without break duration",
+			env:         map[string]string{"PLAN_BREAK_AFTER": "4h"},
+			wantErr:     true,
+			errContains: "planning.break_duration",
+		},
+		{
+			name:        "error on non-positive cache ttl",
+			env:         map[string]string{"CACHE_TTL": "0s"},
+			wantErr:     true,
+			errContains: "cache.ttl",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			if tc.file != "" {
+				path := filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tc.file), 0o600); err != nil {
+					t.Fatalf("write config file: %v", err)
+				}
+				t.Setenv("CONFIG_FILE", path)
+			}
+
+			cfg, err := config.Load()
+
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if tc.errContains != "" && !strings.Contains(err.Error(), tc.errContains) {
+					t.Fatalf("expected error containing %q, got %q", tc.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.check(t, cfg)
+		})
+	}
+}
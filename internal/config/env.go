@@ -1,10 +0,0 @@
-package config
-
-import "os"
-
-func Get(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
-}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Immutable geographic coordinates (longitude, latitude).
 type Coordinates struct {
 	Lon float64
@@ -8,3 +14,36 @@ type Coordinates struct {
 
 // Return coordinates as [lon, lat] for external API compatibility.
 func (c Coordinates) CoordsToList() []float64 { return []float64{c.Lon, c.Lat} }
+
+// Valid reports whether the latitude and longitude are within range.
+func (c Coordinates) Valid() bool {
+	return c.Lat >= -90 && c.Lat <= 90 && c.Lon >= -180 && c.Lon <= 180
+}
+
+// String formats coordinates as "lat,lon", the form accepted by ParseCoordinates.
+// It is used as the destination label for packages given only by position.
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.6f,%.6f", c.Lat, c.Lon)
+}
+
+// ParseCoordinates parses a "lat,lon" destination label.
+// It returns false for anything that is not two in-range numbers.
+func ParseCoordinates(s string) (Coordinates, bool) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return Coordinates{}, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return Coordinates{}, false
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return Coordinates{}, false
+	}
+	c := Coordinates{Lon: lon, Lat: lat}
+	if !c.Valid() {
+		return Coordinates{}, false
+	}
+	return c, true
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel error kinds for planning failures caused by the request or its data
+// rather than by infrastructure. Typed errors below match them via errors.Is.
+var (
+	ErrValidation       = errors.New("validation failed")
+	ErrCapacityExceeded = errors.New("truck capacity exceeded")
+)
+
+// ValidationError reports an invalid request field or package attribute.
+// PackageID is zero when the error is not tied to a specific package.
+type ValidationError struct {
+	Field     string
+	PackageID int
+	Reason    string
+}
+
+func (e *ValidationError) Error() string {
+	if e.PackageID != 0 {
+		return fmt.Sprintf("package_id=%d: %s", e.PackageID, e.Reason)
+	}
+	return e.Reason
+}
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// CapacityError reports a package that could not be loaded because the truck is full.
+// Dimension names the limit that was reached; empty means the package count,
+// in which case Capacity holds it, and otherwise Limit holds the weight or
+// volume limit.
+type CapacityError struct {
+	TruckID   int
+	Capacity  int
+	PackageID int
+	Dimension string
+	Limit     float64
+}
+
+func (e *CapacityError) Error() string {
+	if e.Dimension != "" && e.Dimension != DimensionPackages {
+		return fmt.Sprintf(
+			"truck %d cannot load package_id=%d without exceeding its %s limit of %g",
+			e.TruckID, e.PackageID, e.Dimension, e.Limit,
+		)
+	}
+	return fmt.Sprintf(
+		"truck %d is at full capacity (capacity=%d) and cannot load package_id=%d",
+		e.TruckID, e.Capacity, e.PackageID,
+	)
+}
+
+func (e *CapacityError) Is(target error) bool { return target == ErrCapacityExceeded }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Represents a manually pinned location for an address.
+// Overrides take precedence over geocoding so destinations that the
+// geocoder resolves to the wrong point can be corrected by hand.
+type GeocodeOverride struct {
+	Address     string
+	Coordinates Coordinates
+	CreatedAt   time.Time
+}
@@ -0,0 +1,10 @@
+package domain
+
+// Depot that trucks start from and packages are delivered from.
+// Address is a street address or a "lat,lon" label, like a plan's hub; fleet
+// trucks belong to the hub whose Address matches their home hub.
+type Hub struct {
+	HubID   int
+	Name    string
+	Address string
+}
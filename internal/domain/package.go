@@ -4,11 +4,32 @@ import "time"
 
 // Represents a single delivery unit handled by the system.
 // A Package has a unique identifier and a single destination address.
+// Coordinates are optional; when set they are used instead of geocoding the
+// destination, and they are filled in after the destination is first geocoded.
+// ServiceTime, when set, is how long handing over this package takes and
+// replaces the per-package default of the plan's ServiceTime model.
+// WeightKg and VolumeM3 count against a truck's weight and volume limits;
+// zero means negligible.
+// HubID ties the package to the hub it must be delivered from; zero leaves it
+// unassigned for multi-depot planning to place.
+// Pickup, when set, is where the truck collects the package on its route,
+// before delivering it to Destination; empty means it is loaded at the hub.
+// ServiceLevel ranks the package for assignment when trucks cannot take
+// everything; Deadline, when set, is when it is promised and replaces the
+// service level's default promise.
 // Delivery timestamps are poplated during simulation after a route
 // has been planned and applied.
 type Package struct {
-	PackageID   int
-	Destination string
-	LoadedAt    *time.Time
-	DeliveredAt *time.Time
+	PackageID    int
+	Destination  string
+	Coordinates  *Coordinates
+	ServiceTime  *time.Duration
+	WeightKg     float64
+	VolumeM3     float64
+	HubID        int
+	Pickup       string
+	ServiceLevel ServiceLevel
+	Deadline     *time.Time
+	LoadedAt     *time.Time
+	DeliveredAt  *time.Time
 }
@@ -5,10 +5,23 @@ import "time"
 // Represents a single stop in a delivery route.
 // A RouteStop corresponds to arriving at a specific destination at a computed time,
 // and delivering one or more packages associated with that destination.
+// DepartAt is ArriveAt plus the ServiceSeconds spent handing the packages over
+// and any BreakSeconds the driver rests there before the next leg.
+// PackageIDs are delivered at the stop and PickupIDs collected there, after
+// the deliveries; OnBoard is what the truck carries when it leaves.
 type RouteStop struct {
-	Destination string
-	ArriveAt    time.Time
-	PackageIDs  []int
+	Destination    string
+	ArriveAt       time.Time
+	DepartAt       time.Time
+	ServiceSeconds int
+	BreakSeconds   int
+	PackageIDs     []int
+	PickupIDs      []int
+	OnBoard        Cargo
+	// Distance driven from the start of the route up to this stop.
+	CumulativeDistanceMeters int
+	// Resolved position of Destination; nil when the provider did not geocode it.
+	Coordinates *Coordinates
 }
 
 // Represents the planned delivery route for a single truck.
@@ -16,9 +29,55 @@ type RouteStop struct {
 // sequence of delivery stops, along with aggregate distance and duration metrics.
 // It is immutable planning data and contains no side effects.
 type RoutePlan struct {
-	TruckID              int
+	TruckID int
+	// Fleet name of the truck; empty for trucks created for a single plan.
+	TruckName string
+	// 1-based position of this route among the truck's trips in a multi-trip
+	// plan; zero when the truck makes a single trip.
+	Trip                 int
+	StartLocation        string
 	DepartAt             time.Time
 	Stops                []RouteStop
+	ReturnToStart        bool
 	TotalDurationSeconds int
 	TotalDistanceMeters  int
+	// Time spent at stops and on breaks; included in TotalDurationSeconds.
+	TotalServiceSeconds int
+	TotalBreakSeconds   int
+	// What the route costs at the truck's per-km and per-hour rates; zero
+	// for trucks without rates.
+	TotalCost float64
+	// The most the truck carries at once on this route, in each dimension, and
+	// its limits, for reporting utilization; zero weight or volume limits mean none.
+	Load     Cargo
+	Capacity Cargo
+	// Resolved position of StartLocation; nil when unknown.
+	StartCoordinates *Coordinates
+	// Road path for the route; nil unless geometry was requested.
+	Geometry *RouteGeometry
+}
+
+// Represents the road path of a planned route as returned by a directions service.
+// Polyline is the encoded polyline (precision 5) for the whole route and Legs
+// holds one entry per consecutive pair of waypoints (hub -> stop, stop -> stop, ...).
+type RouteGeometry struct {
+	Polyline string
+	Legs     []RouteLeg
+}
+
+// Represents driving between two consecutive waypoints of a route.
+type RouteLeg struct {
+	From            string
+	To              string
+	DistanceMeters  int
+	DurationSeconds int
+	Steps           []DirectionStep
+}
+
+// Represents a single turn-by-turn instruction within a leg.
+type DirectionStep struct {
+	Instruction     string
+	Street          string
+	DistanceMeters  int
+	DurationSeconds int
 }
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// ServiceLevel is the delivery promise made for a package.
+type ServiceLevel string
+
+const (
+	// No promise beyond the day's plan; the empty level means the same.
+	ServiceLevelStandard ServiceLevel = "standard"
+	// Delivered the day it is planned, within Promises.SameDay of departure.
+	ServiceLevelSameDay ServiceLevel = "same_day"
+	// Delivered within Promises.Express of departure.
+	ServiceLevelExpress ServiceLevel = "express"
+)
+
+// ServiceLevels lists the accepted service levels, lowest priority first.
+var ServiceLevels = []ServiceLevel{ServiceLevelStandard, ServiceLevelSameDay, ServiceLevelExpress}
+
+// Rank orders service levels by priority: 0 for standard and unknown levels,
+// higher for more urgent ones.
+func (l ServiceLevel) Rank() int {
+	switch l {
+	case ServiceLevelSameDay:
+		return 1
+	case ServiceLevelExpress:
+		return 2
+	}
+	return 0
+}
+
+// Priority reports whether l ranks above standard.
+func (l ServiceLevel) Priority() bool {
+	return l.Rank() > 0
+}
+
+// Models the default delivery promise of each priority service level, as a
+// window after the plan's departure. A zero window sets no deadline, leaving
+// only the packages' own deadlines.
+type Promises struct {
+	SameDay time.Duration
+	Express time.Duration
+}
+
+// DeadlineOf returns when pkg is promised, for a plan departing at start:
+// its own Deadline when set, or start plus the window of its service level.
+// The boolean is false when pkg carries no promise.
+func (p Promises) DeadlineOf(pkg *Package, start time.Time) (time.Time, bool) {
+	if pkg.Deadline != nil {
+		return *pkg.Deadline, true
+	}
+
+	var window time.Duration
+	switch pkg.ServiceLevel {
+	case ServiceLevelSameDay:
+		window = p.SameDay
+	case ServiceLevelExpress:
+		window = p.Express
+	}
+	if window <= 0 {
+		return time.Time{}, false
+	}
+	return start.Add(window), true
+}
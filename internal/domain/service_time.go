@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Models the time a driver spends at a stop handing over packages.
+// A stop takes PerStop plus PerPackage for every package delivered there.
+// A package's own ServiceTime replaces PerPackage for that package, and an
+// entry in Destinations replaces the whole computed time for that stop.
+type ServiceTime struct {
+	PerStop      time.Duration
+	PerPackage   time.Duration
+	Destinations map[string]time.Duration
+}
+
+// AtStop returns how long delivering pkgs at destination takes.
+func (s ServiceTime) AtStop(destination string, pkgs []*Package) time.Duration {
+	if d, ok := s.Destinations[destination]; ok {
+		return d
+	}
+
+	total := s.PerStop
+	for _, pkg := range pkgs {
+		if pkg.ServiceTime != nil {
+			total += *pkg.ServiceTime
+			continue
+		}
+		total += s.PerPackage
+	}
+	return total
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Represents the working-time rules for one driver's route.
+// MaxDuration caps the route from departure until the last stop is done, or
+// until the truck is back at the start when the route returns there.
+// After BreakAfter on duty (driving plus service) since departure or the last
+// break, the driver takes a break of BreakDuration. Zero values disable a rule.
+type Shift struct {
+	MaxDuration   time.Duration
+	BreakAfter    time.Duration
+	BreakDuration time.Duration
+}
@@ -2,16 +2,32 @@ package domain
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Delivery truck aggregate holding packages and producing/applying RoutePlans.
+// Trucks stored in the fleet also carry a name, vehicle type, running costs and
+// availability; trucks created for a single plan leave them zero.
 type Truck struct {
-	TruckID       int
-	Capacity      int
+	TruckID int
+	Name    string
+	// Capacity is the number of packages the truck holds. MaxWeightKg and
+	// MaxVolumeM3 limit the total package weight and volume; zero means no limit.
+	Capacity    int
+	MaxWeightKg float64
+	MaxVolumeM3 float64
+	// Home hub the truck starts from.
 	StartLocation string
-	DepartAt      *time.Time
-	Packages      []*Package
+	VehicleType   string
+	CostPerKm     float64
+	CostPerHour   float64
+	// Unavailable trucks (in maintenance, off the road) cannot be planned.
+	Available bool
+	DepartAt  *time.Time
+	Packages  []*Package
+	// Driver working-time limits applied when routing this truck.
+	Shift Shift
 }
 
 func NewTruck(id int, capacity int, hub string) *Truck {
@@ -19,13 +35,138 @@ func NewTruck(id int, capacity int, hub string) *Truck {
 		TruckID:       id,
 		Capacity:      capacity,
 		StartLocation: hub,
+		Available:     true,
 	}
 }
 
-// Load a single package onto the truck.
+// Capacity dimensions named by CapacityError.
+const (
+	DimensionPackages = "packages"
+	DimensionWeight   = "weight_kg"
+	DimensionVolume   = "volume_m3"
+)
+
+// Cargo totals a set of packages in every capacity dimension. As a truck's
+// limits, zero weight or volume means no limit.
+type Cargo struct {
+	Packages int
+	WeightKg float64
+	VolumeM3 float64
+}
+
+// CargoOf sums the packages' count, weight and volume.
+func CargoOf(pkgs []*Package) Cargo {
+	var c Cargo
+	for _, p := range pkgs {
+		c = c.With(p)
+	}
+	return c
+}
+
+// With returns c with pkg added.
+func (c Cargo) With(pkg *Package) Cargo {
+	return Cargo{Packages: c.Packages + 1, WeightKg: c.WeightKg + pkg.WeightKg, VolumeM3: c.VolumeM3 + pkg.VolumeM3}
+}
+
+// Without returns c with pkg taken out.
+func (c Cargo) Without(pkg *Package) Cargo {
+	return Cargo{Packages: c.Packages - 1, WeightKg: c.WeightKg - pkg.WeightKg, VolumeM3: c.VolumeM3 - pkg.VolumeM3}
+}
+
+// Max returns the larger of c and o in each dimension.
+func (c Cargo) Max(o Cargo) Cargo {
+	return Cargo{Packages: max(c.Packages, o.Packages), WeightKg: max(c.WeightKg, o.WeightKg), VolumeM3: max(c.VolumeM3, o.VolumeM3)}
+}
+
+// Loaded totals the packages currently assigned to the truck.
+func (t *Truck) Loaded() Cargo {
+	return CargoOf(t.Packages)
+}
+
+// PicksUp reports whether the truck collects pkg along its route rather than
+// loading it at its start location.
+func (t *Truck) PicksUp(pkg *Package) bool {
+	pickup := strings.Join(strings.Fields(pkg.Pickup), " ")
+	return pickup != "" && pickup != strings.Join(strings.Fields(t.StartLocation), " ")
+}
+
+// Holds reports whether the truck can carry c all at once.
+func (t *Truck) Holds(c Cargo) bool {
+	dimension, _ := t.exceeded(c)
+	return dimension == ""
+}
+
+// exceeded names the first dimension in which c is over the truck's limits,
+// and that limit, or returns an empty dimension when c fits.
+func (t *Truck) exceeded(c Cargo) (dimension string, limit float64) {
+	// Sums of fractional weights are compared with a little slack so a load
+	// that adds up exactly to the limit is not rejected by rounding.
+	const slack = 1e-9
+	switch {
+	case c.Packages > t.Capacity:
+		return DimensionPackages, 0
+	case t.MaxWeightKg > 0 && c.WeightKg > t.MaxWeightKg+slack:
+		return DimensionWeight, t.MaxWeightKg
+	case t.MaxVolumeM3 > 0 && c.VolumeM3 > t.MaxVolumeM3+slack:
+		return DimensionVolume, t.MaxVolumeM3
+	}
+	return "", 0
+}
+
+// departureLoad totals the packages the truck carries when it sets out.
+func (t *Truck) departureLoad() Cargo {
+	var c Cargo
+	for _, pkg := range t.Packages {
+		if !t.PicksUp(pkg) {
+			c = c.With(pkg)
+		}
+	}
+	return c
+}
+
+// Limits returns the truck's capacity in every dimension.
+func (t *Truck) Limits() Cargo {
+	return Cargo{Packages: t.Capacity, WeightKg: t.MaxWeightKg, VolumeM3: t.MaxVolumeM3}
+}
+
+// CanLoad reports whether pkgs fit on the truck alongside what it already carries.
+func (t *Truck) CanLoad(pkgs []*Package) bool {
+	return t.CheckLoad(pkgs) == nil
+}
+
+// CheckLoad returns a CapacityError naming the first of pkgs that would not
+// fit on the truck alongside what it already carries, or nil when all fit.
+//
+// Packages loaded at the start location are all on board when the truck sets
+// out, so they must fit together. Packages the truck picks up on its route are
+// only on board between pickup and delivery, and each must merely fit on its
+// own: routing orders the stops so the load never goes over the limits.
+func (t *Truck) CheckLoad(pkgs []*Package) error {
+	c := t.departureLoad()
+	for _, pkg := range pkgs {
+		load := Cargo{}.With(pkg)
+		if !t.PicksUp(pkg) {
+			c = c.With(pkg)
+			load = c
+		}
+
+		dimension, limit := t.exceeded(load)
+		if dimension == "" {
+			continue
+		}
+		return &CapacityError{
+			TruckID: t.TruckID, Capacity: t.Capacity, PackageID: pkg.PackageID,
+			Dimension: dimension, Limit: limit,
+		}
+	}
+	return nil
+}
+
+// Load a single package onto the truck, enforcing the package count, weight
+// and volume limits.
 func (t *Truck) Load(pkg *Package) error {
-	if len(t.Packages) >= int(t.Capacity) {
-		return fmt.Errorf("load truck: Truck %d is at full capacity (capacity=%d)", t.TruckID, t.Capacity)
+	if err := t.CheckLoad([]*Package{pkg}); err != nil {
+		return fmt.Errorf("load truck: %w", err)
 	}
 	t.Packages = append(t.Packages, pkg)
 	return nil
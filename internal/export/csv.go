@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bytes"
+	"delivery-route-service/internal/domain"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var manifestHeader = []string{
+	"truck_id",
+	"trip",
+	"stop_sequence",
+	"destination",
+	"package_id",
+	"eta",
+	"cumulative_distance_meters",
+}
+
+// ManifestCSV renders a loading-dock manifest with one row per package, in
+// delivery order per truck and trip. Packages collected en route are left out
+// since they are never loaded at the dock.
+func ManifestCSV(plans []*domain.RoutePlan) ([]byte, error) {
+	return manifestCSV(plans, false)
+}
+
+// LoadOrderCSV renders the same manifest in reverse delivery order per truck,
+// so the packages for the last stop are loaded first and end up at the back.
+func LoadOrderCSV(plans []*domain.RoutePlan) ([]byte, error) {
+	return manifestCSV(plans, true)
+}
+
+func manifestCSV(plans []*domain.RoutePlan, loadOrder bool) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(manifestHeader); err != nil {
+		return nil, fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, plan := range plans {
+		collected := make(map[int]bool)
+		for _, s := range plan.Stops {
+			for _, id := range s.PickupIDs {
+				collected[id] = true
+			}
+		}
+		// Single-trip routes have Trip zero; they are the truck's first trip.
+		trip := max(plan.Trip, 1)
+
+		for i := range plan.Stops {
+			idx := i
+			if loadOrder {
+				idx = len(plan.Stops) - 1 - i
+			}
+			s := plan.Stops[idx]
+
+			for j := range s.PackageIDs {
+				pkgIdx := j
+				if loadOrder {
+					pkgIdx = len(s.PackageIDs) - 1 - j
+				}
+				id := s.PackageIDs[pkgIdx]
+				if collected[id] {
+					continue
+				}
+				row := []string{
+					strconv.Itoa(plan.TruckID),
+					strconv.Itoa(trip),
+					strconv.Itoa(idx + 1),
+					s.Destination,
+					strconv.Itoa(id),
+					s.ArriveAt.Format(time.RFC3339),
+					strconv.Itoa(s.CumulativeDistanceMeters),
+				}
+				if err := w.Write(row); err != nil {
+					return nil, fmt.Errorf("write csv row: %w", err)
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
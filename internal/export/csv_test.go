@@ -0,0 +1,82 @@
+package export_test
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManifestCSV(t *testing.T) {
+	departAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	plans := []*domain.RoutePlan{
+		{
+			TruckID: 1,
+			Stops: []domain.RouteStop{
+				{Destination: "DestA", ArriveAt: departAt.Add(time.Minute), PackageIDs: []int{1, 2}, CumulativeDistanceMeters: 100},
+				{Destination: "Dest, B", ArriveAt: departAt.Add(2 * time.Minute), PackageIDs: []int{3}, CumulativeDistanceMeters: 250},
+			},
+		},
+		{
+			TruckID: 2,
+			Trip:    2,
+			Stops: []domain.RouteStop{
+				{Destination: "Shop", ArriveAt: departAt.Add(3 * time.Minute), PickupIDs: []int{5}, CumulativeDistanceMeters: 50},
+				{Destination: "DestC", ArriveAt: departAt.Add(4 * time.Minute), PackageIDs: []int{4, 5}, CumulativeDistanceMeters: 90},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		render func([]*domain.RoutePlan) ([]byte, error)
+		want   [][]string
+	}{
+		{
+			name:   "delivery order skips packages collected en route",
+			render: export.ManifestCSV,
+			want: [][]string{
+				{"truck_id", "trip", "stop_sequence", "destination", "package_id", "eta", "cumulative_distance_meters"},
+				{"1", "1", "1", "DestA", "1", "2024-01-01T08:01:00Z", "100"},
+				{"1", "1", "1", "DestA", "2", "2024-01-01T08:01:00Z", "100"},
+				{"1", "1", "2", "Dest, B", "3", "2024-01-01T08:02:00Z", "250"},
+				{"2", "2", "2", "DestC", "4", "2024-01-01T08:04:00Z", "90"},
+			},
+		},
+		{
+			name:   "load order reverses stops and packages",
+			render: export.LoadOrderCSV,
+			want: [][]string{
+				{"truck_id", "trip", "stop_sequence", "destination", "package_id", "eta", "cumulative_distance_meters"},
+				{"1", "1", "2", "Dest, B", "3", "2024-01-01T08:02:00Z", "250"},
+				{"1", "1", "1", "DestA", "2", "2024-01-01T08:01:00Z", "100"},
+				{"1", "1", "1", "DestA", "1", "2024-01-01T08:01:00Z", "100"},
+				{"2", "2", "2", "DestC", "4", "2024-01-01T08:04:00Z", "90"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tc.render(plans)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			rows, err := csv.NewReader(strings.NewReader(string(out))).ReadAll()
+			if err != nil {
+				t.Fatalf("parse csv: %v", err)
+			}
+			if len(rows) != len(tc.want) {
+				t.Fatalf("expected %d rows, got %d:\n%s", len(tc.want), len(rows), out)
+			}
+			for i := range rows {
+				if strings.Join(rows[i], "|") != strings.Join(tc.want[i], "|") {
+					t.Fatalf("row %d: expected %v, got %v", i, tc.want[i], rows[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Package export renders route plans in formats consumed by external tools:
+// GeoJSON for map tooling, GPX for handheld GPS units and KML for Google Earth.
+// Renderers work from the coordinates already resolved during planning and
+// never geocode.
+package export
+
+import (
+	"delivery-route-service/internal/domain"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func truckName(plan *domain.RoutePlan) string {
+	if plan.Trip > 0 {
+		return fmt.Sprintf("Truck %d, trip %d", plan.TruckID, plan.Trip)
+	}
+	return fmt.Sprintf("Truck %d", plan.TruckID)
+}
+
+func stopName(i int, s domain.RouteStop) string {
+	return fmt.Sprintf("%d. %s", i+1, s.Destination)
+}
+
+// stopDescription lists the packages dropped at s and any it picks up.
+func stopDescription(s domain.RouteStop) string {
+	desc := "Packages: " + joinIDs(s.PackageIDs)
+	if len(s.PickupIDs) > 0 {
+		desc += "; Pickups: " + joinIDs(s.PickupIDs)
+	}
+	return desc
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ", ")
+}
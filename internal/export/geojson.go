@@ -0,0 +1,104 @@
+package export
+
+import (
+	"delivery-route-service/internal/domain"
+	"fmt"
+	"math"
+	"time"
+)
+
+// FeatureCollection is an RFC 7946 GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry holds a Point ([lon, lat]) or LineString ([][lon, lat]) position set.
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Feature kinds, set as the "kind" property so map tools can style routes and stops apart.
+const (
+	featureKindRoute = "route"
+	featureKindStop  = "stop"
+)
+
+// PlansGeoJSON renders plans as a FeatureCollection with one LineString per
+// truck route followed by one Point per stop. Routes use the road geometry
+// when it was requested and straight lines between stops otherwise. Stops
+// without resolved coordinates are omitted, as is a route with fewer than two
+// known positions.
+func PlansGeoJSON(plans []*domain.RoutePlan) (*FeatureCollection, error) {
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+
+	for _, plan := range plans {
+		path, err := routePath(plan)
+		if err != nil {
+			return nil, fmt.Errorf("geojson: truck %d: %w", plan.TruckID, err)
+		}
+
+		if len(path) >= 2 {
+			line := make([][]float64, 0, len(path))
+			for _, c := range path {
+				line = append(line, c.CoordsToList())
+			}
+			props := map[string]any{
+				"kind":                   featureKindRoute,
+				"truck_id":               plan.TruckID,
+				"depart_at":              plan.DepartAt.Format(time.RFC3339),
+				"stop_count":             len(plan.Stops),
+				"total_distance_meters":  plan.TotalDistanceMeters,
+				"total_duration_seconds": plan.TotalDurationSeconds,
+				"total_service_seconds":  plan.TotalServiceSeconds,
+				"total_break_seconds":    plan.TotalBreakSeconds,
+				"total_cost":             math.Round(plan.TotalCost*100) / 100,
+			}
+			if plan.Trip > 0 {
+				props["trip"] = plan.Trip
+			}
+			fc.Features = append(fc.Features, Feature{
+				Type:       "Feature",
+				Geometry:   Geometry{Type: "LineString", Coordinates: line},
+				Properties: props,
+			})
+		}
+
+		for i, s := range plan.Stops {
+			if s.Coordinates == nil {
+				continue
+			}
+			props := map[string]any{
+				"kind":            featureKindStop,
+				"truck_id":        plan.TruckID,
+				"sequence":        i + 1,
+				"destination":     s.Destination,
+				"arrive_at":       s.ArriveAt.Format(time.RFC3339),
+				"depart_at":       s.DepartAt.Format(time.RFC3339),
+				"service_seconds": s.ServiceSeconds,
+				"break_seconds":   s.BreakSeconds,
+				"package_ids":     s.PackageIDs,
+			}
+			if plan.Trip > 0 {
+				props["trip"] = plan.Trip
+			}
+			if len(s.PickupIDs) > 0 {
+				props["pickup_ids"] = s.PickupIDs
+			}
+			fc.Features = append(fc.Features, Feature{
+				Type:       "Feature",
+				Geometry:   Geometry{Type: "Point", Coordinates: s.Coordinates.CoordsToList()},
+				Properties: props,
+			})
+		}
+	}
+
+	return fc, nil
+}
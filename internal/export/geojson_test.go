@@ -0,0 +1,114 @@
+package export_test
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlansGeoJSON(t *testing.T) {
+	hub := domain.Coordinates{Lon: -112.0, Lat: 33.4}
+	destA := domain.Coordinates{Lon: -112.1, Lat: 33.5}
+	departAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		plan         *domain.RoutePlan
+		wantFeatures int
+		wantLine     [][]float64
+	}{
+		{
+			name: "straight line through start, stops and return",
+			plan: &domain.RoutePlan{
+				TruckID:          1,
+				StartLocation:    "Hub",
+				StartCoordinates: &hub,
+				DepartAt:         departAt,
+				ReturnToStart:    true,
+				Stops: []domain.RouteStop{
+					{Destination: "DestA", ArriveAt: departAt.Add(time.Minute), PackageIDs: []int{1, 2}, Coordinates: &destA},
+				},
+			},
+			wantFeatures: 2,
+			wantLine:     [][]float64{{-112.0, 33.4}, {-112.1, 33.5}, {-112.0, 33.4}},
+		},
+		{
+			name: "road geometry replaces straight lines",
+			plan: &domain.RoutePlan{
+				TruckID:          2,
+				StartLocation:    "Hub",
+				StartCoordinates: &hub,
+				DepartAt:         departAt,
+				Stops: []domain.RouteStop{
+					{Destination: "DestA", ArriveAt: departAt, PackageIDs: []int{3}, Coordinates: &destA},
+				},
+				Geometry: &domain.RouteGeometry{Polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@"},
+			},
+			wantFeatures: 2,
+			wantLine:     [][]float64{{-120.2, 38.5}, {-120.95, 40.7}, {-126.453, 43.252}},
+		},
+		{
+			name: "stops without coordinates are omitted",
+			plan: &domain.RoutePlan{
+				TruckID:       3,
+				StartLocation: "Hub",
+				DepartAt:      departAt,
+				Stops: []domain.RouteStop{
+					{Destination: "DestA", ArriveAt: departAt, PackageIDs: []int{4}},
+				},
+			},
+			wantFeatures: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fc, err := export.PlansGeoJSON([]*domain.RoutePlan{tc.plan})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fc.Type != "FeatureCollection" {
+				t.Fatalf("expected FeatureCollection, got %q", fc.Type)
+			}
+			if len(fc.Features) != tc.wantFeatures {
+				t.Fatalf("expected %d features, got %d", tc.wantFeatures, len(fc.Features))
+			}
+			if tc.wantLine == nil {
+				return
+			}
+
+			route := fc.Features[0]
+			if route.Geometry.Type != "LineString" {
+				t.Fatalf("expected LineString first, got %q", route.Geometry.Type)
+			}
+			line := route.Geometry.Coordinates.([][]float64)
+			if len(line) != len(tc.wantLine) {
+				t.Fatalf("expected %d positions, got %d", len(tc.wantLine), len(line))
+			}
+			for i := range line {
+				for j := range line[i] {
+					if math.Abs(line[i][j]-tc.wantLine[i][j]) > 1e-9 {
+						t.Fatalf("position %d: expected %v, got %v", i, tc.wantLine[i], line[i])
+					}
+				}
+			}
+
+			stop := fc.Features[1]
+			if stop.Geometry.Type != "Point" {
+				t.Fatalf("expected Point, got %q", stop.Geometry.Type)
+			}
+			if stop.Properties["sequence"] != 1 || stop.Properties["truck_id"] != tc.plan.TruckID {
+				t.Fatalf("unexpected stop properties %v", stop.Properties)
+			}
+		})
+	}
+}
+
+func TestPlansGeoJSONInvalidPolyline(t *testing.T) {
+	plan := &domain.RoutePlan{TruckID: 1, Geometry: &domain.RouteGeometry{Polyline: "_p~iF~ps|"}}
+	if _, err := export.PlansGeoJSON([]*domain.RoutePlan{plan}); err == nil {
+		t.Fatalf("expected error for truncated polyline")
+	}
+}
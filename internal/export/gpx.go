@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bytes"
+	"delivery-route-service/internal/domain"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type gpxDoc struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Version string     `xml:"version,attr"`
+	Creator string     `xml:"creator,attr"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Routes  []gpxRoute `xml:"rte"`
+	Tracks  []gpxTrack `xml:"trk"`
+}
+
+type gpxRoute struct {
+	Name   string     `xml:"name"`
+	Desc   string     `xml:"desc,omitempty"`
+	Number int        `xml:"number"`
+	Points []gpxPoint `xml:"rtept"`
+}
+
+type gpxTrack struct {
+	Name     string            `xml:"name"`
+	Number   int               `xml:"number"`
+	Segments []gpxTrackSegment `xml:"trkseg"`
+}
+
+type gpxTrackSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time,omitempty"`
+	Name string  `xml:"name,omitempty"`
+	Desc string  `xml:"desc,omitempty"`
+}
+
+// creator identifies this service in exported GPX files.
+const creator = "delivery-route-service"
+
+// PlansGPX renders plans as a GPX 1.1 document with one route per truck whose
+// route points are the start, each stop in order and, for round trips, the
+// start again. When road geometry is present it is added as a track so
+// devices can show the exact path. Stops without coordinates are omitted.
+func PlansGPX(plans []*domain.RoutePlan) ([]byte, error) {
+	doc := gpxDoc{
+		Version: "1.1",
+		Creator: creator,
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+	}
+
+	for _, plan := range plans {
+		rte := gpxRoute{
+			Name:   truckName(plan),
+			Desc:   fmt.Sprintf("%d stops, %d m, %d s", len(plan.Stops), plan.TotalDistanceMeters, plan.TotalDurationSeconds),
+			Number: plan.TruckID,
+		}
+		if plan.StartCoordinates != nil {
+			rte.Points = append(rte.Points, gpxPoint{
+				Lat:  plan.StartCoordinates.Lat,
+				Lon:  plan.StartCoordinates.Lon,
+				Time: plan.DepartAt.UTC().Format(time.RFC3339),
+				Name: "Start",
+				Desc: plan.StartLocation,
+			})
+		}
+		for i, s := range plan.Stops {
+			if s.Coordinates == nil {
+				continue
+			}
+			rte.Points = append(rte.Points, gpxPoint{
+				Lat:  s.Coordinates.Lat,
+				Lon:  s.Coordinates.Lon,
+				Time: s.ArriveAt.UTC().Format(time.RFC3339),
+				Name: stopName(i, s),
+				Desc: stopDescription(s),
+			})
+		}
+		if plan.ReturnToStart && plan.StartCoordinates != nil {
+			rte.Points = append(rte.Points, gpxPoint{
+				Lat:  plan.StartCoordinates.Lat,
+				Lon:  plan.StartCoordinates.Lon,
+				Name: "Return",
+				Desc: plan.StartLocation,
+			})
+		}
+		doc.Routes = append(doc.Routes, rte)
+
+		if plan.Geometry == nil || plan.Geometry.Polyline == "" {
+			continue
+		}
+		path, err := decodePolyline(plan.Geometry.Polyline)
+		if err != nil {
+			return nil, fmt.Errorf("gpx: truck %d: %w", plan.TruckID, err)
+		}
+		seg := gpxTrackSegment{Points: make([]gpxPoint, 0, len(path))}
+		for _, c := range path {
+			seg.Points = append(seg.Points, gpxPoint{Lat: c.Lat, Lon: c.Lon})
+		}
+		doc.Tracks = append(doc.Tracks, gpxTrack{
+			Name:     truckName(plan),
+			Number:   plan.TruckID,
+			Segments: []gpxTrackSegment{seg},
+		})
+	}
+
+	return marshalXML(doc)
+}
+
+// marshalXML encodes doc with an XML declaration and indentation.
+func marshalXML(doc any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("encode xml: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,72 @@
+package export_test
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestPlansGPX(t *testing.T) {
+	hub := domain.Coordinates{Lon: -112.0, Lat: 33.4}
+	destA := domain.Coordinates{Lon: -112.1, Lat: 33.5}
+	departAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	plan := &domain.RoutePlan{
+		TruckID:          2,
+		StartLocation:    "Hub",
+		StartCoordinates: &hub,
+		DepartAt:         departAt,
+		ReturnToStart:    true,
+		Stops: []domain.RouteStop{
+			{Destination: "DestA", ArriveAt: departAt.Add(time.Minute), PackageIDs: []int{1, 2}, Coordinates: &destA},
+			{Destination: "DestB", ArriveAt: departAt.Add(2 * time.Minute), PackageIDs: []int{3}},
+		},
+		Geometry: &domain.RouteGeometry{Polyline: "_p~iF~ps|U_ulLnnqC_mqNvxq`@"},
+	}
+
+	out, err := export.PlansGPX([]*domain.RoutePlan{plan})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Routes  []struct {
+			Name   string `xml:"name"`
+			Number int    `xml:"number"`
+			Points []struct {
+				Lat  float64 `xml:"lat,attr"`
+				Lon  float64 `xml:"lon,attr"`
+				Name string  `xml:"name"`
+				Desc string  `xml:"desc"`
+			} `xml:"rtept"`
+		} `xml:"rte"`
+		Tracks []struct {
+			Points []struct{} `xml:"trkseg>trkpt"`
+		} `xml:"trk"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("decode gpx: %v\n%s", err, out)
+	}
+
+	if doc.Version != "1.1" {
+		t.Fatalf("expected GPX 1.1, got %q", doc.Version)
+	}
+	if len(doc.Routes) != 1 || doc.Routes[0].Number != 2 {
+		t.Fatalf("expected one route for truck 2, got %+v", doc.Routes)
+	}
+
+	// Start, DestA and the return; DestB has no coordinates.
+	points := doc.Routes[0].Points
+	if len(points) != 3 {
+		t.Fatalf("expected 3 route points, got %d", len(points))
+	}
+	if points[1].Name != "1. DestA" || points[1].Desc != "Packages: 1, 2" || points[1].Lat != 33.5 {
+		t.Fatalf("unexpected stop point %+v", points[1])
+	}
+	if len(doc.Tracks) != 1 || len(doc.Tracks[0].Points) != 3 {
+		t.Fatalf("expected one track with 3 points from the polyline, got %+v", doc.Tracks)
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"delivery-route-service/internal/domain"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type kmlDoc struct {
+	XMLName  xml.Name    `xml:"kml"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name    string      `xml:"name"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	TimeStamp   *kmlTimeStamp  `xml:"TimeStamp,omitempty"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// PlansKML renders plans as a KML 2.2 document with one folder per truck
+// holding the route path and a placemark per stop. The path follows the road
+// geometry when present and straight lines between stops otherwise. Stops
+// without coordinates are omitted.
+func PlansKML(plans []*domain.RoutePlan) ([]byte, error) {
+	doc := kmlDoc{
+		Xmlns:    "http://www.opengis.net/kml/2.2",
+		Document: kmlDocument{Name: "Delivery plan"},
+	}
+
+	for _, plan := range plans {
+		folder := kmlFolder{Name: truckName(plan)}
+
+		path, err := routePath(plan)
+		if err != nil {
+			return nil, fmt.Errorf("kml: truck %d: %w", plan.TruckID, err)
+		}
+		if len(path) >= 2 {
+			folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+				Name:        truckName(plan) + " route",
+				Description: fmt.Sprintf("%d stops, %d m, %d s", len(plan.Stops), plan.TotalDistanceMeters, plan.TotalDurationSeconds),
+				LineString:  &kmlLineString{Tessellate: 1, Coordinates: kmlCoordinates(path...)},
+			})
+		}
+
+		for i, s := range plan.Stops {
+			if s.Coordinates == nil {
+				continue
+			}
+			folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+				Name:        stopName(i, s),
+				Description: stopDescription(s),
+				TimeStamp:   &kmlTimeStamp{When: s.ArriveAt.UTC().Format(time.RFC3339)},
+				Point:       &kmlPoint{Coordinates: kmlCoordinates(*s.Coordinates)},
+			})
+		}
+
+		doc.Document.Folders = append(doc.Document.Folders, folder)
+	}
+
+	return marshalXML(doc)
+}
+
+// kmlCoordinates formats positions as KML "lon,lat" tuples separated by spaces.
+func kmlCoordinates(coords ...domain.Coordinates) string {
+	parts := make([]string, 0, len(coords))
+	for _, c := range coords {
+		parts = append(parts,
+			strconv.FormatFloat(c.Lon, 'f', -1, 64)+","+strconv.FormatFloat(c.Lat, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,74 @@
+package export_test
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/export"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestPlansKML(t *testing.T) {
+	hub := domain.Coordinates{Lon: -112.0, Lat: 33.4}
+	destA := domain.Coordinates{Lon: -112.1, Lat: 33.5}
+	departAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	plans := []*domain.RoutePlan{
+		{
+			TruckID:          1,
+			StartLocation:    "Hub",
+			StartCoordinates: &hub,
+			DepartAt:         departAt,
+			Stops: []domain.RouteStop{
+				{Destination: "DestA", ArriveAt: departAt.Add(time.Minute), PackageIDs: []int{1}, Coordinates: &destA},
+			},
+		},
+		{
+			TruckID:       2,
+			StartLocation: "Hub",
+			DepartAt:      departAt,
+			Stops: []domain.RouteStop{
+				{Destination: "DestB", ArriveAt: departAt, PackageIDs: []int{2}},
+			},
+		},
+	}
+
+	out, err := export.PlansKML(plans)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Folders []struct {
+			Name       string `xml:"name"`
+			Placemarks []struct {
+				Name       string `xml:"name"`
+				Point      string `xml:"Point>coordinates"`
+				LineString string `xml:"LineString>coordinates"`
+			} `xml:"Placemark"`
+		} `xml:"Document>Folder"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("decode kml: %v\n%s", err, out)
+	}
+
+	if len(doc.Folders) != 2 {
+		t.Fatalf("expected a folder per truck, got %d", len(doc.Folders))
+	}
+
+	first := doc.Folders[0]
+	if first.Name != "Truck 1" || len(first.Placemarks) != 2 {
+		t.Fatalf("expected path and one stop for truck 1, got %+v", first)
+	}
+	if want := "-112,33.4 -112.1,33.5"; first.Placemarks[0].LineString != want {
+		t.Fatalf("expected path %q, got %q", want, first.Placemarks[0].LineString)
+	}
+	if want := "-112.1,33.5"; first.Placemarks[1].Point != want {
+		t.Fatalf("expected point %q, got %q", want, first.Placemarks[1].Point)
+	}
+
+	// Truck 2 has no resolved positions, so its folder is empty.
+	if len(doc.Folders[1].Placemarks) != 0 {
+		t.Fatalf("expected no placemarks for truck 2, got %+v", doc.Folders[1].Placemarks)
+	}
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"delivery-route-service/internal/domain"
+	"errors"
+)
+
+// decodePolyline decodes an encoded polyline with precision 5, the format
+// returned by the ORS directions endpoint.
+func decodePolyline(s string) ([]domain.Coordinates, error) {
+	var (
+		coords   []domain.Coordinates
+		lat, lon int
+	)
+
+	next := func(i int) (int, int, error) {
+		result, shift := 0, 0
+		for {
+			if i >= len(s) {
+				return 0, i, errors.New("decode polyline: truncated input")
+			}
+			b := int(s[i]) - 63
+			i++
+			if b < 0 || b > 63 {
+				return 0, i, errors.New("decode polyline: invalid character")
+			}
+			result |= (b & 0x1f) << shift
+			shift += 5
+			if b < 0x20 {
+				break
+			}
+		}
+		if result&1 != 0 {
+			return ^(result >> 1), i, nil
+		}
+		return result >> 1, i, nil
+	}
+
+	for i := 0; i < len(s); {
+		dLat, j, err := next(i)
+		if err != nil {
+			return nil, err
+		}
+		dLon, k, err := next(j)
+		if err != nil {
+			return nil, err
+		}
+		i = k
+
+		lat += dLat
+		lon += dLon
+		coords = append(coords, domain.Coordinates{Lat: float64(lat) / 1e5, Lon: float64(lon) / 1e5})
+	}
+
+	return coords, nil
+}
+
+// routePath returns the positions a plan's route passes through: the decoded
+// road geometry when present, otherwise straight lines through the start,
+// each located stop and, for round trips, the start again.
+func routePath(plan *domain.RoutePlan) ([]domain.Coordinates, error) {
+	if plan.Geometry != nil && plan.Geometry.Polyline != "" {
+		return decodePolyline(plan.Geometry.Polyline)
+	}
+
+	path := make([]domain.Coordinates, 0, len(plan.Stops)+2)
+	if plan.StartCoordinates != nil {
+		path = append(path, *plan.StartCoordinates)
+	}
+	for _, s := range plan.Stops {
+		if s.Coordinates != nil {
+			path = append(path, *s.Coordinates)
+		}
+	}
+	if plan.ReturnToStart && plan.StartCoordinates != nil {
+		path = append(path, *plan.StartCoordinates)
+	}
+
+	return path, nil
+}
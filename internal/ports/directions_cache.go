@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+type DirectionsCache interface {
+	// Fetch cached geometry for an ordered waypoint list; ok is false on a miss.
+	Get(ctx context.Context, waypoints []string) (geometry *domain.RouteGeometry, ok bool, err error)
+	// Store geometry for an ordered waypoint list.
+	Put(ctx context.Context, waypoints []string, geometry *domain.RouteGeometry) error
+}
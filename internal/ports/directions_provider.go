@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+// Optional extension of DistanceProvider that returns the road path through
+// an ordered list of waypoints.
+type DirectionsProvider interface {
+	// Return geometry and per-leg directions visiting waypoints in order.
+	// Known coordinates are used as-is for the addresses they cover.
+	GetDirections(
+		ctx context.Context,
+		waypoints []string,
+		known map[string]domain.Coordinates,
+	) (*domain.RouteGeometry, error)
+}
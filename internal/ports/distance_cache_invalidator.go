@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// Optional extension of DistanceCache that can drop every entry involving an
+// address, used when the address's coordinates change.
+type DistanceCacheInvalidator interface {
+	// Remove cached distances where address is the origin or destination.
+	InvalidateAddress(ctx context.Context, address string) error
+}
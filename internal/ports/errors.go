@@ -0,0 +1,59 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel error kinds returned by DistanceProvider implementations.
+// Typed errors below match them via errors.Is so callers can classify failures
+// without depending on a concrete adapter.
+var (
+	ErrUpstreamUnavailable  = errors.New("upstream service unavailable")
+	ErrUpstreamRateLimited  = errors.New("upstream service rate limited")
+	ErrAddressNotGeocodable = errors.New("address not geocodable")
+)
+
+// UpstreamError reports a failed call to an external service after retries.
+// StatusCode is zero for transport failures (timeouts, connection errors).
+type UpstreamError struct {
+	Service    string
+	StatusCode int
+	// Server-suggested wait before retrying; zero when not provided.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s returned status %d: %v", e.Service, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s request failed: %v", e.Service, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error { return e.Err }
+
+func (e *UpstreamError) Is(target error) bool {
+	switch target {
+	case ErrUpstreamRateLimited:
+		return e.StatusCode == 429
+	case ErrUpstreamUnavailable:
+		return e.StatusCode == 0 || e.StatusCode >= 500
+	}
+	return false
+}
+
+// AddressError reports an address the provider could not resolve to coordinates.
+// PackageIDs is filled in by callers that know which packages use the address.
+type AddressError struct {
+	Address    string
+	Reason     string
+	PackageIDs []int
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("address %q not geocodable: %s", e.Address, e.Reason)
+}
+
+func (e *AddressError) Is(target error) bool { return target == ErrAddressNotGeocodable }
@@ -11,3 +11,13 @@ type GeocodeCache interface {
 	// Store address -> coordinate mappings in the cache.
 	PutMany(ctx context.Context, results map[string]domain.Coordinates) error
 }
+
+// Optional extension of GeocodeCache that remembers addresses known not to
+// geocode, so they are not re-queried on every request.
+type NegativeGeocodeCache interface {
+	GeocodeCache
+	// Fetch cached failure reasons for the given addresses.
+	GetFailures(ctx context.Context, addresses []string) (map[string]string, error)
+	// Store address -> failure reason mappings in the cache.
+	PutFailures(ctx context.Context, failures map[string]string) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+// Port: a boundary for persisting manual address -> coordinate overrides.
+type GeocodeOverrideRepository interface {
+	// Retrieve all overrides ordered by address.
+	ListOverrides(ctx context.Context) ([]domain.GeocodeOverride, error)
+	// Fetch overrides for the given addresses; addresses without one are omitted.
+	GetOverrides(ctx context.Context, addresses []string) (map[string]domain.Coordinates, error)
+	// Create or replace the override for an address.
+	PutOverride(ctx context.Context, override domain.GeocodeOverride) error
+	// Remove the override for an address, reporting whether one existed.
+	DeleteOverride(ctx context.Context, address string) (bool, error)
+}
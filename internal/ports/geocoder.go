@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+// Optional extension of DistanceProvider that resolves addresses up front.
+type Geocoder interface {
+	// Resolve addresses to coordinates, keyed by the address as given.
+	// Addresses that cannot be located are returned in failures with the
+	// provider's reason instead of as an error.
+	GeocodeAddresses(
+		ctx context.Context,
+		addresses []string,
+	) (coords map[string]domain.Coordinates, failures map[string]string, err error)
+}
+
+// Optional extension of DistanceMatrixProvider for callers that already know
+// coordinates for some addresses (e.g. stored on packages). Known coordinates
+// are used as-is instead of being looked up again.
+type CoordinateDistanceProvider interface {
+	DistanceMatrixProvider
+	// Return distances from one origin to many destinations using known coordinates where present.
+	GetDistancesWithCoordinates(
+		ctx context.Context,
+		origin string,
+		destinations []string,
+		known map[string]domain.Coordinates,
+	) (map[string]DistanceResult, error)
+}
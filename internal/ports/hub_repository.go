@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+// Port: a boundary for persisting the depots packages are delivered from.
+type HubRepository interface {
+	// Retrieve all hubs ordered by hub ID.
+	ListHubs(ctx context.Context) ([]*domain.Hub, error)
+	// Create or replace the hub with the given ID.
+	PutHub(ctx context.Context, hub *domain.Hub) error
+	// Remove a hub, reporting whether one existed. Packages tied to it
+	// become unassigned.
+	DeleteHub(ctx context.Context, id int) (bool, error)
+}
@@ -10,3 +10,18 @@ type PackageRepository interface {
 	// Retrieve all packages available for routing.
 	ListPackages(ctx context.Context) ([]*domain.Package, error)
 }
+
+// Optional extension of PackageRepository that stores resolved coordinates on
+// packages so they are not geocoded again.
+type PackageCoordinateWriter interface {
+	// Set coordinates for the given package IDs.
+	SetPackageCoordinates(ctx context.Context, coords map[int]domain.Coordinates) error
+}
+
+// Optional extension of PackageRepository that creates or replaces packages.
+type PackageWriter interface {
+	// Upsert packages in a single transaction; nothing is written on error.
+	// Stored coordinates are kept unless the destination changes or new
+	// coordinates are given.
+	UpsertPackages(ctx context.Context, pkgs []*domain.Package) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+)
+
+// Port: a boundary for persisting the trucks of the fleet.
+type TruckRepository interface {
+	// Retrieve all trucks ordered by truck ID.
+	ListTrucks(ctx context.Context) ([]*domain.Truck, error)
+	// Fetch trucks by ID; IDs without a truck are omitted.
+	GetTrucks(ctx context.Context, ids []int) (map[int]*domain.Truck, error)
+	// Create or replace the truck with the given ID.
+	PutTruck(ctx context.Context, truck *domain.Truck) error
+	// Remove a truck, reporting whether one existed.
+	DeleteTruck(ctx context.Context, id int) (bool, error)
+}
@@ -6,41 +6,28 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"time"
 )
 
 // AssignPackagesByDistance assigns packages to trucks using a simple heuristic.
 //
-// Destinations are sorted by hub distance and chunked across trucks to produce a
-// deterministic, reasonably balanced distribution without solving a full VRP.
-// This is a planning shortcut intended for predictable demo behavior.
+// Destinations are sorted by how far they are from the hub under objective,
+// and chunked across trucks to produce a deterministic, reasonably balanced
+// distribution without solving a full VRP. This is a planning shortcut
+// intended for predictable demo behavior.
 func AssignPackagesByDistance(
 	trucks []*domain.Truck,
 	pkgDest map[string][]*domain.Package,
 	distances map[string]ports.DistanceResult,
 	destinations []string,
+	objective Objective,
 ) error {
 	if len(trucks) == 0 {
 		return errors.New("assign packages: truck list must not be empty")
 	}
 
 	// Sort by hub distance so each truck receives a contiguous "band" of destinations.
-	slices.SortFunc(destinations, func(a, b string) int {
-		da := distances[a].DistanceMeters
-		db := distances[b].DistanceMeters
-		if da < db {
-			return -1
-		}
-		if da > db {
-			return 1
-		}
-		if a < b {
-			return -1
-		}
-		if a > b {
-			return 1
-		}
-		return 0
-	})
+	sortByHubCost(destinations, distances, objective, trucks)
 
 	nTrucks := len(trucks)
 	nDests := len(destinations)
@@ -76,3 +63,454 @@ func AssignPackagesByDistance(
 
 	return nil
 }
+
+// AssignPackagesToFleet assigns packages to trucks that differ in capacity or
+// start location, extending the band heuristic of AssignPackagesByDistance.
+//
+// Each destination belongs to the start location nearest to it under
+// objective, costed at the cheapest rates among the trucks starting there,
+// and those trucks split that start's destinations, sorted the same way, into
+// bands sized in proportion to their package capacity. A destination that
+// does not fit in its band, by count, weight or volume, goes to the first
+// truck with room, nearest start first; when no truck has room, assignment
+// fails with a capacity error.
+func AssignPackagesToFleet(
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	startDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	objective Objective,
+) error {
+	if len(trucks) == 0 {
+		return errors.New("assign packages: truck list must not be empty")
+	}
+
+	starts, byStart := groupByStart(trucks)
+	home := homeDestinations(destinations, starts, byStart, startDistances, objective)
+
+	var leftover []string
+	for _, s := range starts {
+		dests := home[s]
+		sortByHubCost(dests, startDistances[s], objective, byStart[s])
+
+		group := byStart[s]
+		groupCapacity := 0
+		for _, t := range group {
+			groupCapacity += t.Capacity
+		}
+
+		// Band boundaries follow the running share of the group's capacity.
+		next, cumulative := 0, 0
+		for _, t := range group {
+			cumulative += t.Capacity
+			end := (len(dests)*cumulative + groupCapacity - 1) / groupCapacity
+			for ; next < end; next++ {
+				d := dests[next]
+				if !t.CanLoad(pkgDest[d]) {
+					leftover = append(leftover, d)
+					continue
+				}
+				if err := t.LoadMultiple(pkgDest[d]); err != nil {
+					return fmt.Errorf("assign packages: truck %d: %w", t.TruckID, err)
+				}
+			}
+		}
+	}
+
+	for _, d := range leftover {
+		var nearest *domain.Truck
+		placed := false
+		for _, s := range nearestStarts(starts, byStart, startDistances, objective, d) {
+			for _, t := range byStart[s] {
+				if nearest == nil {
+					nearest = t
+				}
+				if !t.CanLoad(pkgDest[d]) {
+					continue
+				}
+				if err := t.LoadMultiple(pkgDest[d]); err != nil {
+					return fmt.Errorf("assign packages: truck %d: %w", t.TruckID, err)
+				}
+				placed = true
+				break
+			}
+			if placed {
+				break
+			}
+		}
+		if !placed {
+			return fmt.Errorf("assign packages: truck %d: %w", nearest.TruckID, nearest.CheckLoad(pkgDest[d]))
+		}
+	}
+
+	return nil
+}
+
+// groupByStart groups trucks by start location, listing the starts in the
+// order their first truck appears.
+func groupByStart(trucks []*domain.Truck) (starts []string, byStart map[string][]*domain.Truck) {
+	byStart = make(map[string][]*domain.Truck)
+	for _, t := range trucks {
+		if _, ok := byStart[t.StartLocation]; !ok {
+			starts = append(starts, t.StartLocation)
+		}
+		byStart[t.StartLocation] = append(byStart[t.StartLocation], t)
+	}
+	return starts, byStart
+}
+
+// nearestStarts orders starts by their cheapest truck's cost to reach d
+// under objective, earliest start on ties.
+func nearestStarts(
+	starts []string,
+	byStart map[string][]*domain.Truck,
+	startDistances map[string]map[string]ports.DistanceResult,
+	objective Objective,
+	d string,
+) []string {
+	ordered := slices.Clone(starts)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		ca := objective.cheapestLegCost(byStart[a], startDistances[a][d])
+		cb := objective.cheapestLegCost(byStart[b], startDistances[b][d])
+		switch {
+		case ca < cb:
+			return -1
+		case ca > cb:
+			return 1
+		}
+		return 0
+	})
+	return ordered
+}
+
+// homeDestinations files each destination under its nearest start.
+func homeDestinations(
+	destinations []string,
+	starts []string,
+	byStart map[string][]*domain.Truck,
+	startDistances map[string]map[string]ports.DistanceResult,
+	objective Objective,
+) map[string][]string {
+	home := make(map[string][]string, len(starts))
+	for _, d := range destinations {
+		s := nearestStarts(starts, byStart, startDistances, objective, d)[0]
+		home[s] = append(home[s], d)
+	}
+	return home
+}
+
+// UnassignedDestination reports a destination no truck could take.
+type UnassignedDestination struct {
+	Address    string
+	Reason     string
+	PackageIDs []int
+}
+
+// AssignPackagesWithinShift assigns packages like AssignPackagesByDistance but
+// stops loading a truck once fits reports that its route would break the
+// truck's shift or the next destination would exceed its capacity.
+//
+// As in AssignPackagesToFleet, each destination belongs to its nearest start
+// location and the trucks starting there split those destinations into
+// bands, sorted by their distance from that start in startDistances. Each
+// truck takes its band, ending it early when the next destination does not
+// fit; the following truck continues from there. Destinations left over are
+// then offered to every truck, nearest start first. Whatever still fits
+// nowhere is returned as unassigned instead of failing the plan.
+func AssignPackagesWithinShift(
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	startDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	fits func(*domain.Truck) (bool, error),
+	objective Objective,
+) (unassigned []UnassignedDestination, err error) {
+	if len(trucks) == 0 {
+		return nil, errors.New("assign packages: truck list must not be empty")
+	}
+
+	starts, byStart := groupByStart(trucks)
+	home := homeDestinations(destinations, starts, byStart, startDistances, objective)
+
+	var leftover []string
+	for _, s := range starts {
+		dests, group := home[s], byStart[s]
+		sortByHubCost(dests, startDistances[s], objective, group)
+		chunkSize := (len(dests) + len(group) - 1) / len(group)
+
+		next := 0
+		for _, truck := range group {
+			for taken := 0; next < len(dests) && taken < chunkSize; {
+				d := dests[next]
+				ok, err := tryLoad(truck, pkgDest[d], fits)
+				if err != nil {
+					return nil, fmt.Errorf("assign packages: truck %d: %w", truck.TruckID, err)
+				}
+				if !ok && len(truck.Packages) > 0 {
+					break
+				}
+				// A destination that does not fit an empty truck must not end every band.
+				if !ok {
+					leftover = append(leftover, d)
+				} else {
+					taken++
+				}
+				next++
+			}
+		}
+		leftover = append(leftover, dests[next:]...)
+	}
+
+	for _, d := range leftover {
+		placed := false
+		for _, s := range nearestStarts(starts, byStart, startDistances, objective, d) {
+			for _, truck := range byStart[s] {
+				ok, err := tryLoad(truck, pkgDest[d], fits)
+				if err != nil {
+					return nil, fmt.Errorf("assign packages: truck %d: %w", truck.TruckID, err)
+				}
+				if ok {
+					placed = true
+					break
+				}
+			}
+			if placed {
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+
+		reason, err := unassignedReason(trucks, pkgDest[d], fits)
+		if err != nil {
+			return nil, fmt.Errorf("assign packages: %w", err)
+		}
+		unassigned = append(unassigned, UnassignedDestination{Address: d, Reason: reason, PackageIDs: packageIDs(pkgDest[d])})
+	}
+
+	return unassigned, nil
+}
+
+// tryLoad loads pkgs onto truck when they fit its capacity in every dimension
+// and fits accepts the result, and leaves the truck unchanged otherwise.
+func tryLoad(truck *domain.Truck, pkgs []*domain.Package, fits func(*domain.Truck) (bool, error)) (bool, error) {
+	if !truck.CanLoad(pkgs) {
+		return false, nil
+	}
+
+	loaded := len(truck.Packages)
+	truck.Packages = append(truck.Packages, pkgs...)
+	ok, err := fits(truck)
+	if err != nil || !ok {
+		truck.Packages = truck.Packages[:loaded]
+	}
+	return ok, err
+}
+
+// unassignedReason explains why pkgs fit on none of the trucks.
+func unassignedReason(trucks []*domain.Truck, pkgs []*domain.Package, fits func(*domain.Truck) (bool, error)) (string, error) {
+	tooMany := true
+	for _, t := range trucks {
+		alone := *t
+		alone.Packages = nil
+		if !alone.CanLoad(pkgs) {
+			continue
+		}
+		tooMany = false
+
+		alone.Packages = pkgs
+		ok, err := fits(&alone)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return "no truck has shift time or capacity left", nil
+		}
+	}
+	if tooMany {
+		return "more than a truck can carry", nil
+	}
+	return "delivering here alone exceeds the shift limit", nil
+}
+
+// AssignPackagesByPriority assigns packages like AssignPackagesWithinShift,
+// one service level at a time, most urgent first, so standard packages only
+// take the room priority ones leave. A destination ranks by its most urgent
+// package and is never split.
+func AssignPackagesByPriority(
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	startDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	fits func(*domain.Truck) (bool, error),
+	objective Objective,
+) (unassigned []UnassignedDestination, err error) {
+	tiers := make([][]string, len(domain.ServiceLevels))
+	for _, d := range destinations {
+		rank := destinationRank(pkgDest[d])
+		tiers[rank] = append(tiers[rank], d)
+	}
+
+	for rank := len(tiers) - 1; rank >= 0; rank-- {
+		left, err := AssignPackagesWithinShift(trucks, pkgDest, startDistances, tiers[rank], fits, objective)
+		if err != nil {
+			return nil, err
+		}
+		unassigned = append(unassigned, left...)
+	}
+	return unassigned, nil
+}
+
+// AssignPackagesByMakespan assigns packages so the last truck finishes as
+// early as possible, for ObjectiveMakespan.
+//
+// Destinations are taken most urgent service level first and, within a
+// level, farthest first, by the drive from the nearest start location in
+// startDistances. Each goes to the truck that leaves the
+// latest finish among all trucks earliest, where finish reports when a
+// truck's route would end; ties go to the truck whose own finish grows
+// least, then to the earlier truck. A destination only goes to a truck with
+// room for its packages in every dimension, and only when fits accepts the
+// result. Destinations that fit nowhere are returned as unassigned.
+func AssignPackagesByMakespan(
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	startDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	fits func(*domain.Truck) (bool, error),
+	finish func(*domain.Truck) (time.Time, error),
+) (unassigned []UnassignedDestination, err error) {
+	if len(trucks) == 0 {
+		return nil, errors.New("assign packages: truck list must not be empty")
+	}
+
+	starts, _ := groupByStart(trucks)
+	reach := make(map[string]int, len(destinations))
+	for _, d := range destinations {
+		for i, s := range starts {
+			if r := startDistances[s][d].DurationSeconds; i == 0 || r < reach[d] {
+				reach[d] = r
+			}
+		}
+	}
+
+	ordered := slices.Clone(destinations)
+	slices.SortFunc(ordered, func(a, b string) int {
+		if ra, rb := destinationRank(pkgDest[a]), destinationRank(pkgDest[b]); ra != rb {
+			return rb - ra
+		}
+		if da, db := reach[a], reach[b]; da != db {
+			return db - da
+		}
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	})
+
+	finishes := make([]time.Time, len(trucks))
+	var latest time.Time
+	for i, t := range trucks {
+		if finishes[i], err = finish(t); err != nil {
+			return nil, fmt.Errorf("assign packages: truck %d: %w", t.TruckID, err)
+		}
+		if finishes[i].After(latest) {
+			latest = finishes[i]
+		}
+	}
+
+	for _, d := range ordered {
+		best := -1
+		var bestLatest, bestFinish time.Time
+		var bestGrowth time.Duration
+		for i, t := range trucks {
+			loaded := len(t.Packages)
+			ok, err := tryLoad(t, pkgDest[d], fits)
+			if err != nil {
+				return nil, fmt.Errorf("assign packages: truck %d: %w", t.TruckID, err)
+			}
+			if !ok {
+				continue
+			}
+			end, err := finish(t)
+			t.Packages = t.Packages[:loaded]
+			if err != nil {
+				return nil, fmt.Errorf("assign packages: truck %d: %w", t.TruckID, err)
+			}
+
+			after := latest
+			if end.After(after) {
+				after = end
+			}
+			growth := end.Sub(finishes[i])
+			if best < 0 || after.Before(bestLatest) || (after.Equal(bestLatest) && growth < bestGrowth) {
+				best, bestLatest, bestFinish, bestGrowth = i, after, end, growth
+			}
+		}
+
+		if best < 0 {
+			reason, err := unassignedReason(trucks, pkgDest[d], fits)
+			if err != nil {
+				return nil, fmt.Errorf("assign packages: %w", err)
+			}
+			unassigned = append(unassigned, UnassignedDestination{Address: d, Reason: reason, PackageIDs: packageIDs(pkgDest[d])})
+			continue
+		}
+		trucks[best].Packages = append(trucks[best].Packages, pkgDest[d]...)
+		finishes[best], latest = bestFinish, bestLatest
+	}
+
+	return unassigned, nil
+}
+
+// assignWithinShift assigns like AssignPackagesWithinShift. Should that leave
+// a priority package out, the trucks are put back as they were and loaded
+// again with AssignPackagesByPriority, so standard packages give way to it.
+// Under ObjectiveMakespan it assigns with AssignPackagesByMakespan instead,
+// which places priority destinations first by itself.
+func assignWithinShift(
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	startDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	fits func(*domain.Truck) (bool, error),
+	finish func(*domain.Truck) (time.Time, error),
+	objective Objective,
+) ([]UnassignedDestination, error) {
+	if objective == ObjectiveMakespan {
+		return AssignPackagesByMakespan(trucks, pkgDest, startDistances, destinations, fits, finish)
+	}
+
+	loaded := make([]int, len(trucks))
+	for i, t := range trucks {
+		loaded[i] = len(t.Packages)
+	}
+
+	unassigned, err := AssignPackagesWithinShift(trucks, pkgDest, startDistances, destinations, fits, objective)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(unassigned, func(u UnassignedDestination) bool {
+		return destinationRank(pkgDest[u.Address]) > 0
+	}) {
+		return unassigned, nil
+	}
+
+	for i, t := range trucks {
+		t.Packages = t.Packages[:loaded[i]]
+	}
+	return AssignPackagesByPriority(trucks, pkgDest, startDistances, destinations, fits, objective)
+}
+
+// destinationRank is the highest service level rank among pkgs.
+func destinationRank(pkgs []*domain.Package) int {
+	rank := 0
+	for _, pkg := range pkgs {
+		rank = max(rank, pkg.ServiceLevel.Rank())
+	}
+	return rank
+}
@@ -4,8 +4,12 @@ import (
 	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/ports"
 	"delivery-route-service/internal/services"
+	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAssignPackages(t *testing.T) {
@@ -51,6 +55,23 @@ func TestAssignPackages(t *testing.T) {
 			wantErr:      true,
 			errContains:  "truck",
 		},
+		{
+			name: "error when packages exceed the truck weight limit",
+			trucks: []*domain.Truck{
+				{TruckID: 1, Capacity: 5, MaxWeightKg: 20, StartLocation: "HUB"},
+			},
+			pkgDest: map[string][]*domain.Package{
+				"DestA": {{PackageID: 1, Destination: "DestA", WeightKg: 12}},
+				"DestB": {{PackageID: 2, Destination: "DestB", WeightKg: 9}},
+			},
+			distances: map[string]ports.DistanceResult{
+				"DestA": {DistanceMeters: 1000, DurationSeconds: 60},
+				"DestB": {DistanceMeters: 2000, DurationSeconds: 120},
+			},
+			destinations: []string{"DestA", "DestB"},
+			wantErr:      true,
+			errContains:  "weight_kg limit of 20",
+		},
 		{
 			name: "packages distributed evenly across multiple trucks",
 			trucks: []*domain.Truck{
@@ -92,7 +113,7 @@ func TestAssignPackages(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := services.AssignPackagesByDistance(tc.trucks, tc.pkgDest, tc.distances, tc.destinations)
+			err := services.AssignPackagesByDistance(tc.trucks, tc.pkgDest, tc.distances, tc.destinations, services.ObjectiveDistance)
 
 			if tc.wantErr {
 				if err == nil {
@@ -119,3 +140,283 @@ func TestAssignPackages(t *testing.T) {
 		})
 	}
 }
+
+func TestAssignPackagesToFleet(t *testing.T) {
+	// Destinations N1..N5 are 1-5 km from North and 20 km from South; S1 is
+	// 1 km from South and 20 km from North.
+	startDistances := map[string]map[string]ports.DistanceResult{
+		"North": {"S1": {DistanceMeters: 20000}},
+		"South": {"S1": {DistanceMeters: 1000}},
+	}
+	pkgDest := map[string][]*domain.Package{
+		"S1": {{PackageID: 100, Destination: "S1"}},
+	}
+	for i := 1; i <= 5; i++ {
+		d := fmt.Sprintf("N%d", i)
+		startDistances["North"][d] = ports.DistanceResult{DistanceMeters: i * 1000}
+		startDistances["South"][d] = ports.DistanceResult{DistanceMeters: 20000}
+		pkgDest[d] = []*domain.Package{{PackageID: i, Destination: d}}
+	}
+	fleet := func(southCapacity int) []*domain.Truck {
+		return []*domain.Truck{
+			{TruckID: 1, Capacity: 3, StartLocation: "North"},
+			{TruckID: 2, Capacity: 1, StartLocation: "North"},
+			{TruckID: 3, Capacity: southCapacity, StartLocation: "South"},
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		trucks               []*domain.Truck
+		destinations         []string
+		wantPackagesPerTruck []int
+		wantErrIs            error
+	}{
+		{
+			name:                 "bands follow capacity within each hub",
+			trucks:               fleet(2),
+			destinations:         []string{"S1", "N4", "N3", "N2", "N1"},
+			wantPackagesPerTruck: []int{3, 1, 1},
+		},
+		{
+			name:                 "overflow goes to a truck at another hub",
+			trucks:               fleet(2),
+			destinations:         []string{"S1", "N5", "N4", "N3", "N2", "N1"},
+			wantPackagesPerTruck: []int{3, 1, 2},
+		},
+		{
+			name:         "error when no truck has room",
+			trucks:       fleet(1),
+			destinations: []string{"S1", "N5", "N4", "N3", "N2", "N1"},
+			wantErrIs:    domain.ErrCapacityExceeded,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := services.AssignPackagesToFleet(tc.trucks, pkgDest, startDistances, tc.destinations, services.ObjectiveDistance)
+
+			if tc.wantErrIs != nil {
+				if !errors.Is(err, tc.wantErrIs) {
+					t.Fatalf("expected error matching %v, got %v", tc.wantErrIs, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i, want := range tc.wantPackagesPerTruck {
+				if len(tc.trucks[i].Packages) != want {
+					t.Fatalf("truck %d: expected %d packages, got %d",
+						tc.trucks[i].TruckID, want, len(tc.trucks[i].Packages),
+					)
+				}
+			}
+			for _, pkg := range tc.trucks[0].Packages {
+				if pkg.PackageID > 3 {
+					t.Fatalf("expected truck 1 to take the nearest band, got package %d", pkg.PackageID)
+				}
+			}
+		})
+	}
+}
+
+func TestAssignPackagesToFleetWeightAndVolume(t *testing.T) {
+	distances := map[string]map[string]ports.DistanceResult{
+		"Hub": {
+			"A": {DistanceMeters: 1000},
+			"B": {DistanceMeters: 2000},
+			"C": {DistanceMeters: 3000},
+		},
+	}
+	pkgDest := map[string][]*domain.Package{
+		"A": {{PackageID: 1, Destination: "A", WeightKg: 300, VolumeM3: 1}},
+		"B": {{PackageID: 2, Destination: "B", WeightKg: 300, VolumeM3: 1}},
+		"C": {{PackageID: 3, Destination: "C", WeightKg: 10, VolumeM3: 4}},
+	}
+
+	// Truck 1 has room for every package by count but not by weight, and
+	// truck 2 not by volume, so B and C trade places from their bands.
+	trucks := []*domain.Truck{
+		{TruckID: 1, Capacity: 10, MaxWeightKg: 500, StartLocation: "Hub"},
+		{TruckID: 2, Capacity: 10, MaxVolumeM3: 3, StartLocation: "Hub"},
+	}
+	if err := services.AssignPackagesToFleet(trucks, pkgDest, distances, []string{"C", "B", "A"}, services.ObjectiveDistance); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]int{{1, 3}, {2}}
+	for i, ids := range want {
+		var got []int
+		for _, pkg := range trucks[i].Packages {
+			got = append(got, pkg.PackageID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(ids) {
+			t.Fatalf("truck %d: expected packages %v, got %v", trucks[i].TruckID, ids, got)
+		}
+	}
+	if load := trucks[0].Loaded(); load.WeightKg != 310 || load.VolumeM3 != 5 {
+		t.Fatalf("expected truck 1 to carry 310 kg and 5 m3, got %+v", load)
+	}
+
+	heavy := map[string][]*domain.Package{"A": {{PackageID: 9, Destination: "A", WeightKg: 600, VolumeM3: 5}}}
+	trucks = []*domain.Truck{
+		{TruckID: 1, Capacity: 10, MaxWeightKg: 500, StartLocation: "Hub"},
+		{TruckID: 2, Capacity: 10, MaxVolumeM3: 3, StartLocation: "Hub"},
+	}
+	err := services.AssignPackagesToFleet(trucks, heavy, distances, []string{"A"}, services.ObjectiveDistance)
+	var capErr *domain.CapacityError
+	if !errors.As(err, &capErr) || capErr.Dimension != domain.DimensionWeight || capErr.PackageID != 9 {
+		t.Fatalf("expected a weight capacity error for package 9, got %v", err)
+	}
+}
+
+func TestAssignPackagesWithinShiftFromTwoHubs(t *testing.T) {
+	// N1..N3 are 1-3 km from North and 20 km from South; S1 is 1 km from
+	// South. Banding by distance from North alone would hand N3 to the
+	// South truck along with S1, or, taking express S1 first, S1 to the
+	// North truck.
+	startDistances := map[string]map[string]ports.DistanceResult{
+		"North": {"S1": {DistanceMeters: 20000}},
+		"South": {"S1": {DistanceMeters: 1000}},
+	}
+	for i := 1; i <= 3; i++ {
+		d := fmt.Sprintf("N%d", i)
+		startDistances["North"][d] = ports.DistanceResult{DistanceMeters: i * 1000}
+		startDistances["South"][d] = ports.DistanceResult{DistanceMeters: 20000}
+	}
+	fits := func(*domain.Truck) (bool, error) { return true, nil }
+
+	tests := []struct {
+		name   string
+		assign func([]*domain.Truck, map[string][]*domain.Package) ([]services.UnassignedDestination, error)
+	}{
+		{
+			name: "within shift",
+			assign: func(trucks []*domain.Truck, pkgDest map[string][]*domain.Package) ([]services.UnassignedDestination, error) {
+				return services.AssignPackagesWithinShift(trucks, pkgDest, startDistances, []string{"S1", "N3", "N2", "N1"}, fits, services.ObjectiveDistance)
+			},
+		},
+		{
+			name: "by priority",
+			assign: func(trucks []*domain.Truck, pkgDest map[string][]*domain.Package) ([]services.UnassignedDestination, error) {
+				return services.AssignPackagesByPriority(trucks, pkgDest, startDistances, []string{"S1", "N3", "N2", "N1"}, fits, services.ObjectiveDistance)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pkgDest := map[string][]*domain.Package{
+				"S1": {{PackageID: 100, Destination: "S1", ServiceLevel: domain.ServiceLevelExpress}},
+				"N1": {{PackageID: 1, Destination: "N1"}},
+				"N2": {{PackageID: 2, Destination: "N2"}},
+				"N3": {{PackageID: 3, Destination: "N3"}},
+			}
+			trucks := []*domain.Truck{
+				{TruckID: 1, Capacity: 3, StartLocation: "North"},
+				{TruckID: 2, Capacity: 3, StartLocation: "South"},
+			}
+
+			unassigned, err := tc.assign(trucks, pkgDest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(unassigned) != 0 {
+				t.Fatalf("expected every destination assigned, got %+v", unassigned)
+			}
+
+			want := [][]int{{1, 2, 3}, {100}}
+			for i, ids := range want {
+				var got []int
+				for _, pkg := range trucks[i].Packages {
+					got = append(got, pkg.PackageID)
+				}
+				slices.Sort(got)
+				if fmt.Sprint(got) != fmt.Sprint(ids) {
+					t.Fatalf("truck %d: expected packages %v, got %v", trucks[i].TruckID, ids, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAssignPackagesByMakespan(t *testing.T) {
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	distances := map[string]ports.DistanceResult{
+		"A": {DurationSeconds: 60},
+		"B": {DurationSeconds: 120},
+		"C": {DurationSeconds: 180},
+	}
+	// Each destination adds its drive from the hub to the truck's day.
+	finish := func(truck *domain.Truck) (time.Time, error) {
+		end := depart
+		seen := make(map[string]bool)
+		for _, pkg := range truck.Packages {
+			if !seen[pkg.Destination] {
+				seen[pkg.Destination] = true
+				end = end.Add(time.Duration(distances[pkg.Destination].DurationSeconds) * time.Second)
+			}
+		}
+		return end, nil
+	}
+	fits := func(*domain.Truck) (bool, error) { return true, nil }
+
+	tests := []struct {
+		name           string
+		capacity       int
+		express        string
+		want           [][]int
+		wantUnassigned []string
+	}{
+		// C goes first, then B to the idle truck and A where it ends sooner.
+		{name: "farthest first to the earliest finish", capacity: 3, want: [][]int{{3}, {2, 1}}},
+		{
+			name:           "priority destinations first",
+			capacity:       1,
+			express:        "A",
+			want:           [][]int{{1}, {3}},
+			wantUnassigned: []string{"B"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pkgDest := map[string][]*domain.Package{
+				"A": {{PackageID: 1, Destination: "A"}},
+				"B": {{PackageID: 2, Destination: "B"}},
+				"C": {{PackageID: 3, Destination: "C"}},
+			}
+			if tc.express != "" {
+				pkgDest[tc.express][0].ServiceLevel = domain.ServiceLevelExpress
+			}
+			trucks := []*domain.Truck{
+				{TruckID: 1, Capacity: tc.capacity, StartLocation: "Hub"},
+				{TruckID: 2, Capacity: tc.capacity, StartLocation: "Hub"},
+			}
+
+			unassigned, err := services.AssignPackagesByMakespan(trucks, pkgDest, map[string]map[string]ports.DistanceResult{"Hub": distances}, []string{"A", "B", "C"}, fits, finish)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for i, ids := range tc.want {
+				var got []int
+				for _, pkg := range trucks[i].Packages {
+					got = append(got, pkg.PackageID)
+				}
+				if fmt.Sprint(got) != fmt.Sprint(ids) {
+					t.Fatalf("truck %d: expected packages %v, got %v", trucks[i].TruckID, ids, got)
+				}
+			}
+			var left []string
+			for _, u := range unassigned {
+				left = append(left, u.Address)
+			}
+			if fmt.Sprint(left) != fmt.Sprint(tc.wantUnassigned) {
+				t.Fatalf("expected unassigned %v, got %v", tc.wantUnassigned, left)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// BalanceMode selects the workload rebalancing evens out between trucks.
+type BalanceMode string
+
+const (
+	// Route duration, service and breaks included.
+	BalanceDuration BalanceMode = "duration"
+	// Number of packages on the truck.
+	BalancePackages BalanceMode = "packages"
+)
+
+// BalanceModes lists the accepted balance modes.
+var BalanceModes = []BalanceMode{BalanceDuration, BalancePackages}
+
+// Balance asks planning to even out the trucks' workloads after assignment.
+// The zero value leaves trucks as the assignment heuristics load them.
+type Balance struct {
+	Mode BalanceMode
+	// How far the busiest truck may be ahead of the idlest, as a fraction of
+	// the average workload; zero keeps moving destinations while it helps.
+	Tolerance float64
+}
+
+// validateBalance rejects unknown modes and negative tolerances.
+func validateBalance(b Balance) error {
+	if b.Mode != "" && !slices.Contains(BalanceModes, b.Mode) {
+		return &domain.ValidationError{
+			Field:  "balance",
+			Reason: fmt.Sprintf("balance mode must be %q or %q, got %q", BalanceDuration, BalancePackages, b.Mode),
+		}
+	}
+	if b.Tolerance < 0 {
+		return &domain.ValidationError{
+			Field:  "balance",
+			Reason: fmt.Sprintf("balance tolerance must not be negative, got %g", b.Tolerance),
+		}
+	}
+	return nil
+}
+
+// rebalance moves whole destinations between trucks, after assignment and
+// before routing, to even out their workloads under req.Balance.
+//
+// Each step takes the busiest truck and moves one of its destinations to
+// another truck: the move that leaves the busier of the two with the least
+// work, as long as that is less than the busiest truck had. A destination
+// only moves to a truck with room for its packages in every dimension, and
+// only when fits accepts both trucks afterwards. Steps repeat until the
+// busiest and idlest trucks, empty ones included, are within the tolerance
+// or no move helps. Destinations are tried by name and trucks in order,
+// keeping the result deterministic.
+func rebalance(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	trucks []*domain.Truck,
+	pairwiseDist map[string]ports.DistanceResult,
+	fits func(*domain.Truck) (bool, error),
+) error {
+	if req.Balance.Mode == "" || len(trucks) < 2 {
+		return nil
+	}
+
+	route, name := req.Strategy.router()
+	opts := routeOptions(req)
+	workload := func(truck *domain.Truck) (float64, error) {
+		if req.Balance.Mode == BalancePackages || len(truck.Packages) == 0 {
+			return float64(len(truck.Packages)), nil
+		}
+		plan, err := route(ctx, truck, req.DepartAt, pairwiseDist, opts)
+		if err != nil {
+			return 0, fmt.Errorf("balance: plan %s route: %w", name, err)
+		}
+		return float64(plan.TotalDurationSeconds), nil
+	}
+
+	loads := make([]float64, len(trucks))
+	for i, t := range trucks {
+		w, err := workload(t)
+		if err != nil {
+			return err
+		}
+		loads[i] = w
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("balance: %w", err)
+		}
+
+		busiest, idlest, total := 0, 0, 0.0
+		for i, w := range loads {
+			if w > loads[busiest] {
+				busiest = i
+			}
+			if w < loads[idlest] {
+				idlest = i
+			}
+			total += w
+		}
+		if loads[busiest]-loads[idlest] <= req.Balance.Tolerance*total/float64(len(loads)) {
+			return nil
+		}
+
+		from := trucks[busiest]
+		best := loads[busiest]
+		var moving, staying []*domain.Package
+		to, fromLoad, toLoad := -1, 0.0, 0.0
+
+		for _, d := range truckDestinations(from) {
+			rest := *from
+			var take []*domain.Package
+			rest.Packages = nil
+			for _, pkg := range from.Packages {
+				if pkg.Destination == d {
+					take = append(take, pkg)
+				} else {
+					rest.Packages = append(rest.Packages, pkg)
+				}
+			}
+
+			restLoad, err := workload(&rest)
+			if err != nil {
+				return err
+			}
+			if restLoad >= best {
+				continue
+			}
+			ok, err := fits(&rest)
+			if err != nil {
+				return fmt.Errorf("balance: truck %d: %w", from.TruckID, err)
+			}
+			if !ok {
+				continue
+			}
+
+			for j, t := range trucks {
+				if j == busiest {
+					continue
+				}
+				loaded := len(t.Packages)
+				ok, err := tryLoad(t, take, fits)
+				if err != nil {
+					return fmt.Errorf("balance: truck %d: %w", t.TruckID, err)
+				}
+				if !ok {
+					continue
+				}
+				w, err := workload(t)
+				t.Packages = t.Packages[:loaded]
+				if err != nil {
+					return err
+				}
+				if worse := max(restLoad, w); worse < best {
+					best = worse
+					moving, staying = take, rest.Packages
+					to, fromLoad, toLoad = j, restLoad, w
+				}
+			}
+		}
+
+		if to < 0 {
+			return nil
+		}
+		from.Packages = staying
+		trucks[to].Packages = append(trucks[to].Packages, moving...)
+		loads[busiest], loads[to] = fromLoad, toLoad
+	}
+}
+
+// truckDestinations lists the destinations of the packages on truck by name.
+func truckDestinations(truck *domain.Truck) []string {
+	var dests []string
+	for _, pkg := range truck.Packages {
+		if !slices.Contains(dests, pkg.Destination) {
+			dests = append(dests, pkg.Destination)
+		}
+	}
+	slices.Sort(dests)
+	return dests
+}
+
+// durationSpread returns the shortest and longest of seconds and their
+// standard deviation, or zeros when there are none.
+func durationSpread(seconds []int) (shortest, longest int, stddev float64) {
+	if len(seconds) == 0 {
+		return 0, 0, 0
+	}
+	shortest, longest = slices.Min(seconds), slices.Max(seconds)
+
+	mean := 0.0
+	for _, s := range seconds {
+		mean += float64(s)
+	}
+	mean /= float64(len(seconds))
+	variance := 0.0
+	for _, s := range seconds {
+		variance += (float64(s) - mean) * (float64(s) - mean)
+	}
+	return shortest, longest, math.Sqrt(variance / float64(len(seconds)))
+}
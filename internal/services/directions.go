@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"sync"
+)
+
+// routeWaypoints lists the addresses a truck visits in order: its start, each
+// stop, and the start again when the route returns to it.
+func routeWaypoints(plan *domain.RoutePlan) []string {
+	waypoints := make([]string, 0, len(plan.Stops)+2)
+	waypoints = append(waypoints, plan.StartLocation)
+	for _, s := range plan.Stops {
+		waypoints = append(waypoints, s.Destination)
+	}
+	if plan.ReturnToStart {
+		waypoints = append(waypoints, plan.StartLocation)
+	}
+	return waypoints
+}
+
+// attachDirections fetches road geometry for every plan concurrently and
+// stores it on the plan. Any failure fails the whole step, since a partial
+// set of paths would be misleading to drivers.
+func attachDirections(
+	ctx context.Context,
+	plans []*domain.RoutePlan,
+	known map[string]domain.Coordinates,
+	dp ports.DirectionsProvider,
+	concurrency int,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, concurrency)
+	errCh := make(chan error, len(plans))
+	var wg sync.WaitGroup
+
+	for _, plan := range plans {
+		wg.Add(1)
+		go func(p *domain.RoutePlan) {
+			sem <- struct{}{}
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			geometry, err := dp.GetDirections(ctx, routeWaypoints(p), known)
+			if err != nil {
+				errCh <- fmt.Errorf("plan deliveries: get directions for truck %d: %w", p.TruckID, err)
+				cancel()
+				return
+			}
+			p.Geometry = geometry
+		}(plan)
+	}
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"delivery-route-service/internal/domain"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// importColumn returns the source column a field is read from.
+func importColumn(mapping map[string]string, field string) string {
+	if c, ok := mapping[field]; ok {
+		return c
+	}
+	return field
+}
+
+// readImportCSV streams CSV records to handle, one call per data row.
+// Header names are matched case-insensitively. Records with the wrong number
+// of fields are passed to reject instead of stopping the import.
+func readImportCSV(
+	src io.Reader,
+	mapping map[string]string,
+	handle func(row int, get func(field string) string),
+	reject func(row int, reason string),
+) error {
+	r := csv.NewReader(src)
+	r.TrimLeadingSpace = true
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return &domain.ValidationError{Field: "body", Reason: "csv input is empty"}
+	}
+	if err != nil {
+		return malformedInput(fmt.Errorf("read csv header: %w", err))
+	}
+
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+
+	cols := make(map[string]int, len(importFields))
+	for _, f := range importFields {
+		if i, ok := index[strings.ToLower(importColumn(mapping, f))]; ok {
+			cols[f] = i
+		}
+	}
+	if err := checkImportColumns(cols, mapping); err != nil {
+		return err
+	}
+
+	for row := 2; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) && errors.Is(pe.Err, csv.ErrFieldCount) {
+			reject(row, fmt.Sprintf("expected %d fields, got %d", len(header), len(rec)))
+			continue
+		}
+		if err != nil {
+			return malformedInput(fmt.Errorf("read csv row %d: %w", row, err))
+		}
+
+		handle(row, func(field string) string {
+			i, ok := cols[field]
+			if !ok || i >= len(rec) {
+				return ""
+			}
+			return strings.TrimSpace(rec[i])
+		})
+	}
+}
+
+// checkImportColumns fails when the source cannot identify packages or their
+// destinations at all, which would otherwise reject every row.
+func checkImportColumns(cols map[string]int, mapping map[string]string) error {
+	if _, ok := cols[importFieldPackageID]; !ok {
+		return &domain.ValidationError{
+			Field:  "map",
+			Reason: fmt.Sprintf("no %q column for package_id", importColumn(mapping, importFieldPackageID)),
+		}
+	}
+	_, hasDest := cols[importFieldDestination]
+	_, hasLat := cols[importFieldLat]
+	_, hasLon := cols[importFieldLon]
+	if !hasDest && !(hasLat && hasLon) {
+		return &domain.ValidationError{
+			Field:  "map",
+			Reason: fmt.Sprintf("no %q column for destination and no lat/lon columns", importColumn(mapping, importFieldDestination)),
+		}
+	}
+	return nil
+}
+
+// readImportJSON streams objects to handle from either a JSON array or a
+// sequence of top-level objects (NDJSON). Values may be strings or numbers.
+func readImportJSON(
+	src io.Reader,
+	mapping map[string]string,
+	handle func(row int, get func(field string) string),
+) error {
+	dec := json.NewDecoder(src)
+
+	first, err := dec.Token()
+	if err == io.EOF {
+		return &domain.ValidationError{Field: "body", Reason: "json input is empty"}
+	}
+	if err != nil {
+		return malformedInput(fmt.Errorf("read json: %w", err))
+	}
+
+	inArray := first == json.Delim('[')
+	if !inArray && first != json.Delim('{') {
+		return &domain.ValidationError{Field: "body", Reason: "json input must be an array or a stream of objects"}
+	}
+
+	// Token() consumed the opening brace of the first object in the NDJSON
+	// case, so rebuild the stream with it put back.
+	if !inArray {
+		dec = json.NewDecoder(io.MultiReader(strings.NewReader("{"), dec.Buffered(), src))
+	}
+
+	for row := 1; ; row++ {
+		if inArray && !dec.More() {
+			break
+		}
+
+		var obj map[string]json.RawMessage
+		err := dec.Decode(&obj)
+		if err == io.EOF && !inArray {
+			return nil
+		}
+		if err != nil {
+			return malformedInput(fmt.Errorf("read json object %d: %w", row, err))
+		}
+
+		handle(row, func(field string) string {
+			return jsonScalar(obj[importColumn(mapping, field)])
+		})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return malformedInput(fmt.Errorf("read json: %w", err))
+	}
+	return nil
+}
+
+// malformedInput turns syntax errors in the source into validation errors so
+// callers report them as bad input; I/O failures are returned unchanged.
+func malformedInput(err error) error {
+	var (
+		csvErr    *csv.ParseError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	if errors.As(err, &csvErr) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return &domain.ValidationError{Field: "body", Reason: err.Error()}
+	}
+	return err
+}
+
+// jsonScalar renders a JSON string or number as text; null and absent are empty.
+func jsonScalar(raw json.RawMessage) string {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if raw[0] == '"' && json.Unmarshal(raw, &s) == nil {
+		return strings.TrimSpace(s)
+	}
+	return string(raw)
+}
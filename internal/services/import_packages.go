@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportFormat is the encoding of a package import source.
+type ImportFormat string
+
+const (
+	// Comma-separated values with a header row.
+	ImportFormatCSV ImportFormat = "csv"
+	// A JSON array of objects, or a stream of objects (NDJSON).
+	ImportFormatJSON ImportFormat = "json"
+)
+
+// ImportMode controls what happens to valid rows when other rows fail validation.
+type ImportMode string
+
+const (
+	// Write nothing unless every row is valid.
+	ImportModeAtomic ImportMode = "atomic"
+	// Write the valid rows and report the rest.
+	ImportModeBestEffort ImportMode = "best_effort"
+)
+
+// Fields a source column can be mapped onto.
+const (
+	importFieldPackageID   = "package_id"
+	importFieldDestination = "destination"
+	importFieldLat         = "lat"
+	importFieldLon         = "lon"
+	// Optional per-package service time override, in whole seconds.
+	importFieldServiceSeconds = "service_seconds"
+	// Optional package weight and volume, counted against truck limits.
+	importFieldWeightKg = "weight_kg"
+	importFieldVolumeM3 = "volume_m3"
+	// Optional hub the package must be delivered from.
+	importFieldHubID = "hub_id"
+	// Optional address where the truck collects the package.
+	importFieldPickup = "pickup"
+	// Optional delivery promise: a service level, and an RFC 3339 deadline.
+	importFieldServiceLevel = "service_level"
+	importFieldDeadline     = "deadline"
+)
+
+var importFields = []string{
+	importFieldPackageID,
+	importFieldDestination,
+	importFieldLat,
+	importFieldLon,
+	importFieldServiceSeconds,
+	importFieldWeightKg,
+	importFieldVolumeM3,
+	importFieldHubID,
+	importFieldPickup,
+	importFieldServiceLevel,
+	importFieldDeadline,
+}
+
+type ImportPackagesRequest struct {
+	Format ImportFormat
+	Mode   ImportMode
+	// Source column (CSV header or JSON key) per package field. Fields not
+	// listed are read from the column of the same name.
+	Mapping map[string]string
+	// Validate and report without writing anything.
+	DryRun bool
+	// Stored hubs a row's hub_id must name; nil skips the check, for
+	// targets without hubs.
+	Hubs []*domain.Hub
+}
+
+// ImportRowError reports why a source row was rejected. Row is the 1-based
+// line in a CSV source (the header is row 1) or the 1-based object index in a
+// JSON source.
+type ImportRowError struct {
+	Row       int
+	PackageID int
+	Field     string
+	Reason    string
+}
+
+// ImportPackagesResult is the outcome of an import.
+type ImportPackagesResult struct {
+	// Data rows read from the source.
+	Total int
+	// Rows written, or that would be written in a dry run.
+	Imported int
+	Errors   []ImportRowError
+	DryRun   bool
+	Mode     ImportMode
+}
+
+// ParseImportMapping parses "field=column" pairs into a column mapping.
+func ParseImportMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		field, column, ok := strings.Cut(p, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || field == "" || column == "" {
+			return nil, &domain.ValidationError{
+				Field:  "map",
+				Reason: fmt.Sprintf("mapping %q must have the form field=column", p),
+			}
+		}
+		if !isImportField(field) {
+			return nil, &domain.ValidationError{
+				Field:  "map",
+				Reason: fmt.Sprintf("unknown field %q; expected one of %s", field, strings.Join(importFields, ", ")),
+			}
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+func isImportField(f string) bool {
+	for _, known := range importFields {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImportRequest checks format, mode and mapping before any input is read.
+func validateImportRequest(req ImportPackagesRequest) error {
+	switch req.Format {
+	case ImportFormatCSV, ImportFormatJSON:
+	default:
+		return fmt.Errorf("import packages: %w", &domain.ValidationError{
+			Field:  "format",
+			Reason: fmt.Sprintf("format must be %q or %q, got %q", ImportFormatCSV, ImportFormatJSON, req.Format),
+		})
+	}
+	switch req.Mode {
+	case ImportModeAtomic, ImportModeBestEffort:
+	default:
+		return fmt.Errorf("import packages: %w", &domain.ValidationError{
+			Field:  "mode",
+			Reason: fmt.Sprintf("mode must be %q or %q, got %q", ImportModeAtomic, ImportModeBestEffort, req.Mode),
+		})
+	}
+	for field := range req.Mapping {
+		if !isImportField(field) {
+			return fmt.Errorf("import packages: %w", &domain.ValidationError{
+				Field:  "map",
+				Reason: fmt.Sprintf("unknown field %q", field),
+			})
+		}
+	}
+	return nil
+}
+
+// rowToPackage validates one source row and builds its package.
+// A row may give lat/lon instead of a destination, as seed files can.
+func rowToPackage(get func(field string) string) (*domain.Package, *ImportRowError) {
+	idStr := get(importFieldPackageID)
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		return nil, &ImportRowError{
+			Field:  importFieldPackageID,
+			Reason: fmt.Sprintf("package_id must be a positive integer, got %q", idStr),
+		}
+	}
+
+	pkg := &domain.Package{PackageID: id, Destination: strings.Join(strings.Fields(get(importFieldDestination)), " ")}
+
+	latStr, lonStr := get(importFieldLat), get(importFieldLon)
+	if (latStr == "") != (lonStr == "") {
+		return nil, &ImportRowError{PackageID: id, Field: importFieldLat, Reason: "lat and lon must be given together"}
+	}
+	if latStr != "" {
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lon, lonErr := strconv.ParseFloat(lonStr, 64)
+		c := domain.Coordinates{Lon: lon, Lat: lat}
+		if latErr != nil || lonErr != nil || !c.Valid() {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     importFieldLat,
+				Reason:    fmt.Sprintf("coordinates %q,%q are not a valid position", latStr, lonStr),
+			}
+		}
+		pkg.Coordinates = &c
+	}
+
+	if v := get(importFieldServiceSeconds); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     importFieldServiceSeconds,
+				Reason:    fmt.Sprintf("service_seconds must be a non-negative integer, got %q", v),
+			}
+		}
+		d := time.Duration(secs) * time.Second
+		pkg.ServiceTime = &d
+	}
+
+	for _, f := range []struct {
+		field string
+		dst   *float64
+	}{
+		{importFieldWeightKg, &pkg.WeightKg},
+		{importFieldVolumeM3, &pkg.VolumeM3},
+	} {
+		v := get(f.field)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     f.field,
+				Reason:    fmt.Sprintf("%s must be a non-negative number, got %q", f.field, v),
+			}
+		}
+		*f.dst = n
+	}
+
+	if v := get(importFieldHubID); v != "" {
+		hub, err := strconv.Atoi(v)
+		if err != nil || hub < 1 {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     importFieldHubID,
+				Reason:    fmt.Sprintf("hub_id must be a positive integer, got %q", v),
+			}
+		}
+		pkg.HubID = hub
+	}
+
+	if v := get(importFieldServiceLevel); v != "" {
+		level := domain.ServiceLevel(strings.ToLower(v))
+		if !slices.Contains(domain.ServiceLevels, level) {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     importFieldServiceLevel,
+				Reason:    fmt.Sprintf("service_level must be one of %q, got %q", domain.ServiceLevels, v),
+			}
+		}
+		pkg.ServiceLevel = level
+	}
+
+	if v := get(importFieldDeadline); v != "" {
+		deadline, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, &ImportRowError{
+				PackageID: id,
+				Field:     importFieldDeadline,
+				Reason:    fmt.Sprintf("deadline must be an RFC 3339 timestamp, got %q", v),
+			}
+		}
+		pkg.Deadline = &deadline
+	}
+
+	if pkg.Destination == "" && pkg.Coordinates != nil {
+		pkg.Destination = pkg.Coordinates.String()
+	}
+	if pkg.Destination == "" {
+		return nil, &ImportRowError{PackageID: id, Field: importFieldDestination, Reason: "destination must not be empty"}
+	}
+
+	pkg.Pickup = strings.Join(strings.Fields(get(importFieldPickup)), " ")
+	if pkg.Pickup != "" && pkg.Pickup == pkg.Destination {
+		return nil, &ImportRowError{PackageID: id, Field: importFieldPickup, Reason: "pickup must differ from the destination"}
+	}
+
+	return pkg, nil
+}
+
+// ImportPackages reads packages from src row by row, validates each row and
+// upserts the valid ones through writer. Row problems are collected in the
+// result rather than returned as an error. In atomic mode nothing is written
+// when any row fails; in best-effort mode the valid rows are written. A dry run
+// stops after validation. Malformed input that cannot be read past is an error.
+func ImportPackages(
+	ctx context.Context,
+	src io.Reader,
+	req ImportPackagesRequest,
+	writer ports.PackageWriter,
+) (*ImportPackagesResult, error) {
+	if err := validateImportRequest(req); err != nil {
+		return nil, err
+	}
+
+	result := &ImportPackagesResult{DryRun: req.DryRun, Mode: req.Mode, Errors: []ImportRowError{}}
+	seen := make(map[int]int)
+	pkgs := make([]*domain.Package, 0, 64)
+	var hubIDs map[int]bool
+	if req.Hubs != nil {
+		hubIDs = make(map[int]bool, len(req.Hubs))
+		for _, h := range req.Hubs {
+			hubIDs[h.HubID] = true
+		}
+	}
+
+	handle := func(row int, get func(field string) string) {
+		result.Total++
+
+		pkg, rowErr := rowToPackage(get)
+		if rowErr == nil {
+			if first, dup := seen[pkg.PackageID]; dup {
+				rowErr = &ImportRowError{
+					PackageID: pkg.PackageID,
+					Field:     importFieldPackageID,
+					Reason:    fmt.Sprintf("duplicate package_id, first seen on row %d", first),
+				}
+			} else if hubIDs != nil && pkg.HubID != 0 && !hubIDs[pkg.HubID] {
+				rowErr = &ImportRowError{
+					PackageID: pkg.PackageID,
+					Field:     importFieldHubID,
+					Reason:    fmt.Sprintf("no hub with hub_id %d", pkg.HubID),
+				}
+			}
+		}
+		if rowErr != nil {
+			rowErr.Row = row
+			result.Errors = append(result.Errors, *rowErr)
+			return
+		}
+
+		seen[pkg.PackageID] = row
+		pkgs = append(pkgs, pkg)
+	}
+
+	var err error
+	switch req.Format {
+	case ImportFormatCSV:
+		err = readImportCSV(src, req.Mapping, handle, func(row int, reason string) {
+			result.Total++
+			result.Errors = append(result.Errors, ImportRowError{Row: row, Reason: reason})
+		})
+	case ImportFormatJSON:
+		err = readImportJSON(src, req.Mapping, handle)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("import packages: %w", err)
+	}
+
+	if req.Mode == ImportModeAtomic && len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	result.Imported = len(pkgs)
+	if req.DryRun || len(pkgs) == 0 {
+		return result, nil
+	}
+
+	if err := writer.UpsertPackages(ctx, pkgs); err != nil {
+		return nil, fmt.Errorf("import packages: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,251 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/services"
+	"delivery-route-service/internal/testutil"
+)
+
+func TestImportPackages(t *testing.T) {
+	csvWithErrors := "Order No,Ship To,lat,lon\n" +
+		"1,1 Main St,,\n" +
+		"x,2 Main St,,\n" +
+		"3,,33.4,-112.1\n" +
+		"1,Dup St,,\n" +
+		"5,5 Main St,95,-112\n" +
+		"6,6 Main St\n"
+
+	tests := []struct {
+		name         string
+		src          string
+		req          services.ImportPackagesRequest
+		wantTotal    int
+		wantImported int
+		wantErrRows  []int
+		wantWritten  []int
+		wantErrIs    error
+	}{
+		{
+			name: "csv with mapping in best-effort mode writes valid rows",
+			src:  csvWithErrors,
+			req: services.ImportPackagesRequest{
+				Format:  services.ImportFormatCSV,
+				Mode:    services.ImportModeBestEffort,
+				Mapping: map[string]string{"package_id": "Order No", "destination": "Ship To"},
+			},
+			wantTotal:    6,
+			wantImported: 2,
+			wantErrRows:  []int{3, 5, 6, 7},
+			wantWritten:  []int{1, 3},
+		},
+		{
+			name: "best-effort mode rejects rows naming an unknown hub",
+			src:  "package_id,destination,hub_id\n1,1 Main St,1\n2,2 Main St,7\n3,3 Main St,\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeBestEffort,
+				Hubs:   []*domain.Hub{{HubID: 1, Name: "North", Address: "1 Hub Rd"}},
+			},
+			wantTotal:    3,
+			wantImported: 2,
+			wantErrRows:  []int{3},
+			wantWritten:  []int{1, 3},
+		},
+		{
+			name: "atomic mode writes nothing when a row names an unknown hub",
+			src:  "package_id,destination,hub_id\n1,1 Main St,1\n2,2 Main St,7\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeAtomic,
+				Hubs:   []*domain.Hub{{HubID: 1, Name: "North", Address: "1 Hub Rd"}},
+			},
+			wantTotal:    2,
+			wantImported: 0,
+			wantErrRows:  []int{3},
+		},
+		{
+			name: "atomic mode writes nothing when any row fails",
+			src:  csvWithErrors,
+			req: services.ImportPackagesRequest{
+				Format:  services.ImportFormatCSV,
+				Mode:    services.ImportModeAtomic,
+				Mapping: map[string]string{"package_id": "Order No", "destination": "Ship To"},
+			},
+			wantTotal:    6,
+			wantImported: 0,
+			wantErrRows:  []int{3, 5, 6, 7},
+		},
+		{
+			name: "dry run validates without writing",
+			src:  "package_id,destination\n1,1 Main St\n2,2 Main St\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeAtomic,
+				DryRun: true,
+			},
+			wantTotal:    2,
+			wantImported: 2,
+		},
+		{
+			name: "json array with numeric and string values",
+			src:  `[{"id": 7, "address": "7 Main St"}, {"id": "8", "address": "8 Main St"}]`,
+			req: services.ImportPackagesRequest{
+				Format:  services.ImportFormatJSON,
+				Mode:    services.ImportModeAtomic,
+				Mapping: map[string]string{"package_id": "id", "destination": "address"},
+			},
+			wantTotal:    2,
+			wantImported: 2,
+			wantWritten:  []int{7, 8},
+		},
+		{
+			name: "ndjson stream",
+			src:  "{\"package_id\": 9, \"destination\": \"9 Main St\"}\n{\"package_id\": 0, \"destination\": \"Zero St\"}\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatJSON,
+				Mode:   services.ImportModeBestEffort,
+			},
+			wantTotal:    2,
+			wantImported: 1,
+			wantErrRows:  []int{2},
+			wantWritten:  []int{9},
+		},
+		{
+			name: "service_seconds must be a non-negative integer",
+			src:  "package_id,destination,service_seconds\n1,1 Main St,90\n2,2 Main St,\n3,3 Main St,-5\n4,4 Main St,1m\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeBestEffort,
+			},
+			wantTotal:    4,
+			wantImported: 2,
+			wantErrRows:  []int{4, 5},
+			wantWritten:  []int{1, 2},
+		},
+		{
+			name: "weight_kg and volume_m3 must be non-negative numbers",
+			src:  `[{"package_id": 1, "destination": "1 Main St", "weight_kg": 2.5, "volume_m3": "0.01"}, {"package_id": 2, "destination": "2 Main St", "weight_kg": -1}, {"package_id": 3, "destination": "3 Main St", "volume_m3": "big"}]`,
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatJSON,
+				Mode:   services.ImportModeBestEffort,
+			},
+			wantTotal:    3,
+			wantImported: 1,
+			wantErrRows:  []int{2, 3},
+			wantWritten:  []int{1},
+		},
+		{
+			name: "pickup must differ from the destination",
+			src:  "package_id,destination,pickup\n1,1 Main St,Store 4\n2,2 Main St, 2  Main St \n3,3 Main St,\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeBestEffort,
+			},
+			wantTotal:    3,
+			wantImported: 2,
+			wantErrRows:  []int{3},
+			wantWritten:  []int{1, 3},
+		},
+		{
+			name: "service_level must be known and deadline RFC 3339",
+			src:  "package_id,destination,service_level,deadline\n1,1 Main St,Express,2026-03-02T12:00:00-07:00\n2,2 Main St,,\n3,3 Main St,overnight,\n4,4 Main St,same_day,noon\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeBestEffort,
+			},
+			wantTotal:    4,
+			wantImported: 2,
+			wantErrRows:  []int{4, 5},
+			wantWritten:  []int{1, 2},
+		},
+		{
+			name: "missing package_id column is a validation error",
+			src:  "destination\n1 Main St\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   services.ImportModeAtomic,
+			},
+			wantErrIs: domain.ErrValidation,
+		},
+		{
+			name: "malformed json is a validation error",
+			src:  `[{"package_id": 1,`,
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatJSON,
+				Mode:   services.ImportModeAtomic,
+			},
+			wantErrIs: domain.ErrValidation,
+		},
+		{
+			name: "unknown mode is a validation error",
+			src:  "package_id,destination\n",
+			req: services.ImportPackagesRequest{
+				Format: services.ImportFormatCSV,
+				Mode:   "sometimes",
+			},
+			wantErrIs: domain.ErrValidation,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockPackageRepository(nil, nil)
+
+			result, err := services.ImportPackages(context.Background(), strings.NewReader(tc.src), tc.req, repo)
+			if tc.wantErrIs != nil {
+				if !errors.Is(err, tc.wantErrIs) {
+					t.Fatalf("expected error matching %v, got %v", tc.wantErrIs, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Total != tc.wantTotal || result.Imported != tc.wantImported {
+				t.Fatalf("expected total=%d imported=%d, got total=%d imported=%d",
+					tc.wantTotal, tc.wantImported, result.Total, result.Imported)
+			}
+
+			var errRows []int
+			for _, e := range result.Errors {
+				errRows = append(errRows, e.Row)
+			}
+			if !slices.Equal(errRows, tc.wantErrRows) {
+				t.Fatalf("expected error rows %v, got %v (%+v)", tc.wantErrRows, errRows, result.Errors)
+			}
+
+			var written []int
+			for _, batch := range repo.Upserted {
+				for _, p := range batch {
+					written = append(written, p.PackageID)
+				}
+			}
+			if !slices.Equal(written, tc.wantWritten) {
+				t.Fatalf("expected written packages %v, got %v", tc.wantWritten, written)
+			}
+		})
+	}
+}
+
+func TestParseImportMapping(t *testing.T) {
+	mapping, err := services.ParseImportMapping([]string{"package_id=Order No", " destination = Ship To "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mapping["package_id"] != "Order No" || mapping["destination"] != "Ship To" {
+		t.Fatalf("unexpected mapping %v", mapping)
+	}
+
+	for _, bad := range []string{"package_id", "weight=Kg", "=Order No"} {
+		if _, err := services.ParseImportMapping([]string{bad}); !errors.Is(err, domain.ErrValidation) {
+			t.Fatalf("%q: expected validation error, got %v", bad, err)
+		}
+	}
+}
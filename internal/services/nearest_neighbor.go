@@ -7,20 +7,57 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"time"
 )
 
+// RouteOptions holds the settings a routing strategy applies to every truck.
+type RouteOptions struct {
+	// Include the leg back to the start location in the totals.
+	ReturnToStart bool
+	// Time spent at each stop, added to the ETAs of later stops and to the totals.
+	ServiceTime domain.ServiceTime
+	// Default promises of priority service levels, counted from PromiseStart,
+	// or from the route's departure when it is zero.
+	Promises     domain.Promises
+	PromiseStart time.Time
+	// What the choice of each next stop minimizes; empty uses ObjectiveDuration.
+	Objective Objective
+}
+
+// deadlines returns when each of pkgs that carries a promise is due, by
+// package ID, for a route departing at departAt.
+func (o RouteOptions) deadlines(pkgs []*domain.Package, departAt time.Time) map[int]time.Time {
+	start := o.PromiseStart
+	if start.IsZero() {
+		start = departAt
+	}
+	due := make(map[int]time.Time)
+	for _, pkg := range pkgs {
+		if d, ok := o.Promises.DeadlineOf(pkg, start); ok {
+			due[pkg.PackageID] = d
+		}
+	}
+	return due
+}
+
 // Plan a delivery route using a greedy nearest-neighbor algorithm.
 //
-// The algorithm minimizes immediate travel duration at each step.
+// The algorithm minimizes the cost of the next leg under opts.Objective at
+// each step: its travel duration unless another objective is selected.
 // It does not attempt global route optimization (e.g., VRP solvers).
 // The design prioritizes determinism and simplicity over optimality.
+// Packages with a pickup are collected on the way, before they are delivered,
+// without the load ever exceeding the truck's limits. A package with a
+// promise is delivered ahead of nearer stops when going there first would
+// break a deadline that can still be kept.
 func NearestNeighborRoute(
 	ctx context.Context,
 	truck *domain.Truck,
 	departAt time.Time,
 	distances map[string]ports.DistanceResult,
-	returnToStart bool,
+	opts RouteOptions,
 ) (*domain.RoutePlan, error) {
 	startLocation := truck.StartLocation
 	packages := truck.Packages
@@ -32,35 +69,91 @@ func NearestNeighborRoute(
 	if len(packages) == 0 {
 		return &domain.RoutePlan{
 			TruckID:              truck.TruckID,
+			TruckName:            truck.Name,
+			Load:                 domain.Cargo{},
+			Capacity:             truck.Limits(),
+			StartLocation:        startLocation,
 			DepartAt:             departAt,
 			Stops:                []domain.RouteStop{},
+			ReturnToStart:        opts.ReturnToStart,
 			TotalDurationSeconds: 0,
 			TotalDistanceMeters:  0,
 		}, nil
 	}
 
-	byDestination := make(map[string][]int)
-	for _, pkg := range packages {
-		byDestination[pkg.Destination] = append(byDestination[pkg.Destination], pkg.PackageID)
+	visits, err := nearestNeighborVisits(truck, departAt, distances, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	remainingDestinations := make(map[string]struct{})
-	for dest := range byDestination {
-		remainingDestinations[dest] = struct{}{}
+	return sequenceRoute(truck, departAt, visits, distances, opts)
+}
+
+// visit is one stop of a route: the packages delivered there, then the
+// packages picked up there.
+type visit struct {
+	destination string
+	drops       []*domain.Package
+	pickups     []*domain.Package
+}
+
+// nearestNeighborVisits orders the stops of truck greedily by the cost of
+// each leg under opts.Objective.
+//
+// A stop is worth visiting while the truck carries a package for it or a
+// package waiting there for pickup fits alongside the current load. Packages
+// are delivered before any pickups at the same stop, so a pickup can use the
+// room just freed. Every package on board can always be delivered, so the
+// route always completes, and a location with pickups that did not fit is
+// visited again later.
+//
+// The nearest stop gives way to the one due first among the packages on
+// board whose deadline can still be met, when detouring to the nearest would
+// miss one of them. The clock for this counts driving and service time but
+// not breaks.
+func nearestNeighborVisits(
+	truck *domain.Truck,
+	departAt time.Time,
+	distances map[string]ports.DistanceResult,
+	opts RouteOptions,
+) ([]visit, error) {
+	due := opts.deadlines(truck.Packages, departAt)
+	now := departAt
+
+	// Packages on board by destination, and waiting for pickup by location.
+	onBoard := make(map[string][]*domain.Package)
+	waiting := make(map[string][]*domain.Package)
+	var load domain.Cargo
+	for _, pkg := range truck.Packages {
+		if truck.PicksUp(pkg) {
+			pickup := strings.Join(strings.Fields(pkg.Pickup), " ")
+			waiting[pickup] = append(waiting[pickup], pkg)
+			continue
+		}
+		onBoard[pkg.Destination] = append(onBoard[pkg.Destination], pkg)
+		load = load.With(pkg)
 	}
 
-	currentTime := departAt
-	currentLocation := startLocation
+	// fitsPickup reports whether any package waiting at location fits now.
+	fitsPickup := func(location string, load domain.Cargo) bool {
+		return slices.ContainsFunc(waiting[location], func(p *domain.Package) bool {
+			return truck.Holds(load.With(p))
+		})
+	}
 
-	stops := []domain.RouteStop{}
-	totalDistanceMeters := 0
-	totalDurationSeconds := 0
+	currentLocation := truck.StartLocation
+	visits := make([]visit, 0, len(onBoard)+len(waiting))
 
-	for len(remainingDestinations) > 0 {
-		destinations := make([]string, 0, len(remainingDestinations))
-		for d := range remainingDestinations {
+	for len(onBoard) > 0 || len(waiting) > 0 {
+		destinations := make([]string, 0, len(onBoard)+len(waiting))
+		for d := range onBoard {
 			destinations = append(destinations, d)
 		}
+		for d := range waiting {
+			if _, ok := onBoard[d]; !ok && fitsPickup(d, load) {
+				destinations = append(destinations, d)
+			}
+		}
 
 		for _, d := range destinations {
 			if _, ok := distances[currentLocation+"|"+d]; !ok {
@@ -69,42 +162,224 @@ func NearestNeighborRoute(
 		}
 
 		var bestDestination string
-		minDuration := math.MaxInt64
+		minCost := math.Inf(1)
 
-		// Select next stop by minimum travel duration (greedy step.)
+		// Select next stop by minimum leg cost (greedy step.)
 		for _, d := range destinations {
-			currentDuration := distances[currentLocation+"|"+d].DurationSeconds
-			// Tie-breaker ensures deterministic ordering when durations are equal.
-			if currentDuration < minDuration || (currentDuration == minDuration && (bestDestination == "" || d < bestDestination)) {
-				minDuration = currentDuration
+			currentCost := opts.Objective.legCost(truck, distances[currentLocation+"|"+d])
+			// Tie-breaker ensures deterministic ordering when costs are equal.
+			if currentCost < minCost || (currentCost == minCost && (bestDestination == "" || d < bestDestination)) {
+				minCost = currentCost
 				bestDestination = d
 			}
 		}
 
 		if bestDestination == "" {
-			return nil, errors.New("plan route: failed to select next destination")
+			return nil, fmt.Errorf("plan route: truck %d: %w", truck.TruckID, truck.CheckLoad(truck.Packages))
 		}
-		bestResult := distances[currentLocation+"|"+bestDestination]
 
-		currentTime = currentTime.Add(time.Duration(bestResult.DurationSeconds) * time.Second)
-		totalDurationSeconds += bestResult.DurationSeconds
-		totalDistanceMeters += bestResult.DistanceMeters
+		service := func(d string) time.Duration {
+			return opts.ServiceTime.AtStop(d, append(slices.Clone(onBoard[d]), waiting[d]...))
+		}
+		if len(due) > 0 {
+			bestDestination = keepPromises(bestDestination, currentLocation, now, onBoard, due, distances, service)
+		}
+		now = now.Add(time.Duration(distances[currentLocation+"|"+bestDestination].DurationSeconds)*time.Second +
+			service(bestDestination))
+
+		v := visit{destination: bestDestination, drops: onBoard[bestDestination]}
+		delete(onBoard, bestDestination)
+		for _, pkg := range v.drops {
+			load = load.Without(pkg)
+		}
+
+		var left []*domain.Package
+		for _, pkg := range waiting[bestDestination] {
+			if !truck.Holds(load.With(pkg)) {
+				left = append(left, pkg)
+				continue
+			}
+			v.pickups = append(v.pickups, pkg)
+			onBoard[pkg.Destination] = append(onBoard[pkg.Destination], pkg)
+			load = load.With(pkg)
+		}
+		if len(left) > 0 {
+			waiting[bestDestination] = left
+		} else {
+			delete(waiting, bestDestination)
+		}
+
+		visits = append(visits, v)
+		currentLocation = bestDestination
+	}
+
+	return visits, nil
+}
+
+// keepPromises returns the stop to make after from, at now, instead of
+// nearest: the on-board destination due first among those still reachable
+// in time, when going to nearest first would make one of them late. Ties go
+// to the shorter leg, then the name. Otherwise nearest stands.
+func keepPromises(
+	nearest, from string,
+	now time.Time,
+	onBoard map[string][]*domain.Package,
+	due map[int]time.Time,
+	distances map[string]ports.DistanceResult,
+	service func(string) time.Duration,
+) string {
+	leg := func(a, b string) time.Duration {
+		return time.Duration(distances[a+"|"+b].DurationSeconds) * time.Second
+	}
+
+	// The earliest deadline at each destination that can still be met.
+	reachable := make(map[string]time.Time)
+	for d, pkgs := range onBoard {
+		for _, pkg := range pkgs {
+			deadline, ok := due[pkg.PackageID]
+			if !ok || now.Add(leg(from, d)).After(deadline) {
+				continue
+			}
+			if cur, ok := reachable[d]; !ok || deadline.Before(cur) {
+				reachable[d] = deadline
+			}
+		}
+	}
+
+	leaveNearest := now.Add(leg(from, nearest) + service(nearest))
+	broken := false
+	for d, deadline := range reachable {
+		if d != nearest && leaveNearest.Add(leg(nearest, d)).After(deadline) {
+			broken = true
+			break
+		}
+	}
+	if !broken {
+		return nearest
+	}
+
+	urgent := ""
+	for d, deadline := range reachable {
+		if urgent == "" {
+			urgent = d
+			continue
+		}
+		switch cur := reachable[urgent]; {
+		case deadline.Before(cur):
+			urgent = d
+		case deadline.Equal(cur) && (leg(from, d) < leg(from, urgent) || (leg(from, d) == leg(from, urgent) && d < urgent)):
+			urgent = d
+		}
+	}
+	return urgent
+}
+
+// sequenceRoute builds the plan for making visits in the given order,
+// computing arrival and departure times, cumulative distance, the load on
+// board after each stop and route totals. The route's cost charges the
+// truck's hourly rate for all of its duration, service and breaks included.
+//
+// Breaks required by truck.Shift are taken at stops, after service: the driver
+// rests before any leg that, with the service at its end, would take them past
+// BreakAfter on duty. A single leg longer than BreakAfter cannot be split, so
+// the driver rests before it and drives it in one go.
+func sequenceRoute(
+	truck *domain.Truck,
+	departAt time.Time,
+	visits []visit,
+	distances map[string]ports.DistanceResult,
+	opts RouteOptions,
+) (*domain.RoutePlan, error) {
+	startLocation := truck.StartLocation
+	currentTime := departAt
+	currentLocation := startLocation
+
+	serviceAt := make([]int, len(visits))
+	for i, v := range visits {
+		handled := append(slices.Clone(v.drops), v.pickups...)
+		serviceAt[i] = int(opts.ServiceTime.AtStop(v.destination, handled) / time.Second)
+	}
+
+	breakAfter := int(truck.Shift.BreakAfter / time.Second)
+	breakSeconds := int(truck.Shift.BreakDuration / time.Second)
+	// Driving and service since departure or the last break.
+	onDuty := 0
+
+	var load domain.Cargo
+	for _, pkg := range truck.Packages {
+		if !truck.PicksUp(pkg) {
+			load = load.With(pkg)
+		}
+	}
+	peak := load
+
+	stops := make([]domain.RouteStop, 0, len(visits))
+	totalDistanceMeters := 0
+	totalDurationSeconds := 0
+	totalServiceSeconds := 0
+	totalBreakSeconds := 0
+
+	for i, v := range visits {
+		d := v.destination
+		leg, ok := distances[currentLocation+"|"+d]
+		if !ok {
+			return nil, fmt.Errorf("plan route: missing distance result from %q to %q", currentLocation, d)
+		}
+
+		currentTime = currentTime.Add(time.Duration(leg.DurationSeconds) * time.Second)
+		totalDurationSeconds += leg.DurationSeconds
+		totalDistanceMeters += leg.DistanceMeters
+		arriveAt := currentTime
+
+		serviceSeconds := serviceAt[i]
+		currentTime = currentTime.Add(time.Duration(serviceSeconds) * time.Second)
+		totalDurationSeconds += serviceSeconds
+		totalServiceSeconds += serviceSeconds
+		onDuty += leg.DurationSeconds + serviceSeconds
+
+		// Look ahead to the next leg and its service to decide on a break here.
+		next := 0
+		if i+1 < len(visits) {
+			next = distances[d+"|"+visits[i+1].destination].DurationSeconds + serviceAt[i+1]
+		} else if opts.ReturnToStart {
+			next = distances[d+"|"+startLocation].DurationSeconds
+		}
+		restSeconds := 0
+		if breakAfter > 0 && next > 0 && onDuty+next > breakAfter {
+			restSeconds = breakSeconds
+			currentTime = currentTime.Add(time.Duration(restSeconds) * time.Second)
+			totalDurationSeconds += restSeconds
+			totalBreakSeconds += restSeconds
+			onDuty = 0
+		}
+
+		for _, pkg := range v.drops {
+			load = load.Without(pkg)
+		}
+		for _, pkg := range v.pickups {
+			load = load.With(pkg)
+		}
+		peak = peak.Max(load)
 
 		stops = append(
 			stops,
 			domain.RouteStop{
-				Destination: bestDestination,
-				ArriveAt:    currentTime,
-				PackageIDs:  byDestination[bestDestination],
+				Destination:              d,
+				ArriveAt:                 arriveAt,
+				DepartAt:                 currentTime,
+				ServiceSeconds:           serviceSeconds,
+				BreakSeconds:             restSeconds,
+				PackageIDs:               packageIDs(v.drops),
+				PickupIDs:                pickupIDs(v.pickups),
+				OnBoard:                  load,
+				CumulativeDistanceMeters: totalDistanceMeters,
 			},
 		)
-
-		delete(remainingDestinations, bestDestination)
-		currentLocation = bestDestination
+		currentLocation = d
 	}
 
 	// Optionally includes return leg to hub for total route metrics.
-	if returnToStart {
+	if opts.ReturnToStart {
 		back, ok := distances[currentLocation+"|"+startLocation]
 		if !ok {
 			return nil, fmt.Errorf(
@@ -120,9 +395,57 @@ func NearestNeighborRoute(
 
 	return &domain.RoutePlan{
 		TruckID:              truck.TruckID,
+		TruckName:            truck.Name,
+		Load:                 peak,
+		Capacity:             truck.Limits(),
+		StartLocation:        startLocation,
 		DepartAt:             departAt,
 		Stops:                stops,
+		ReturnToStart:        opts.ReturnToStart,
 		TotalDurationSeconds: totalDurationSeconds,
 		TotalDistanceMeters:  totalDistanceMeters,
+		TotalServiceSeconds:  totalServiceSeconds,
+		TotalBreakSeconds:    totalBreakSeconds,
+		TotalCost:            truckCost(truck, totalDistanceMeters, totalDurationSeconds),
 	}, nil
 }
+
+// feasibleVisits reports whether making visits in order picks up every
+// package before delivering it and keeps the load within the truck's limits.
+func feasibleVisits(truck *domain.Truck, visits []visit) bool {
+	onBoard := make(map[*domain.Package]bool, len(truck.Packages))
+	var load domain.Cargo
+	for _, pkg := range truck.Packages {
+		if !truck.PicksUp(pkg) {
+			onBoard[pkg] = true
+			load = load.With(pkg)
+		}
+	}
+
+	for _, v := range visits {
+		for _, pkg := range v.drops {
+			if !onBoard[pkg] {
+				return false
+			}
+			delete(onBoard, pkg)
+			load = load.Without(pkg)
+		}
+		for _, pkg := range v.pickups {
+			onBoard[pkg] = true
+			load = load.With(pkg)
+		}
+		if !truck.Holds(load) {
+			return false
+		}
+	}
+	return true
+}
+
+// pickupIDs lists the IDs of pkgs in order, or nil when there are none, so
+// stops without pickups leave PickupIDs unset.
+func pickupIDs(pkgs []*domain.Package) []int {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	return packageIDs(pkgs)
+}
@@ -5,6 +5,8 @@ import (
 	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/ports"
 	"delivery-route-service/internal/services"
+	"math"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +31,7 @@ func TestNearestNeighbor(t *testing.T) {
 		wantFirstAddress  string
 		wantTotalDistance int
 		wantTotalDuration int
+		wantCumulative    []int
 	}{
 		{
 			name:     "Error when startLocation is empty",
@@ -116,6 +119,7 @@ func TestNearestNeighbor(t *testing.T) {
 			returnToStart:     true,
 			wantTotalDistance: 400,
 			wantTotalDuration: 240,
+			wantCumulative:    []int{100, 200},
 		},
 		{
 			name: "Total distance and duration sum correctly across stops",
@@ -159,7 +163,7 @@ func TestNearestNeighbor(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			plan, err := services.NearestNeighborRoute(context.Background(), tc.truck, tc.departAt, tc.distances, tc.returnToStart)
+			plan, err := services.NearestNeighborRoute(context.Background(), tc.truck, tc.departAt, tc.distances, services.RouteOptions{ReturnToStart: tc.returnToStart})
 
 			if tc.wantErr {
 				if err == nil {
@@ -213,6 +217,181 @@ func TestNearestNeighbor(t *testing.T) {
 					t.Fatalf("expected duration: %d, got: %d", tc.wantTotalDuration, plan.TotalDurationSeconds)
 				}
 			}
+
+			for i, want := range tc.wantCumulative {
+				if got := plan.Stops[i].CumulativeDistanceMeters; got != want {
+					t.Fatalf("stop %d: expected cumulative distance %d, got %d", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNearestNeighborServiceTime(t *testing.T) {
+	slow := 90 * time.Second
+	truck := &domain.Truck{
+		TruckID:       1,
+		Capacity:      3,
+		StartLocation: "HUB",
+		Packages: []*domain.Package{
+			{PackageID: 1, Destination: "DestA"},
+			{PackageID: 2, Destination: "DestA", ServiceTime: &slow},
+			{PackageID: 3, Destination: "DestB"},
+		},
+	}
+	distances := map[string]ports.DistanceResult{
+		"HUB|DestA":   {DistanceMeters: 100, DurationSeconds: 60},
+		"HUB|DestB":   {DistanceMeters: 300, DurationSeconds: 180},
+		"DestA|DestB": {DistanceMeters: 100, DurationSeconds: 60},
+		"DestB|DestA": {DistanceMeters: 100, DurationSeconds: 60},
+		"DestA|HUB":   {DistanceMeters: 100, DurationSeconds: 60},
+		"DestB|HUB":   {DistanceMeters: 200, DurationSeconds: 120},
+	}
+	opts := services.RouteOptions{
+		ReturnToStart: true,
+		ServiceTime: domain.ServiceTime{
+			PerStop:      2 * time.Minute,
+			PerPackage:   30 * time.Second,
+			Destinations: map[string]time.Duration{"DestB": time.Minute},
+		},
+	}
+	depart := time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	plan, err := services.NearestNeighborRoute(context.Background(), truck, depart, distances, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// DestA: 2m per stop + 30s default + 90s override; DestB: the 1m destination override.
+	want := []struct {
+		destination string
+		arrive      string
+		depart      string
+		service     int
+	}{
+		{"DestA", "08:01:00", "08:05:00", 240},
+		{"DestB", "08:06:00", "08:07:00", 60},
+	}
+	if len(plan.Stops) != len(want) {
+		t.Fatalf("expected %d stops, got %d", len(want), len(plan.Stops))
+	}
+	for i, w := range want {
+		s := plan.Stops[i]
+		if s.Destination != w.destination || s.ArriveAt.Format(time.TimeOnly) != w.arrive ||
+			s.DepartAt.Format(time.TimeOnly) != w.depart || s.ServiceSeconds != w.service {
+			t.Fatalf("stop %d: expected %s arrive %s depart %s service %ds, got %s arrive %s depart %s service %ds",
+				i, w.destination, w.arrive, w.depart, w.service,
+				s.Destination, s.ArriveAt.Format(time.TimeOnly), s.DepartAt.Format(time.TimeOnly), s.ServiceSeconds)
+		}
+	}
+
+	if plan.TotalServiceSeconds != 300 || plan.TotalDurationSeconds != 540 {
+		t.Fatalf("expected 300s service within 540s total, got %ds within %ds", plan.TotalServiceSeconds, plan.TotalDurationSeconds)
+	}
+}
+
+func TestNearestNeighborShiftBreaks(t *testing.T) {
+	truck := &domain.Truck{
+		TruckID:       1,
+		Capacity:      2,
+		StartLocation: "HUB",
+		Packages: []*domain.Package{
+			{PackageID: 1, Destination: "DestA"},
+			{PackageID: 2, Destination: "DestB"},
+		},
+		Shift: domain.Shift{BreakAfter: 3 * time.Minute, BreakDuration: 15 * time.Minute},
+	}
+	distances := map[string]ports.DistanceResult{
+		"HUB|DestA":   {DistanceMeters: 100, DurationSeconds: 60},
+		"HUB|DestB":   {DistanceMeters: 300, DurationSeconds: 180},
+		"DestA|DestB": {DistanceMeters: 100, DurationSeconds: 60},
+		"DestB|DestA": {DistanceMeters: 100, DurationSeconds: 60},
+		"DestA|HUB":   {DistanceMeters: 100, DurationSeconds: 60},
+		"DestB|HUB":   {DistanceMeters: 200, DurationSeconds: 120},
+	}
+	depart := time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	plan, err := services.NearestNeighborRoute(context.Background(), truck, depart, distances, services.RouteOptions{ReturnToStart: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Two minutes on duty at DestB; the 2-minute drive home would pass the 3-minute limit.
+	want := []struct {
+		destination string
+		depart      string
+		rest        int
+	}{
+		{"DestA", "08:01:00", 0},
+		{"DestB", "08:17:00", 900},
+	}
+	if len(plan.Stops) != len(want) {
+		t.Fatalf("expected %d stops, got %d", len(want), len(plan.Stops))
+	}
+	for i, w := range want {
+		s := plan.Stops[i]
+		if s.Destination != w.destination || s.DepartAt.Format(time.TimeOnly) != w.depart || s.BreakSeconds != w.rest {
+			t.Fatalf("stop %d: expected %s depart %s break %ds, got %s depart %s break %ds",
+				i, w.destination, w.depart, w.rest, s.Destination, s.DepartAt.Format(time.TimeOnly), s.BreakSeconds)
+		}
+	}
+
+	if plan.TotalBreakSeconds != 900 || plan.TotalDurationSeconds != 1140 {
+		t.Fatalf("expected 900s break within 1140s total, got %ds within %ds", plan.TotalBreakSeconds, plan.TotalDurationSeconds)
+	}
+}
+
+func TestNearestNeighborObjective(t *testing.T) {
+	// Fast is quick to reach on a long highway, Short is close but slow.
+	distances := map[string]ports.DistanceResult{
+		"HUB|Fast":   {DistanceMeters: 5000, DurationSeconds: 60},
+		"HUB|Short":  {DistanceMeters: 1000, DurationSeconds: 300},
+		"Fast|Short": {DistanceMeters: 2000, DurationSeconds: 120},
+		"Short|Fast": {DistanceMeters: 2000, DurationSeconds: 120},
+	}
+
+	tests := []struct {
+		name      string
+		objective services.Objective
+		costPerKm float64
+		wantOrder []string
+		wantCost  float64
+	}{
+		{name: "default minimizes duration", wantOrder: []string{"Fast", "Short"}},
+		{name: "distance", objective: services.ObjectiveDistance, wantOrder: []string{"Short", "Fast"}},
+		// Short: 1 km + 300s at 10/h; Fast: 5 km + 60s.
+		{name: "cost", objective: services.ObjectiveCost, costPerKm: 1, wantOrder: []string{"Short", "Fast"}, wantCost: 3 + 10*420.0/3600},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			truck := &domain.Truck{
+				TruckID:       1,
+				Capacity:      2,
+				StartLocation: "HUB",
+				CostPerKm:     tc.costPerKm,
+				CostPerHour:   10,
+				Packages: []*domain.Package{
+					{PackageID: 1, Destination: "Fast"},
+					{PackageID: 2, Destination: "Short"},
+				},
+			}
+
+			plan, err := services.NearestNeighborRoute(context.Background(), truck, time.Time{}, distances, services.RouteOptions{Objective: tc.objective})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var order []string
+			for _, s := range plan.Stops {
+				order = append(order, s.Destination)
+			}
+			if !slices.Equal(order, tc.wantOrder) {
+				t.Fatalf("expected order %v, got %v", tc.wantOrder, order)
+			}
+			if tc.wantCost != 0 && math.Abs(plan.TotalCost-tc.wantCost) > 1e-9 {
+				t.Fatalf("expected cost %g, got %g", tc.wantCost, plan.TotalCost)
+			}
 		})
 	}
 }
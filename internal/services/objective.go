@@ -0,0 +1,129 @@
+package services
+
+import (
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"math"
+	"slices"
+	"time"
+)
+
+// Objective selects what planning minimizes, at every stage: which
+// destinations count as nearest to a hub when assigning them to trucks, the
+// greedy step that orders each truck's stops and the 2-opt refinement.
+type Objective string
+
+const (
+	// Total travel duration of all routes.
+	ObjectiveDuration Objective = "duration"
+	// Total distance driven.
+	ObjectiveDistance Objective = "distance"
+	// Money spent, from each truck's CostPerKm and CostPerHour.
+	ObjectiveCost Objective = "cost"
+	// The time the last truck finishes. Destinations go to trucks with
+	// AssignPackagesByMakespan, and each truck finishes sooner the shorter
+	// its route, so stops are chosen by travel duration.
+	ObjectiveMakespan Objective = "makespan"
+)
+
+// Objectives lists the accepted objectives, default first.
+var Objectives = []Objective{ObjectiveDuration, ObjectiveDistance, ObjectiveCost, ObjectiveMakespan}
+
+// legCost is what driving leg costs truck under o. Costs of different
+// objectives are in different units and only compare among themselves.
+func (o Objective) legCost(truck *domain.Truck, leg ports.DistanceResult) float64 {
+	switch o {
+	case ObjectiveDistance:
+		return float64(leg.DistanceMeters)
+	case ObjectiveCost:
+		return truckCost(truck, leg.DistanceMeters, leg.DurationSeconds)
+	}
+	return float64(leg.DurationSeconds)
+}
+
+// truckCost is what truck costs to run for meters over seconds.
+func truckCost(truck *domain.Truck, meters, seconds int) float64 {
+	return truck.CostPerKm*float64(meters)/1000 + truck.CostPerHour*float64(seconds)/3600
+}
+
+// cheapestLegCost is what driving leg costs under o in the cheapest of
+// trucks, so a group of trucks costs the same whatever their order.
+func (o Objective) cheapestLegCost(trucks []*domain.Truck, leg ports.DistanceResult) float64 {
+	cheapest := math.Inf(1)
+	for _, t := range trucks {
+		cheapest = min(cheapest, o.legCost(t, leg))
+	}
+	return cheapest
+}
+
+// sortByHubCost orders destinations cheapest to reach from the hub first
+// under objective, in the cheapest of trucks, by name on ties.
+func sortByHubCost(
+	destinations []string,
+	distances map[string]ports.DistanceResult,
+	objective Objective,
+	trucks []*domain.Truck,
+) {
+	slices.SortFunc(destinations, func(a, b string) int {
+		ca := objective.cheapestLegCost(trucks, distances[a])
+		cb := objective.cheapestLegCost(trucks, distances[b])
+		if ca < cb {
+			return -1
+		}
+		if ca > cb {
+			return 1
+		}
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	})
+}
+
+// PlanMetrics totals a planning run in every objective, whichever one it
+// minimized.
+type PlanMetrics struct {
+	TotalDistanceMeters  int
+	TotalDurationSeconds int
+	TotalCost            float64
+	// When the last route ends, and how long after the run's departure.
+	FinishAt        time.Time
+	MakespanSeconds int
+	// Spread of route durations across the trucks with a route; a truck's
+	// trips count together.
+	MinRouteDurationSeconds    int
+	MaxRouteDurationSeconds    int
+	RouteDurationStdDevSeconds float64
+}
+
+// MetricsOf totals plans, timing the makespan from departAt.
+func MetricsOf(plans []*domain.RoutePlan, departAt time.Time) PlanMetrics {
+	var m PlanMetrics
+	var trucks []int
+	byTruck := make(map[int]int)
+	for _, p := range plans {
+		if _, ok := byTruck[p.TruckID]; !ok {
+			trucks = append(trucks, p.TruckID)
+		}
+		byTruck[p.TruckID] += p.TotalDurationSeconds
+		m.TotalDistanceMeters += p.TotalDistanceMeters
+		m.TotalDurationSeconds += p.TotalDurationSeconds
+		m.TotalCost += p.TotalCost
+		if end := p.DepartAt.Add(time.Duration(p.TotalDurationSeconds) * time.Second); end.After(m.FinishAt) {
+			m.FinishAt = end
+		}
+	}
+	if !m.FinishAt.IsZero() {
+		m.MakespanSeconds = int(m.FinishAt.Sub(departAt) / time.Second)
+	}
+
+	durations := make([]int, 0, len(trucks))
+	for _, id := range trucks {
+		durations = append(durations, byTruck[id])
+	}
+	m.MinRouteDurationSeconds, m.MaxRouteDurationSeconds, m.RouteDurationStdDevSeconds = durationSpread(durations)
+	return m
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/ports"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,29 +19,249 @@ type pairwiseResult struct {
 	err     error
 }
 
+// defaultConcurrency bounds pairwise fetching when the request leaves Concurrency unset.
+const defaultConcurrency = 5
+
 type PlanDeliveriesRequest struct {
 	Hub           string
 	TruckCount    int
 	TruckCapacity int
+	// Weight and volume limits of the TruckCount trucks; zero means no limit.
+	TruckMaxWeightKg float64
+	TruckMaxVolumeM3 float64
+	// Fleet trucks to plan for, each with its own capacity and start location.
+	// When set, TruckCount and the TruckCapacity and limits are ignored; a truck without a
+	// StartLocation starts at Hub.
+	Trucks        []*domain.Truck
 	DepartAt      time.Time
 	ReturnToStart bool
+	// Maximum concurrent distance lookups; zero uses defaultConcurrency.
+	Concurrency int
+	// Attach road geometry and turn-by-turn directions to each plan.
+	// Requires a provider implementing ports.DirectionsProvider.
+	IncludeGeometry bool
+	// Stop ordering per truck; empty uses RouteStrategyNearestNeighbor.
+	Strategy RouteStrategy
+	// What assignment and routing minimize; empty uses ObjectiveDuration.
+	// ObjectiveCost needs fleet Trucks with a cost rate.
+	Objective Objective
+	// Even out the trucks' workloads after assignment; the zero value does
+	// not. Cannot be combined with MultiTrip.
+	Balance Balance
+	// Time spent at each stop; the zero value assumes instant hand-over.
+	ServiceTime domain.ServiceTime
+	// Driver shift rules applied to every truck; the zero value imposes none.
+	// With a MaxDuration, destinations no truck can fit are reported in
+	// PlanDeliveriesResult.Unassigned instead of being planned.
+	Shift domain.Shift
+	// Let each truck run several trips, returning to its start location and
+	// spending ReloadTime there before the next one, until its shift ends.
+	MultiTrip  bool
+	ReloadTime time.Duration
+	// Default promises of priority service levels, counted from DepartAt.
+	// Packages with a promise are routed to keep it where they can, and those
+	// the plan misses are reported in PlanDeliveriesResult.MissedPromises.
+	Promises domain.Promises
 }
 
 // validateRequest checks that required fields in PlanDeliveriesRequest are valid.
 func validateRequest(req PlanDeliveriesRequest) error {
 	if req.Hub == "" {
-		return fmt.Errorf("plan deliveries: hub address must not be empty")
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "hub",
+			Reason: "hub address must not be empty",
+		})
+	}
+	if len(req.Trucks) > 0 {
+		if err := validateTrucks(req.Trucks); err != nil {
+			return fmt.Errorf("plan deliveries: %w", err)
+		}
+	} else {
+		if req.TruckCount <= 0 {
+			return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:  "truck_count",
+				Reason: fmt.Sprintf("truck count must be positive, got %d", req.TruckCount),
+			})
+		}
+		if req.TruckCapacity <= 0 {
+			return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:  "truck_capacity",
+				Reason: fmt.Sprintf("truck capacity must be positive, got %d", req.TruckCapacity),
+			})
+		}
+		if req.TruckMaxWeightKg < 0 {
+			return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:  "truck_max_weight_kg",
+				Reason: fmt.Sprintf("truck weight limit must not be negative, got %g", req.TruckMaxWeightKg),
+			})
+		}
+		if req.TruckMaxVolumeM3 < 0 {
+			return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:  "truck_max_volume_m3",
+				Reason: fmt.Sprintf("truck volume limit must not be negative, got %g", req.TruckMaxVolumeM3),
+			})
+		}
+	}
+	if req.Concurrency < 0 {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "concurrency",
+			Reason: fmt.Sprintf("concurrency must not be negative, got %d", req.Concurrency),
+		})
+	}
+	if req.Strategy != "" && !slices.Contains(RouteStrategies, req.Strategy) {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "strategy",
+			Reason: fmt.Sprintf("strategy must be one of %q, %q, got %q", RouteStrategyNearestNeighbor, RouteStrategyTwoOpt, req.Strategy),
+		})
+	}
+	if err := validateObjective(req); err != nil {
+		return fmt.Errorf("plan deliveries: %w", err)
+	}
+	if err := validateBalance(req.Balance); err != nil {
+		return fmt.Errorf("plan deliveries: %w", err)
+	}
+	if req.Balance.Mode != "" && req.MultiTrip {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "balance",
+			Reason: "balance cannot be combined with multi_trip",
+		})
+	}
+	if err := validateServiceTime(req.ServiceTime); err != nil {
+		return fmt.Errorf("plan deliveries: %w", err)
+	}
+	if err := validateShift(req.Shift); err != nil {
+		return fmt.Errorf("plan deliveries: %w", err)
+	}
+	if req.Promises.SameDay < 0 || req.Promises.Express < 0 {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field: "promises",
+			Reason: fmt.Sprintf("promise windows must not be negative, got %v same day and %v express",
+				req.Promises.SameDay, req.Promises.Express),
+		})
 	}
-	if req.TruckCount <= 0 {
-		return fmt.Errorf("plan deliveries: truck count must be positive, got %d", req.TruckCount)
+	if req.ReloadTime < 0 {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "reload_seconds",
+			Reason: fmt.Sprintf("reload time must not be negative, got %v", req.ReloadTime),
+		})
 	}
-	if req.TruckCapacity <= 0 {
-		return fmt.Errorf("plan deliveries: truck capacity must be positive, got %d", req.TruckCapacity)
+	return nil
+}
+
+// validateObjective rejects unknown objectives, and the cost objective for
+// trucks that have no cost rate to minimize.
+func validateObjective(req PlanDeliveriesRequest) error {
+	if req.Objective != "" && !slices.Contains(Objectives, req.Objective) {
+		return &domain.ValidationError{
+			Field: "objective",
+			Reason: fmt.Sprintf("objective must be one of %q, %q, %q, %q, got %q",
+				ObjectiveDuration, ObjectiveDistance, ObjectiveCost, ObjectiveMakespan, req.Objective),
+		}
+	}
+	if req.Objective != ObjectiveCost {
+		return nil
+	}
+	if len(req.Trucks) == 0 {
+		return &domain.ValidationError{
+			Field:  "objective",
+			Reason: "the cost objective needs fleet trucks with cost rates",
+		}
+	}
+	for _, t := range req.Trucks {
+		if t.CostPerKm <= 0 && t.CostPerHour <= 0 {
+			return &domain.ValidationError{
+				Field:  "objective",
+				Reason: fmt.Sprintf("the cost objective needs a cost rate for truck %d", t.TruckID),
+			}
+		}
+	}
+	return nil
+}
+
+// validateShift rejects negative limits and break rules without a break length.
+func validateShift(s domain.Shift) error {
+	if s.MaxDuration < 0 || s.BreakAfter < 0 || s.BreakDuration < 0 {
+		return &domain.ValidationError{
+			Field: "shift",
+			Reason: fmt.Sprintf("shift limits must not be negative, got max %v, break after %v, break %v",
+				s.MaxDuration, s.BreakAfter, s.BreakDuration),
+		}
+	}
+	if s.BreakAfter > 0 && s.BreakDuration == 0 {
+		return &domain.ValidationError{
+			Field:  "shift",
+			Reason: "break duration must be positive when break after is set",
+		}
+	}
+	return nil
+}
+
+// validateTrucks rejects fleets with duplicate IDs and trucks that cannot carry anything.
+func validateTrucks(trucks []*domain.Truck) error {
+	seen := make(map[int]bool, len(trucks))
+	for _, t := range trucks {
+		if seen[t.TruckID] {
+			return &domain.ValidationError{
+				Field:  "trucks",
+				Reason: fmt.Sprintf("truck %d is listed more than once", t.TruckID),
+			}
+		}
+		seen[t.TruckID] = true
+		if t.Capacity <= 0 {
+			return &domain.ValidationError{
+				Field:  "trucks",
+				Reason: fmt.Sprintf("truck %d capacity must be positive, got %d", t.TruckID, t.Capacity),
+			}
+		}
+		if t.MaxWeightKg < 0 || t.MaxVolumeM3 < 0 {
+			return &domain.ValidationError{
+				Field: "trucks",
+				Reason: fmt.Sprintf("truck %d weight and volume limits must not be negative, got %g kg and %g m3",
+					t.TruckID, t.MaxWeightKg, t.MaxVolumeM3),
+			}
+		}
+		if !t.Available {
+			return &domain.ValidationError{
+				Field:  "trucks",
+				Reason: fmt.Sprintf("truck %d is not available", t.TruckID),
+			}
+		}
+	}
+	return nil
+}
+
+// validateServiceTime rejects negative service times, which would move ETAs backwards.
+func validateServiceTime(st domain.ServiceTime) error {
+	if st.PerStop < 0 || st.PerPackage < 0 {
+		return &domain.ValidationError{
+			Field:  "service_time",
+			Reason: fmt.Sprintf("service time must not be negative, got %v per stop and %v per package", st.PerStop, st.PerPackage),
+		}
+	}
+	for d, v := range st.Destinations {
+		if v < 0 {
+			return &domain.ValidationError{
+				Field:  "service_time",
+				Reason: fmt.Sprintf("service time for %q must not be negative, got %v", d, v),
+			}
+		}
+	}
+	return nil
+}
+
+// validateProvider checks that provider supports the optional steps req asks for.
+func validateProvider(req PlanDeliveriesRequest, provider ports.DistanceProvider) error {
+	if _, ok := provider.(ports.DirectionsProvider); req.IncludeGeometry && !ok {
+		return fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+			Field:  "include_geometry",
+			Reason: "route geometry is not supported by the configured distance provider",
+		})
 	}
 	return nil
 }
 
 // loadPackages fetches all packages from the repository and groups them by destinations.
+// Packages given only by position are grouped under their "lat,lon" label.
 // Returns an empty map and nil error if no packages exit.
 func loadPackages(
 	ctx context.Context,
@@ -52,11 +275,36 @@ func loadPackages(
 	pkgDest = make(map[string][]*domain.Package)
 	for _, pkg := range pkgs {
 		d := strings.TrimSpace(pkg.Destination)
+		if d == "" && pkg.Coordinates != nil {
+			d = pkg.Coordinates.String()
+		}
 		if d == "" {
-			return nil, nil, fmt.Errorf(
-				"plan deliveries: package_id=%d has empty destination",
-				pkg.PackageID,
-			)
+			return nil, nil, fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:     "destination",
+				PackageID: pkg.PackageID,
+				Reason:    "destination must not be empty",
+			})
+		}
+		if pickup := strings.Join(strings.Fields(pkg.Pickup), " "); pickup != "" && pickup == strings.Join(strings.Fields(d), " ") {
+			return nil, nil, fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:     "pickup",
+				PackageID: pkg.PackageID,
+				Reason:    "pickup must differ from the destination",
+			})
+		}
+		if pkg.WeightKg < 0 {
+			return nil, nil, fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:     "weight_kg",
+				PackageID: pkg.PackageID,
+				Reason:    fmt.Sprintf("weight must not be negative, got %g kg", pkg.WeightKg),
+			})
+		}
+		if pkg.VolumeM3 < 0 {
+			return nil, nil, fmt.Errorf("plan deliveries: %w", &domain.ValidationError{
+				Field:     "volume_m3",
+				PackageID: pkg.PackageID,
+				Reason:    fmt.Sprintf("volume must not be negative, got %g m3", pkg.VolumeM3),
+			})
 		}
 		pkgDest[d] = append(pkgDest[d], pkg)
 	}
@@ -69,6 +317,93 @@ func loadPackages(
 	return pkgDest, destinations, nil
 }
 
+// planTrucks builds empty trucks for the run: copies of req.Trucks starting
+// at Hub unless they have a home hub, or TruckCount identical trucks at Hub.
+func planTrucks(req PlanDeliveriesRequest) []*domain.Truck {
+	if len(req.Trucks) == 0 {
+		trucks := make([]*domain.Truck, 0, req.TruckCount)
+		for i := 0; i < req.TruckCount; i++ {
+			truck := domain.NewTruck(i+1, req.TruckCapacity, req.Hub)
+			truck.MaxWeightKg = req.TruckMaxWeightKg
+			truck.MaxVolumeM3 = req.TruckMaxVolumeM3
+			truck.Shift = req.Shift
+			trucks = append(trucks, truck)
+		}
+		return trucks
+	}
+
+	trucks := make([]*domain.Truck, 0, len(req.Trucks))
+	for _, t := range req.Trucks {
+		truck := *t
+		truck.StartLocation = strings.Join(strings.Fields(truck.StartLocation), " ")
+		if truck.StartLocation == "" {
+			truck.StartLocation = req.Hub
+		}
+		truck.Packages = nil
+		truck.Shift = req.Shift
+		trucks = append(trucks, &truck)
+	}
+	return trucks
+}
+
+// startLocations lists the distinct start locations of trucks in first-seen order.
+func startLocations(trucks []*domain.Truck) []string {
+	var starts []string
+	for _, t := range trucks {
+		if !slices.Contains(starts, t.StartLocation) {
+			starts = append(starts, t.StartLocation)
+		}
+	}
+	return starts
+}
+
+// uniformFleet reports whether all trucks share a start location and capacity
+// in every dimension.
+func uniformFleet(trucks []*domain.Truck) bool {
+	for _, t := range trucks[1:] {
+		if t.StartLocation != trucks[0].StartLocation || t.Limits() != trucks[0].Limits() {
+			return false
+		}
+	}
+	return true
+}
+
+// knownCoordinates collects coordinates already stored on packages, keyed by destination.
+func knownCoordinates(pkgDest map[string][]*domain.Package) map[string]domain.Coordinates {
+	known := make(map[string]domain.Coordinates)
+	for d, pkgs := range pkgDest {
+		for _, pkg := range pkgs {
+			if pkg.Coordinates != nil {
+				known[d] = *pkg.Coordinates
+				break
+			}
+		}
+	}
+	return known
+}
+
+// attachCoordinates records the resolved position of each plan's start and
+// stops. "lat,lon" labels are parsed directly; anything else not in known is
+// left nil.
+func attachCoordinates(plans []*domain.RoutePlan, known map[string]domain.Coordinates) {
+	lookup := func(address string) *domain.Coordinates {
+		if c, ok := known[address]; ok {
+			return &c
+		}
+		if c, ok := domain.ParseCoordinates(address); ok {
+			return &c
+		}
+		return nil
+	}
+
+	for _, p := range plans {
+		p.StartCoordinates = lookup(p.StartLocation)
+		for i := range p.Stops {
+			p.Stops[i].Coordinates = lookup(p.Stops[i].Destination)
+		}
+	}
+}
+
 // fetchHubDistances retrives travel distances from the hub to all destinations.
 // Uses batched lookup when the provider supports it.
 func fetchHubDistances(
@@ -136,15 +471,17 @@ func fetchDistancesFromOrigin(
 // the pairwise distance map. Seeds hub→destination distances before collecting.
 func collectPairwiseResults(
 	resultsCh <-chan pairwiseResult,
-	hub string,
-	hubAndDests []string,
-	hubDistances map[string]ports.DistanceResult,
+	hubs []string,
+	hubsAndDests []string,
+	hubDistances map[string]map[string]ports.DistanceResult,
 ) (pairwiseDist map[string]ports.DistanceResult, err error) {
 	pairwiseDist = make(map[string]ports.DistanceResult)
 	// Seeds pairwiseDist with already fetched distances (Hub → destination).
-	for _, d := range hubAndDests {
-		if d != hub {
-			pairwiseDist[hub+"|"+d] = hubDistances[d]
+	for _, hub := range hubs {
+		for d, r := range hubDistances[hub] {
+			if d != hub {
+				pairwiseDist[hub+"|"+d] = r
+			}
 		}
 	}
 
@@ -155,7 +492,7 @@ func collectPairwiseResults(
 			}
 			continue
 		}
-		for _, t := range hubAndDests {
+		for _, t := range hubsAndDests {
 			if t != res.origin {
 				r, ok := res.results[t]
 				if !ok {
@@ -172,27 +509,29 @@ func collectPairwiseResults(
 }
 
 // fetchPairwiseDistances fetches distances between all destination pairs concurrently.
-// Uses a bounded goroutine pool (semaphore size 5) to limit concurrent ORS calls.
-// Hub→destination distances are seeded from the already-fetched distances map.
+// Uses a bounded goroutine pool (semaphore of size concurrency) to limit concurrent ORS calls.
+// Hub→destination distances are seeded from the already-fetched hubDistances,
+// keyed by hub; every destination also gets distances back to each hub.
 func fetchPairwiseDistances(
 	ctx context.Context,
-	hub string,
+	hubs []string,
 	destinations []string,
-	distances map[string]ports.DistanceResult,
+	hubDistances map[string]map[string]ports.DistanceResult,
 	provider ports.DistanceProvider,
+	concurrency int,
 ) (pairwiseDist map[string]ports.DistanceResult, err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	sem := make(chan struct{}, 5)
+	sem := make(chan struct{}, concurrency)
 	resultsCh := make(chan pairwiseResult, len(destinations))
 	var wg sync.WaitGroup
 
-	// Each destination → all other destinations and hub.
-	hubAndDests := append([]string{hub}, destinations...)
+	// Each destination → all other destinations and hubs.
+	hubsAndDests := append(slices.Clone(hubs), destinations...)
 	for _, origin := range destinations {
-		targets := make([]string, 0, len(hubAndDests)-1)
-		for _, t := range hubAndDests {
+		targets := make([]string, 0, len(hubsAndDests)-1)
+		for _, t := range hubsAndDests {
 			if t != origin {
 				targets = append(targets, t)
 			}
@@ -217,7 +556,7 @@ func fetchPairwiseDistances(
 
 	// Build pairwiseDist: "origin|destination" → DistanceResult for all pairs
 	// needed by the nearest-neighbor route planner.
-	pairwiseDist, err = collectPairwiseResults(resultsCh, hub, hubAndDests, distances)
+	pairwiseDist, err = collectPairwiseResults(resultsCh, hubs, hubsAndDests, hubDistances)
 	if err != nil {
 		return nil, err
 	}
@@ -225,6 +564,19 @@ func fetchPairwiseDistances(
 	return pairwiseDist, nil
 }
 
+// attachPackageIDs records which packages use an ungeocodable address so
+// callers can report the affected packages, not just the address string.
+func attachPackageIDs(err error, pkgDest map[string][]*domain.Package) error {
+	var addrErr *ports.AddressError
+	if !errors.As(err, &addrErr) || len(addrErr.PackageIDs) > 0 {
+		return err
+	}
+	for _, pkg := range pkgDest[addrErr.Address] {
+		addrErr.PackageIDs = append(addrErr.PackageIDs, pkg.PackageID)
+	}
+	return err
+}
+
 // planRoutes computes a route plan per truck.
 // Only trucks with assigned packages are included in the returned plans.
 func planRoutes(
@@ -233,13 +585,15 @@ func planRoutes(
 	pairwiseDist map[string]ports.DistanceResult,
 	trucks []*domain.Truck,
 ) (plans []*domain.RoutePlan, err error) {
+	route, name := req.Strategy.router()
+	opts := routeOptions(req)
 
 	// Compute and apply a route plan per truck
 	plans = make([]*domain.RoutePlan, 0, len(trucks))
 	for _, truck := range trucks {
-		plan, err := NearestNeighborRoute(ctx, truck, req.DepartAt, pairwiseDist, req.ReturnToStart)
+		plan, err := route(ctx, truck, req.DepartAt, pairwiseDist, opts)
 		if err != nil {
-			return nil, fmt.Errorf("plan deliveries: plan nearest neighbor route: %w", err)
+			return nil, fmt.Errorf("plan deliveries: plan %s route: %w", name, err)
 		}
 		if len(plan.Stops) > 0 {
 			plans = append(plans, plan)
@@ -249,48 +603,485 @@ func planRoutes(
 	return plans, nil
 }
 
-// PlanDeliveries orchestrates the full route planning workflow.
-// It loads packages, fetches distances, assigns packages to trucks,
-// and computes a nearest-neighbor route plan for each truck.
-// Only trucks with assigned packages are included in the returned plans.
+// routeOptions collects the per-route settings of req.
+func routeOptions(req PlanDeliveriesRequest) RouteOptions {
+	return RouteOptions{
+		ReturnToStart: req.ReturnToStart,
+		ServiceTime:   normalizeServiceTime(req.ServiceTime),
+		Promises:      req.Promises,
+		PromiseStart:  req.DepartAt,
+		Objective:     req.Objective,
+	}
+}
+
+// shiftFit reports whether a truck's route, planned as planRoutes would,
+// stays within the truck's maximum shift duration.
+func shiftFit(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	pairwiseDist map[string]ports.DistanceResult,
+) func(*domain.Truck) (bool, error) {
+	route, name := req.Strategy.router()
+	opts := routeOptions(req)
+	return func(truck *domain.Truck) (bool, error) {
+		if truck.Shift.MaxDuration <= 0 {
+			return true, nil
+		}
+		plan, err := route(ctx, truck, req.DepartAt, pairwiseDist, opts)
+		if err != nil {
+			return false, fmt.Errorf("plan %s route: %w", name, err)
+		}
+		return time.Duration(plan.TotalDurationSeconds)*time.Second <= truck.Shift.MaxDuration, nil
+	}
+}
+
+// routeFinish reports when a truck's route, planned as planRoutes would,
+// ends.
+func routeFinish(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	pairwiseDist map[string]ports.DistanceResult,
+) func(*domain.Truck) (time.Time, error) {
+	route, name := req.Strategy.router()
+	opts := routeOptions(req)
+	return func(truck *domain.Truck) (time.Time, error) {
+		plan, err := route(ctx, truck, req.DepartAt, pairwiseDist, opts)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("plan %s route: %w", name, err)
+		}
+		return plan.DepartAt.Add(time.Duration(plan.TotalDurationSeconds) * time.Second), nil
+	}
+}
+
+// anyFit accepts every truck, for assignment without a shift limit.
+func anyFit(*domain.Truck) (bool, error) { return true, nil }
+
+// normalizeServiceTime collapses whitespace in destination overrides so they
+// match destinations however the caller spaced them.
+func normalizeServiceTime(st domain.ServiceTime) domain.ServiceTime {
+	if len(st.Destinations) == 0 {
+		return st
+	}
+	byDest := make(map[string]time.Duration, len(st.Destinations))
+	for d, v := range st.Destinations {
+		byDest[strings.Join(strings.Fields(d), " ")] = v
+	}
+	st.Destinations = byDest
+	return st
+}
+
+// PlanDeliveriesResult is the outcome of a planning run.
+type PlanDeliveriesResult struct {
+	// Route plans for trucks with at least one assigned package.
+	Plans []*domain.RoutePlan
+	// Destinations the provider could not locate; their packages were left out of Plans.
+	Ungeocodable []UngeocodableDestination
+	// Destinations no truck could deliver within its shift or capacity.
+	Unassigned []UnassignedDestination
+	// Packages with a promise the plans do not keep.
+	MissedPromises []MissedPromise
+	// Totals of Plans in every objective.
+	Metrics PlanMetrics
+}
+
+// MissedPromise reports a package planned to arrive after its deadline, or
+// left out of the plan altogether.
+type MissedPromise struct {
+	PackageID    int
+	ServiceLevel domain.ServiceLevel
+	Deadline     time.Time
+	// The truck and trip delivering the package and its planned arrival;
+	// zero when the package was left out.
+	TruckID  int
+	Trip     int
+	ArriveAt time.Time
+}
+
+// missedPromises lists, by package ID, the packages of pkgs whose promise
+// plans miss, by arriving late or not at all. Deadlines count from start.
+func missedPromises(
+	pkgs []*domain.Package,
+	plans []*domain.RoutePlan,
+	promises domain.Promises,
+	start time.Time,
+) []MissedPromise {
+	type delivery struct {
+		plan     *domain.RoutePlan
+		arriveAt time.Time
+	}
+	delivered := make(map[int]delivery)
+	for _, p := range plans {
+		for _, s := range p.Stops {
+			for _, id := range s.PackageIDs {
+				delivered[id] = delivery{plan: p, arriveAt: s.ArriveAt}
+			}
+		}
+	}
+
+	var missed []MissedPromise
+	for _, pkg := range pkgs {
+		deadline, ok := promises.DeadlineOf(pkg, start)
+		if !ok {
+			continue
+		}
+		m := MissedPromise{PackageID: pkg.PackageID, ServiceLevel: pkg.ServiceLevel, Deadline: deadline}
+		if d, ok := delivered[pkg.PackageID]; ok {
+			if !d.arriveAt.After(deadline) {
+				continue
+			}
+			m.TruckID, m.Trip, m.ArriveAt = d.plan.TruckID, d.plan.Trip, d.arriveAt
+		}
+		missed = append(missed, m)
+	}
+	slices.SortFunc(missed, func(a, b MissedPromise) int { return a.PackageID - b.PackageID })
+	return missed
+}
+
+// allPackages lists the packages of pkgDest at destinations.
+func allPackages(pkgDest map[string][]*domain.Package, destinations []string) []*domain.Package {
+	var pkgs []*domain.Package
+	for _, d := range destinations {
+		pkgs = append(pkgs, pkgDest[d]...)
+	}
+	return pkgs
+}
+
+// UngeocodableDestination reports a destination skipped during planning.
+type UngeocodableDestination struct {
+	Address    string
+	Reason     string
+	PackageIDs []int
+}
+
+// resolveLocations geocodes the hubs and every destination without stored
+// coordinates, for providers that support it. Destinations that cannot be
+// located are dropped so the rest of the plan can proceed; an ungeocodable hub
+// is an error since no route can start there. Freshly resolved coordinates are
+// added to known and written back to the packages when the repository supports it.
+func resolveLocations(
+	ctx context.Context,
+	hubs []string,
+	pkgDest map[string][]*domain.Package,
+	destinations []string,
+	known map[string]domain.Coordinates,
+	repo ports.PackageRepository,
+	provider ports.DistanceProvider,
+) (kept []string, skipped []UngeocodableDestination, err error) {
+	geocoder, ok := provider.(ports.Geocoder)
+	if !ok {
+		return destinations, nil, nil
+	}
+
+	lookups := slices.Clone(hubs)
+	for _, d := range destinations {
+		if _, ok := known[d]; !ok {
+			lookups = append(lookups, d)
+		}
+	}
+
+	coords, failures, err := geocoder.GeocodeAddresses(ctx, lookups)
+	if err != nil {
+		return nil, nil, fmt.Errorf("plan deliveries: geocode addresses: %w", err)
+	}
+	for _, hub := range hubs {
+		if reason, ok := failures[hub]; ok {
+			return nil, nil, fmt.Errorf("plan deliveries: hub: %w", &ports.AddressError{Address: hub, Reason: reason})
+		}
+	}
+
+	fresh := make(map[int]domain.Coordinates)
+	kept = make([]string, 0, len(destinations))
+	for _, d := range destinations {
+		if reason, ok := failures[d]; ok {
+			ids := make([]int, 0, len(pkgDest[d]))
+			for _, pkg := range pkgDest[d] {
+				ids = append(ids, pkg.PackageID)
+			}
+			skipped = append(skipped, UngeocodableDestination{Address: d, Reason: reason, PackageIDs: ids})
+			delete(pkgDest, d)
+			continue
+		}
+
+		kept = append(kept, d)
+		if _, ok := known[d]; ok {
+			continue
+		}
+		if c, ok := coords[d]; ok {
+			known[d] = c
+			for _, pkg := range pkgDest[d] {
+				fresh[pkg.PackageID] = c
+			}
+		}
+	}
+	for _, hub := range hubs {
+		if c, ok := coords[hub]; ok {
+			known[hub] = c
+		}
+	}
+	slices.SortFunc(skipped, func(a, b UngeocodableDestination) int { return strings.Compare(a.Address, b.Address) })
+
+	// Persisting is an optimization for later runs; a failure must not fail this plan.
+	if w, ok := repo.(ports.PackageCoordinateWriter); ok && len(fresh) > 0 {
+		if err := w.SetPackageCoordinates(ctx, fresh); err != nil {
+			log.Printf("plan deliveries: store package coordinates failed: %v", err)
+		}
+	}
+
+	return kept, skipped, nil
+}
+
+// resolvePickups locates the pickup addresses of the packages in pkgDest that
+// are neither destinations nor hubs, for providers that support it. Packages
+// whose pickup cannot be located are dropped and reported like ungeocodable
+// destinations, and destinations left without packages are removed. Pickup
+// coordinates are added to known but never stored on the packages, whose
+// coordinates are those of their destination.
+func resolvePickups(
+	ctx context.Context,
+	hubs []string,
+	pkgDest map[string][]*domain.Package,
+	destinations []string,
+	known map[string]domain.Coordinates,
+	provider ports.DistanceProvider,
+) (kept []string, pickups []string, skipped []UngeocodableDestination, err error) {
+	byPickup := make(map[string][]*domain.Package)
+	for _, d := range destinations {
+		for _, pkg := range pkgDest[d] {
+			p := strings.Join(strings.Fields(pkg.Pickup), " ")
+			if p == "" || slices.Contains(hubs, p) {
+				continue
+			}
+			if _, ok := pkgDest[p]; ok {
+				continue
+			}
+			if _, ok := byPickup[p]; !ok {
+				pickups = append(pickups, p)
+			}
+			byPickup[p] = append(byPickup[p], pkg)
+		}
+	}
+	if len(pickups) == 0 {
+		return destinations, nil, nil, nil
+	}
+
+	// Without a repository nothing is written back.
+	pickups, skipped, err = resolveLocations(ctx, nil, byPickup, pickups, known, nil, provider)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(skipped) == 0 {
+		return destinations, pickups, nil, nil
+	}
+
+	dropped := make(map[int]bool)
+	for i, s := range skipped {
+		skipped[i].Reason = "pickup: " + s.Reason
+		for _, id := range s.PackageIDs {
+			dropped[id] = true
+		}
+	}
+	kept = make([]string, 0, len(destinations))
+	for _, d := range destinations {
+		pkgDest[d] = slices.DeleteFunc(pkgDest[d], func(p *domain.Package) bool { return dropped[p.PackageID] })
+		if len(pkgDest[d]) == 0 {
+			delete(pkgDest, d)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	return kept, pickups, skipped, nil
+}
+
+// knownCoordinateProvider routes batched lookups through a provider's
+// coordinate-aware path so stored package coordinates are used directly.
+type knownCoordinateProvider struct {
+	ports.CoordinateDistanceProvider
+	known map[string]domain.Coordinates
+}
+
+func (p knownCoordinateProvider) GetDistances(
+	ctx context.Context,
+	origin string,
+	destinations []string,
+) (map[string]ports.DistanceResult, error) {
+	return p.GetDistancesWithCoordinates(ctx, origin, destinations, p.known)
+}
+
+// withKnownCoordinates wraps provider so planning never looks up coordinates
+// it already holds. Providers without coordinate support are returned as-is.
+func withKnownCoordinates(
+	provider ports.DistanceProvider,
+	known map[string]domain.Coordinates,
+) ports.DistanceProvider {
+	cp, ok := provider.(ports.CoordinateDistanceProvider)
+	if !ok || len(known) == 0 {
+		return provider
+	}
+	return knownCoordinateProvider{CoordinateDistanceProvider: cp, known: known}
+}
+
+// PlanDeliveries runs the full route planning workflow.
+//
+// It loads packages, locates their destinations (skipping those the provider
+// cannot find), assigns packages to trucks and plans a route for each truck
+// with packages, minimizing req.Objective throughout. A destination no truck
+// can take fails the plan with a capacity error, unless a shift limit, trips,
+// the makespan objective or a priority package call for reporting it as
+// unassigned instead. Trips and balancing are left to planTrips and
+// rebalance. The result totals the plans in every objective.
 func PlanDeliveries(
 	ctx context.Context,
 	req PlanDeliveriesRequest,
 	repo ports.PackageRepository,
 	provider ports.DistanceProvider,
-) ([]*domain.RoutePlan, error) {
+) (*PlanDeliveriesResult, error) {
 	if err := validateRequest(req); err != nil {
 		return nil, err
 	}
+	if err := validateProvider(req, provider); err != nil {
+		return nil, err
+	}
 
 	pkgDest, destinations, err := loadPackages(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
 
+	return planPackages(ctx, req, pkgDest, destinations, knownCoordinates(pkgDest), repo, provider)
+}
+
+// planPackages is PlanDeliveries once packages are loaded: it plans the
+// packages in pkgDest for the trucks of req, starting from the coordinates
+// in known and adding those it resolves.
+func planPackages(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	pkgDest map[string][]*domain.Package,
+	destinations []string,
+	known map[string]domain.Coordinates,
+	repo ports.PackageRepository,
+	provider ports.DistanceProvider,
+) (*PlanDeliveriesResult, error) {
+	directions, _ := provider.(ports.DirectionsProvider)
+
 	if len(destinations) == 0 {
-		return []*domain.RoutePlan{}, nil
+		return &PlanDeliveriesResult{Plans: []*domain.RoutePlan{}}, nil
 	}
 
-	distances, err := fetchHubDistances(ctx, req.Hub, destinations, provider)
+	trucks := planTrucks(req)
+	hubs := startLocations(trucks)
+	// Every package counts for promises, even those dropped below.
+	pkgs := allPackages(pkgDest, destinations)
+
+	destinations, ungeocodable, err := resolveLocations(ctx, hubs, pkgDest, destinations, known, repo, provider)
+	if err != nil {
+		return nil, err
+	}
+	destinations, pickups, skipped, err := resolvePickups(ctx, hubs, pkgDest, destinations, known, provider)
 	if err != nil {
 		return nil, err
 	}
+	ungeocodable = append(ungeocodable, skipped...)
+	provider = withKnownCoordinates(provider, known)
+
+	if len(destinations) == 0 {
+		return &PlanDeliveriesResult{
+			Plans:          []*domain.RoutePlan{},
+			Ungeocodable:   ungeocodable,
+			MissedPromises: missedPromises(pkgs, nil, req.Promises, req.DepartAt),
+		}, nil
+	}
 
-	trucks := make([]*domain.Truck, 0, req.TruckCount)
-	for i := 0; i < req.TruckCount; i++ {
-		trucks = append(trucks, domain.NewTruck(i+1, req.TruckCapacity, req.Hub))
+	// Routes run between destinations and pickups alike; assignment only
+	// looks at destinations.
+	locations := append(slices.Clone(destinations), pickups...)
+
+	hubDistances := make(map[string]map[string]ports.DistanceResult, len(hubs))
+	for _, hub := range hubs {
+		hubDistances[hub], err = fetchHubDistances(ctx, hub, locations, provider)
+		if err != nil {
+			return nil, attachPackageIDs(err, pkgDest)
+		}
+	}
+	// Without a shift limit, trips or the makespan objective, assign packages
+	// to trucks before computing individual routes so capacity problems
+	// surface before pairwise lookups.
+	var unassigned []UnassignedDestination
+	limited := req.Shift.MaxDuration > 0
+	byRoute := limited || req.Objective == ObjectiveMakespan
+	if !byRoute && !req.MultiTrip {
+		if uniformFleet(trucks) {
+			err = AssignPackagesByDistance(trucks, pkgDest, hubDistances[hubs[0]], destinations, req.Objective)
+		} else {
+			err = AssignPackagesToFleet(trucks, pkgDest, hubDistances, destinations, req.Objective)
+		}
+		// Rather than fail, let standard packages make room for priority ones.
+		var capErr *domain.CapacityError
+		if errors.As(err, &capErr) && slices.ContainsFunc(destinations, func(d string) bool {
+			return destinationRank(pkgDest[d]) > 0
+		}) {
+			for _, t := range trucks {
+				t.Packages = nil
+			}
+			unassigned, err = AssignPackagesByPriority(trucks, pkgDest, hubDistances, destinations, anyFit, req.Objective)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("plan deliveries: assign packages: %w", err)
+		}
 	}
 
-	// Assign packages to trucks before computing individual routes.
-	if err := AssignPackagesByDistance(trucks, pkgDest, distances, destinations); err != nil {
-		return nil, fmt.Errorf("plan deliveries: assign packages: %w", err)
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultConcurrency
 	}
 
-	pairwiseDist, err := fetchPairwiseDistances(ctx, req.Hub, destinations, distances, provider)
+	pairwiseDist, err := fetchPairwiseDistances(ctx, hubs, locations, hubDistances, provider, concurrency)
 	if err != nil {
-		return nil, err
+		return nil, attachPackageIDs(err, pkgDest)
+	}
+
+	// A shift limit, trips and the makespan objective need route durations,
+	// so assignment waits for pairwise distances.
+	var plans []*domain.RoutePlan
+	if req.MultiTrip {
+		plans, unassigned, err = planTrips(ctx, req, trucks, pkgDest, hubDistances, destinations, pairwiseDist)
+		if err != nil {
+			return nil, fmt.Errorf("plan deliveries: %w", err)
+		}
+	} else {
+		fits := anyFit
+		if limited {
+			fits = shiftFit(ctx, req, pairwiseDist)
+		}
+		if byRoute {
+			unassigned, err = assignWithinShift(trucks, pkgDest, hubDistances, destinations, fits, routeFinish(ctx, req, pairwiseDist), req.Objective)
+			if err != nil {
+				return nil, fmt.Errorf("plan deliveries: %w", err)
+			}
+		}
+		if err := rebalance(ctx, req, trucks, pairwiseDist, fits); err != nil {
+			return nil, fmt.Errorf("plan deliveries: %w", err)
+		}
+
+		plans, err = planRoutes(ctx, req, pairwiseDist, trucks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attachCoordinates(plans, known)
+
+	if req.IncludeGeometry {
+		if err := attachDirections(ctx, plans, known, directions, concurrency); err != nil {
+			return nil, attachPackageIDs(err, pkgDest)
+		}
 	}
 
-	return planRoutes(ctx, req, pairwiseDist, trucks)
+	return &PlanDeliveriesResult{
+		Plans:          plans,
+		Ungeocodable:   ungeocodable,
+		Unassigned:     unassigned,
+		MissedPromises: missedPromises(pkgs, plans, req.Promises, req.DepartAt),
+		Metrics:        MetricsOf(plans, req.DepartAt),
+	}, nil
 }
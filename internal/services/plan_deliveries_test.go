@@ -3,11 +3,14 @@ package services_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
 	"delivery-route-service/internal/services"
 	"delivery-route-service/internal/testutil"
 )
@@ -42,8 +45,11 @@ func TestPlanDeliveries(t *testing.T) {
 		repo        *testutil.MockPackageRepository
 		provider    *testutil.MockDistanceProvider
 		wantPlans   int
+		wantSkipped []int
 		wantErr     bool
 		errContains string
+		wantErrIs   error
+		wantField   string
 	}{
 		{
 			name: "empty list when no packages exist",
@@ -72,6 +78,46 @@ func TestPlanDeliveries(t *testing.T) {
 			provider:  testutil.NewMockDistanceProvider(twoDests),
 			wantPlans: 2,
 		},
+		{
+			name: "ungeocodable destinations are skipped and reported",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+			},
+			repo: testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: destA},
+				{PackageID: 2, Destination: "Nowhere"},
+				{PackageID: 3, Destination: "Nowhere"},
+			}, nil),
+			provider: func() *testutil.MockDistanceProvider {
+				p := testutil.NewMockDistanceProvider(twoDests)
+				p.Ungeocodable = map[string]string{"Nowhere": "no geocode results"}
+				return p
+			}(),
+			wantPlans:   1,
+			wantSkipped: []int{2, 3},
+		},
+		{
+			name: "error when hub is not geocodable",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    1,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+			},
+			repo: testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: destA},
+			}, nil),
+			provider: func() *testutil.MockDistanceProvider {
+				p := testutil.NewMockDistanceProvider(twoDests)
+				p.Ungeocodable = map[string]string{hub: "no geocode results"}
+				return p
+			}(),
+			wantErr:   true,
+			wantErrIs: ports.ErrAddressNotGeocodable,
+		},
 		{
 			name: "propagates repo error",
 			req: services.PlanDeliveriesRequest{
@@ -97,6 +143,7 @@ func TestPlanDeliveries(t *testing.T) {
 			provider:    testutil.NewMockDistanceProvider(nil),
 			wantErr:     true,
 			errContains: "hub",
+			wantErrIs:   domain.ErrValidation,
 		},
 		{
 			name: "error when packages exceed total truck capacity",
@@ -113,6 +160,7 @@ func TestPlanDeliveries(t *testing.T) {
 			provider:    testutil.NewMockDistanceProvider(overflowPairs),
 			wantErr:     true,
 			errContains: "capacity",
+			wantErrIs:   domain.ErrCapacityExceeded,
 		},
 		{
 			name: "error when TruckCount is 0",
@@ -166,11 +214,115 @@ func TestPlanDeliveries(t *testing.T) {
 			wantErr:     true,
 			errContains: "truck capacity",
 		},
+		{
+			name: "error when Strategy is unknown",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+				Strategy:      "random",
+			},
+			repo:        testutil.NewMockPackageRepository(nil, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "strategy",
+		},
+		{
+			name: "error when ServiceTime is negative",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+				ServiceTime:   domain.ServiceTime{Destinations: map[string]time.Duration{destA: -time.Minute}},
+			},
+			repo:        testutil.NewMockPackageRepository(nil, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "service time",
+		},
+		{
+			name: "error when a fleet truck is unavailable",
+			req: services.PlanDeliveriesRequest{
+				Hub:      hub,
+				DepartAt: departAt,
+				Trucks:   []*domain.Truck{{TruckID: 7, Name: "Van 7", Capacity: 5}},
+			},
+			repo:        testutil.NewMockPackageRepository(nil, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "truck 7 is not available",
+			wantErrIs:   domain.ErrValidation,
+		},
+		{
+			name: "error when a break rule has no break duration",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+				Shift:         domain.Shift{BreakAfter: 4 * time.Hour},
+			},
+			repo:        testutil.NewMockPackageRepository(nil, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "break duration",
+			wantErrIs:   domain.ErrValidation,
+		},
+		{
+			name: "error names weight_kg when a package weight is negative",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+			},
+			repo: testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: destA, WeightKg: -1},
+			}, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "weight must not be negative",
+			wantField:   "weight_kg",
+		},
+		{
+			name: "error names volume_m3 when a package volume is negative",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+			},
+			repo: testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: destA, WeightKg: 2, VolumeM3: -0.5},
+			}, nil),
+			provider:    testutil.NewMockDistanceProvider(nil),
+			wantErr:     true,
+			errContains: "volume must not be negative",
+			wantField:   "volume_m3",
+		},
+		{
+			name: "2-opt strategy plans every truck",
+			req: services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 5,
+				DepartAt:      departAt,
+				Strategy:      services.RouteStrategyTwoOpt,
+			},
+			repo: testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: destA},
+				{PackageID: 2, Destination: destB},
+			}, nil),
+			provider:  testutil.NewMockDistanceProvider(twoDests),
+			wantPlans: 2,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			plans, err := services.PlanDeliveries(context.Background(), tc.req, tc.repo, tc.provider)
+			result, err := services.PlanDeliveries(context.Background(), tc.req, tc.repo, tc.provider)
 
 			if tc.wantErr {
 				if err == nil {
@@ -179,15 +331,823 @@ func TestPlanDeliveries(t *testing.T) {
 				if tc.errContains != "" && !strings.Contains(err.Error(), tc.errContains) {
 					t.Fatalf("expected error containing %q, got %q", tc.errContains, err.Error())
 				}
+				if tc.wantErrIs != nil && !errors.Is(err, tc.wantErrIs) {
+					t.Fatalf("expected error matching %v, got %v", tc.wantErrIs, err)
+				}
+				if tc.wantField != "" {
+					var vErr *domain.ValidationError
+					if !errors.As(err, &vErr) || vErr.Field != tc.wantField {
+						t.Fatalf("expected validation error on %q, got %v", tc.wantField, err)
+					}
+				}
 				return
 			}
 
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if len(plans) != tc.wantPlans {
-				t.Fatalf("expected %d plans, got %d", tc.wantPlans, len(plans))
+			if len(result.Plans) != tc.wantPlans {
+				t.Fatalf("expected %d plans, got %d", tc.wantPlans, len(result.Plans))
+			}
+
+			var skipped []int
+			for _, u := range result.Ungeocodable {
+				skipped = append(skipped, u.PackageIDs...)
+			}
+			if !slices.Equal(skipped, tc.wantSkipped) {
+				t.Fatalf("expected ungeocodable packages %v, got %v", tc.wantSkipped, skipped)
 			}
 		})
 	}
 }
+
+func TestPlanDeliveriesStoredCoordinates(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "DestB", Meters: 2000, Seconds: 120},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "DestB", Meters: 3000, Seconds: 180},
+		{From: "DestB", To: hub, Meters: 2000, Seconds: 120},
+		{From: "DestB", To: "DestA", Meters: 3000, Seconds: 180},
+	}
+	destA := domain.Coordinates{Lon: -112.1, Lat: 33.4}
+	destB := domain.Coordinates{Lon: -111.9, Lat: 33.5}
+
+	repo := testutil.NewMockPackageRepository([]*domain.Package{
+		{PackageID: 1, Destination: "DestA", Coordinates: &destA},
+		{PackageID: 2, Destination: "DestB"},
+		{PackageID: 3, Destination: "DestB"},
+	}, nil)
+	provider := testutil.NewMockDistanceProvider(pairs)
+	provider.Coordinates = map[string]domain.Coordinates{"DestB": destB}
+
+	req := services.PlanDeliveriesRequest{
+		Hub:           hub,
+		TruckCount:    1,
+		TruckCapacity: 5,
+		DepartAt:      time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+	}
+	if _, err := services.PlanDeliveries(context.Background(), req, repo, provider); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if slices.Contains(provider.Geocoded, "DestA") {
+		t.Fatalf("expected DestA not to be geocoded, geocoded %v", provider.Geocoded)
+	}
+	if !slices.Contains(provider.Geocoded, "DestB") {
+		t.Fatalf("expected DestB to be geocoded, geocoded %v", provider.Geocoded)
+	}
+
+	want := map[int]domain.Coordinates{2: destB, 3: destB}
+	if len(repo.StoredCoordinates) != len(want) {
+		t.Fatalf("expected stored coordinates %v, got %v", want, repo.StoredCoordinates)
+	}
+	for id, c := range want {
+		if repo.StoredCoordinates[id] != c {
+			t.Fatalf("package %d: expected stored %+v, got %+v", id, c, repo.StoredCoordinates[id])
+		}
+	}
+}
+
+func TestPlanDeliveriesFleet(t *testing.T) {
+	// DestA is next to Hub and DestB next to Depot.
+	pairs := []testutil.MockPair{
+		{From: "Hub", To: "DestA", Meters: 1000, Seconds: 60},
+		{From: "Hub", To: "DestB", Meters: 9000, Seconds: 540},
+		{From: "Depot", To: "DestA", Meters: 9000, Seconds: 540},
+		{From: "Depot", To: "DestB", Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "Hub", Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "Depot", Meters: 9000, Seconds: 540},
+		{From: "DestA", To: "DestB", Meters: 8000, Seconds: 480},
+		{From: "DestB", To: "Hub", Meters: 9000, Seconds: 540},
+		{From: "DestB", To: "Depot", Meters: 1000, Seconds: 60},
+		{From: "DestB", To: "DestA", Meters: 8000, Seconds: 480},
+	}
+	repo := testutil.NewMockPackageRepository([]*domain.Package{
+		{PackageID: 1, Destination: "DestA"},
+		{PackageID: 2, Destination: "DestB"},
+		{PackageID: 3, Destination: "DestB"},
+	}, nil)
+	req := services.PlanDeliveriesRequest{
+		Hub:      "Hub",
+		DepartAt: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		Trucks: []*domain.Truck{
+			{TruckID: 4, Name: "Small van", Capacity: 1, Available: true},
+			{TruckID: 9, Name: "Box truck", Capacity: 2, StartLocation: " Depot ", Available: true},
+		},
+	}
+
+	result, err := services.PlanDeliveries(context.Background(), req, repo, testutil.NewMockDistanceProvider(pairs))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		truckID  int
+		name     string
+		start    string
+		stop     string
+		packages int
+	}{
+		{4, "Small van", "Hub", "DestA", 1},
+		{9, "Box truck", "Depot", "DestB", 2},
+	}
+	if len(result.Plans) != len(want) {
+		t.Fatalf("expected %d plans, got %d", len(want), len(result.Plans))
+	}
+	for i, w := range want {
+		p := result.Plans[i]
+		if p.TruckID != w.truckID || p.TruckName != w.name || p.StartLocation != w.start ||
+			len(p.Stops) != 1 || p.Stops[0].Destination != w.stop || len(p.Stops[0].PackageIDs) != w.packages {
+			t.Fatalf("plan %d: expected truck %d %q from %s to %s with %d packages, got %+v",
+				i, w.truckID, w.name, w.start, w.stop, w.packages, p)
+		}
+	}
+	if req.Trucks[1].StartLocation != " Depot " || req.Trucks[1].Packages != nil {
+		t.Fatalf("expected request trucks to be left unchanged, got %+v", req.Trucks[1])
+	}
+}
+
+func TestPlanDeliveriesShift(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "DestB", Meters: 2000, Seconds: 120},
+		{From: hub, To: "Far", Meters: 9000, Seconds: 600},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "DestB", Meters: 3000, Seconds: 180},
+		{From: "DestA", To: "Far", Meters: 9000, Seconds: 600},
+		{From: "DestB", To: hub, Meters: 2000, Seconds: 120},
+		{From: "DestB", To: "DestA", Meters: 3000, Seconds: 180},
+		{From: "DestB", To: "Far", Meters: 9000, Seconds: 600},
+		{From: "Far", To: hub, Meters: 9000, Seconds: 600},
+		{From: "Far", To: "DestA", Meters: 9000, Seconds: 600},
+		{From: "Far", To: "DestB", Meters: 9000, Seconds: 600},
+	}
+	packages := func() []*domain.Package {
+		return []*domain.Package{
+			{PackageID: 1, Destination: "DestA"},
+			{PackageID: 2, Destination: "DestB"},
+			{PackageID: 3, Destination: "Far"},
+		}
+	}
+	req := services.PlanDeliveriesRequest{
+		Hub:           hub,
+		TruckCount:    1,
+		TruckCapacity: 5,
+		DepartAt:      time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		Shift:         domain.Shift{MaxDuration: 3 * time.Minute},
+	}
+
+	tests := []struct {
+		name           string
+		truckCount     int
+		wantPerTruck   []int
+		wantUnassigned map[string]string
+	}{
+		{
+			// DestA then DestB takes 240s, so one truck has no time left for DestB.
+			name:         "one truck stops at its shift limit",
+			truckCount:   1,
+			wantPerTruck: []int{1},
+			wantUnassigned: map[string]string{
+				"DestB": "no truck has shift time or capacity left",
+				"Far":   "delivering here alone exceeds the shift limit",
+			},
+		},
+		{
+			name:         "the next truck picks up what the first cannot fit",
+			truckCount:   2,
+			wantPerTruck: []int{1, 1},
+			wantUnassigned: map[string]string{
+				"Far": "delivering here alone exceeds the shift limit",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := req
+			r.TruckCount = tc.truckCount
+			repo := testutil.NewMockPackageRepository(packages(), nil)
+			result, err := services.PlanDeliveries(context.Background(), r, repo, testutil.NewMockDistanceProvider(pairs))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var perTruck []int
+			for _, p := range result.Plans {
+				if p.TotalDurationSeconds > 180 {
+					t.Fatalf("truck %d: expected at most 180s, got %ds", p.TruckID, p.TotalDurationSeconds)
+				}
+				perTruck = append(perTruck, len(p.Stops))
+			}
+			if !slices.Equal(perTruck, tc.wantPerTruck) {
+				t.Fatalf("expected stops per truck %v, got %v", tc.wantPerTruck, perTruck)
+			}
+
+			if len(result.Unassigned) != len(tc.wantUnassigned) {
+				t.Fatalf("expected unassigned %v, got %+v", tc.wantUnassigned, result.Unassigned)
+			}
+			for _, u := range result.Unassigned {
+				if tc.wantUnassigned[u.Address] != u.Reason {
+					t.Fatalf("expected %s to be unassigned with %q, got %q", u.Address, tc.wantUnassigned[u.Address], u.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanDeliveriesMultiTrip(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "DestB", Meters: 2000, Seconds: 120},
+		{From: hub, To: "Far", Meters: 9000, Seconds: 600},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "DestB", Meters: 3000, Seconds: 180},
+		{From: "DestA", To: "Far", Meters: 9000, Seconds: 600},
+		{From: "DestB", To: hub, Meters: 2000, Seconds: 120},
+		{From: "DestB", To: "DestA", Meters: 3000, Seconds: 180},
+		{From: "DestB", To: "Far", Meters: 9000, Seconds: 600},
+		{From: "Far", To: hub, Meters: 9000, Seconds: 600},
+		{From: "Far", To: "DestA", Meters: 9000, Seconds: 600},
+		{From: "Far", To: "DestB", Meters: 9000, Seconds: 600},
+	}
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	req := services.PlanDeliveriesRequest{
+		Hub:           hub,
+		TruckCount:    1,
+		TruckCapacity: 1,
+		DepartAt:      depart,
+		MultiTrip:     true,
+		ReloadTime:    30 * time.Second,
+	}
+
+	tests := []struct {
+		name           string
+		shift          domain.Shift
+		wantTrips      []string
+		wantUnassigned map[string]string
+	}{
+		{
+			// Round trips of 120s, 240s and 1200s, each followed by a 30s reload.
+			name:      "one truck delivers everything in trips",
+			wantTrips: []string{"1 DestA 08:00:00", "2 DestB 08:02:30", "3 Far 08:07:00"},
+		},
+		{
+			// After two trips and reloads only 60s of the shift remain.
+			name:      "trips end with the shift",
+			shift:     domain.Shift{MaxDuration: 8 * time.Minute},
+			wantTrips: []string{"1 DestA 08:00:00", "2 DestB 08:02:30"},
+			wantUnassigned: map[string]string{
+				"Far": "delivering here alone exceeds the shift limit",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := req
+			r.Shift = tc.shift
+			repo := testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "DestB"},
+				{PackageID: 3, Destination: "Far"},
+			}, nil)
+			result, err := services.PlanDeliveries(context.Background(), r, repo, testutil.NewMockDistanceProvider(pairs))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var trips []string
+			for _, p := range result.Plans {
+				if p.TruckID != 1 || !p.ReturnToStart || len(p.Stops) != 1 {
+					t.Fatalf("expected single-stop round trips for truck 1, got %+v", p)
+				}
+				trips = append(trips, fmt.Sprintf("%d %s %s", p.Trip, p.Stops[0].Destination, p.DepartAt.Format(time.TimeOnly)))
+			}
+			if !slices.Equal(trips, tc.wantTrips) {
+				t.Fatalf("expected trips %q, got %q", tc.wantTrips, trips)
+			}
+
+			if len(result.Unassigned) != len(tc.wantUnassigned) {
+				t.Fatalf("expected unassigned %v, got %+v", tc.wantUnassigned, result.Unassigned)
+			}
+			for _, u := range result.Unassigned {
+				if tc.wantUnassigned[u.Address] != u.Reason {
+					t.Fatalf("expected %s to be unassigned with %q, got %q", u.Address, tc.wantUnassigned[u.Address], u.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanDeliveriesPriority(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "Far", Meters: 9000, Seconds: 600},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "Far", Meters: 9000, Seconds: 600},
+		{From: "Far", To: hub, Meters: 9000, Seconds: 600},
+		{From: "Far", To: "DestA", Meters: 9000, Seconds: 600},
+	}
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	noon := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		packages       []*domain.Package
+		capacity       int
+		shift          domain.Shift
+		promises       domain.Promises
+		wantStops      []string
+		wantUnassigned []int
+		wantMissed     []string
+		wantCapacity   bool
+	}{
+		{
+			name: "without priority a full truck is a capacity error",
+			packages: []*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "Far"},
+			},
+			capacity:     1,
+			wantCapacity: true,
+		},
+		{
+			name: "express package takes the only room",
+			packages: []*domain.Package{
+				{PackageID: 1, Destination: "DestA", Deadline: &noon},
+				{PackageID: 2, Destination: "Far", ServiceLevel: domain.ServiceLevelExpress},
+			},
+			capacity:       1,
+			promises:       domain.Promises{Express: time.Hour},
+			wantStops:      []string{"Far"},
+			wantUnassigned: []int{1},
+			wantMissed:     []string{"1 not planned"},
+		},
+		{
+			name: "late express package is flagged",
+			packages: []*domain.Package{
+				{PackageID: 2, Destination: "Far", ServiceLevel: domain.ServiceLevelExpress},
+			},
+			capacity:   1,
+			promises:   domain.Promises{Express: 5 * time.Minute},
+			wantStops:  []string{"Far"},
+			wantMissed: []string{"2 truck 1 late 5m0s"},
+		},
+		{
+			// Both stops take 660s; only one fits a 600s shift.
+			name: "same-day package displaces a nearer standard one within the shift",
+			packages: []*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "Far", ServiceLevel: domain.ServiceLevelSameDay},
+			},
+			capacity:       2,
+			shift:          domain.Shift{MaxDuration: 10 * time.Minute},
+			wantStops:      []string{"Far"},
+			wantUnassigned: []int{1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    1,
+				TruckCapacity: tc.capacity,
+				DepartAt:      depart,
+				Shift:         tc.shift,
+				Promises:      tc.promises,
+			}
+			repo := testutil.NewMockPackageRepository(tc.packages, nil)
+
+			result, err := services.PlanDeliveries(context.Background(), req, repo, testutil.NewMockDistanceProvider(pairs))
+			if tc.wantCapacity {
+				var capErr *domain.CapacityError
+				if !errors.As(err, &capErr) {
+					t.Fatalf("expected capacity error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var stops []string
+			for _, p := range result.Plans {
+				for _, s := range p.Stops {
+					stops = append(stops, s.Destination)
+				}
+			}
+			if !slices.Equal(stops, tc.wantStops) {
+				t.Fatalf("expected stops %v, got %v", tc.wantStops, stops)
+			}
+
+			var unassigned []int
+			for _, u := range result.Unassigned {
+				unassigned = append(unassigned, u.PackageIDs...)
+			}
+			if !slices.Equal(unassigned, tc.wantUnassigned) {
+				t.Fatalf("expected unassigned packages %v, got %v", tc.wantUnassigned, unassigned)
+			}
+
+			var missed []string
+			for _, m := range result.MissedPromises {
+				if m.ArriveAt.IsZero() {
+					missed = append(missed, fmt.Sprintf("%d not planned", m.PackageID))
+					continue
+				}
+				missed = append(missed, fmt.Sprintf("%d truck %d late %s", m.PackageID, m.TruckID, m.ArriveAt.Sub(m.Deadline)))
+			}
+			if !slices.Equal(missed, tc.wantMissed) {
+				t.Fatalf("expected missed promises %q, got %q", tc.wantMissed, missed)
+			}
+		})
+	}
+}
+
+func TestPlanDeliveriesObjective(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "DestB", Meters: 2000, Seconds: 120},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "DestB", Meters: 3000, Seconds: 180},
+		{From: "DestB", To: hub, Meters: 2000, Seconds: 120},
+		{From: "DestB", To: "DestA", Meters: 3000, Seconds: 180},
+	}
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	fleet := []*domain.Truck{
+		{TruckID: 1, Capacity: 1, CostPerKm: 1, Available: true},
+		{TruckID: 2, Capacity: 1, CostPerKm: 2, Available: true},
+	}
+
+	tests := []struct {
+		name        string
+		objective   services.Objective
+		trucks      []*domain.Truck
+		wantField   string
+		wantMetrics services.PlanMetrics
+	}{
+		{
+			name:      "unknown objective is rejected",
+			objective: "cheapest",
+			wantField: "objective",
+		},
+		{
+			name:      "cost needs fleet trucks",
+			objective: services.ObjectiveCost,
+			wantField: "objective",
+		},
+		{
+			name:      "cost needs a rate on every truck",
+			objective: services.ObjectiveCost,
+			trucks:    []*domain.Truck{fleet[0], {TruckID: 3, Capacity: 1, Available: true}},
+			wantField: "objective",
+		},
+		{
+			name:   "metrics are reported without an objective",
+			trucks: fleet,
+			wantMetrics: services.PlanMetrics{
+				TotalDistanceMeters:  3000,
+				TotalDurationSeconds: 180,
+				TotalCost:            5,
+				FinishAt:             depart.Add(2 * time.Minute),
+				MakespanSeconds:      120,
+
+				MinRouteDurationSeconds:    60,
+				MaxRouteDurationSeconds:    120,
+				RouteDurationStdDevSeconds: 30,
+			},
+		},
+		{
+			name:      "cost objective reports the same metrics",
+			objective: services.ObjectiveCost,
+			trucks:    fleet,
+			wantMetrics: services.PlanMetrics{
+				TotalDistanceMeters:  3000,
+				TotalDurationSeconds: 180,
+				TotalCost:            5,
+				FinishAt:             depart.Add(2 * time.Minute),
+				MakespanSeconds:      120,
+
+				MinRouteDurationSeconds:    60,
+				MaxRouteDurationSeconds:    120,
+				RouteDurationStdDevSeconds: 30,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "DestB"},
+			}, nil)
+			req := services.PlanDeliveriesRequest{
+				Hub:           hub,
+				TruckCount:    2,
+				TruckCapacity: 1,
+				Trucks:        tc.trucks,
+				DepartAt:      depart,
+				Objective:     tc.objective,
+			}
+
+			result, err := services.PlanDeliveries(context.Background(), req, repo, testutil.NewMockDistanceProvider(pairs))
+			if tc.wantField != "" {
+				var vErr *domain.ValidationError
+				if !errors.As(err, &vErr) || vErr.Field != tc.wantField {
+					t.Fatalf("expected validation error on %q, got %v", tc.wantField, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Metrics != tc.wantMetrics {
+				t.Fatalf("expected metrics %+v, got %+v", tc.wantMetrics, result.Metrics)
+			}
+		})
+	}
+}
+
+func TestPlanDeliveriesMakespan(t *testing.T) {
+	// East1 and East3 lie one way from the hub, West2 and West4 the other.
+	pairs := linePairs(map[string]int{"Hub": 0, "East1": 1, "West2": -2, "East3": 3, "West4": -4})
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		objective    services.Objective
+		wantRoutes   []string
+		wantMakespan int
+	}{
+		// Bands by hub distance send both trucks both ways.
+		{name: "duration", objective: services.ObjectiveDuration, wantRoutes: []string{"East1 West2", "East3 West4"}, wantMakespan: 600},
+		{name: "makespan", objective: services.ObjectiveMakespan, wantRoutes: []string{"West2 West4", "East1 East3"}, wantMakespan: 240},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockPackageRepository([]*domain.Package{
+				{PackageID: 1, Destination: "East1"},
+				{PackageID: 2, Destination: "West2"},
+				{PackageID: 3, Destination: "East3"},
+				{PackageID: 4, Destination: "West4"},
+			}, nil)
+			req := services.PlanDeliveriesRequest{
+				Hub:           "Hub",
+				TruckCount:    2,
+				TruckCapacity: 4,
+				DepartAt:      depart,
+				Objective:     tc.objective,
+			}
+
+			result, err := services.PlanDeliveries(context.Background(), req, repo, testutil.NewMockDistanceProvider(pairs))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var routes []string
+			for _, p := range result.Plans {
+				var stops []string
+				for _, s := range p.Stops {
+					stops = append(stops, s.Destination)
+				}
+				routes = append(routes, strings.Join(stops, " "))
+			}
+			if !slices.Equal(routes, tc.wantRoutes) {
+				t.Fatalf("expected routes %q, got %q", tc.wantRoutes, routes)
+			}
+			if result.Metrics.MakespanSeconds != tc.wantMakespan {
+				t.Fatalf("expected makespan %ds, got %ds", tc.wantMakespan, result.Metrics.MakespanSeconds)
+			}
+		})
+	}
+}
+
+// linePairs lays places out along a road, one minute and 100 meters per unit
+// apart, and returns the distances between every pair of them.
+func linePairs(positions map[string]int) []testutil.MockPair {
+	var pairs []testutil.MockPair
+	for a, pa := range positions {
+		for b, pb := range positions {
+			if a == b {
+				continue
+			}
+			units := pa - pb
+			if units < 0 {
+				units = -units
+			}
+			pairs = append(pairs, testutil.MockPair{From: a, To: b, Meters: units * 100, Seconds: units * 60})
+		}
+	}
+	return pairs
+}
+
+func TestPlanDeliveriesBalance(t *testing.T) {
+	// A to D lie one way from the hub, E and F the other.
+	pairs := linePairs(map[string]int{"Hub": 0, "A": 1, "B": 2, "C": 3, "D": 4, "E": -4, "F": -5})
+	spread := []*domain.Package{
+		{PackageID: 1, Destination: "A"},
+		{PackageID: 2, Destination: "B"},
+		{PackageID: 3, Destination: "C"},
+		{PackageID: 4, Destination: "D"},
+		{PackageID: 5, Destination: "E"},
+		{PackageID: 6, Destination: "F"},
+	}
+	crowded := []*domain.Package{
+		{PackageID: 1, Destination: "A"},
+		{PackageID: 2, Destination: "A"},
+		{PackageID: 3, Destination: "A"},
+		{PackageID: 4, Destination: "B"},
+		{PackageID: 5, Destination: "C"},
+		{PackageID: 6, Destination: "D"},
+	}
+
+	tests := []struct {
+		name      string
+		packages  []*domain.Package
+		balance   services.Balance
+		multiTrip bool
+		wantField string
+		wantStops [][]string
+		// Shortest and longest route and their standard deviation, in seconds.
+		wantSpread [3]float64
+	}{
+		{
+			name:       "bands without balancing",
+			packages:   spread,
+			wantStops:  [][]string{{"A", "B", "C"}, {"D", "E", "F"}},
+			wantSpread: [3]float64{180, 780, 300},
+		},
+		{
+			name:       "duration moves a stop to the short route",
+			packages:   spread,
+			balance:    services.Balance{Mode: services.BalanceDuration, Tolerance: 0.1},
+			wantStops:  [][]string{{"A", "B", "C", "D"}, {"E", "F"}},
+			wantSpread: [3]float64{240, 300, 30},
+		},
+		{
+			name:       "a loose tolerance accepts the bands",
+			packages:   spread,
+			balance:    services.Balance{Mode: services.BalanceDuration, Tolerance: 2},
+			wantStops:  [][]string{{"A", "B", "C"}, {"D", "E", "F"}},
+			wantSpread: [3]float64{180, 780, 300},
+		},
+		{
+			name:       "packages evens out package counts",
+			packages:   crowded,
+			balance:    services.Balance{Mode: services.BalancePackages},
+			wantStops:  [][]string{{"A"}, {"B", "C", "D"}},
+			wantSpread: [3]float64{60, 240, 90},
+		},
+		{
+			name:      "unknown mode is rejected",
+			packages:  spread,
+			balance:   services.Balance{Mode: "stops"},
+			wantField: "balance",
+		},
+		{
+			name:      "negative tolerance is rejected",
+			packages:  spread,
+			balance:   services.Balance{Mode: services.BalanceDuration, Tolerance: -1},
+			wantField: "balance",
+		},
+		{
+			name:      "multi-trip cannot be balanced",
+			packages:  spread,
+			balance:   services.Balance{Mode: services.BalanceDuration},
+			multiTrip: true,
+			wantField: "balance",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := services.PlanDeliveriesRequest{
+				Hub:           "Hub",
+				TruckCount:    2,
+				TruckCapacity: 6,
+				DepartAt:      time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+				Balance:       tc.balance,
+				MultiTrip:     tc.multiTrip,
+			}
+			repo := testutil.NewMockPackageRepository(tc.packages, nil)
+
+			result, err := services.PlanDeliveries(context.Background(), req, repo, testutil.NewMockDistanceProvider(pairs))
+			if tc.wantField != "" {
+				var vErr *domain.ValidationError
+				if !errors.As(err, &vErr) || vErr.Field != tc.wantField {
+					t.Fatalf("expected validation error on %q, got %v", tc.wantField, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var stops [][]string
+			for _, p := range result.Plans {
+				var dests []string
+				for _, s := range p.Stops {
+					dests = append(dests, s.Destination)
+				}
+				stops = append(stops, dests)
+			}
+			if !slices.EqualFunc(stops, tc.wantStops, slices.Equal[[]string]) {
+				t.Fatalf("expected stops %v, got %v", tc.wantStops, stops)
+			}
+
+			m := result.Metrics
+			got := [3]float64{float64(m.MinRouteDurationSeconds), float64(m.MaxRouteDurationSeconds), m.RouteDurationStdDevSeconds}
+			if got != tc.wantSpread {
+				t.Fatalf("expected route duration min, max and stddev %v, got %v", tc.wantSpread, got)
+			}
+		})
+	}
+}
+
+func TestPlanDeliveriesIncludeGeometry(t *testing.T) {
+	hub := "Hub"
+	pairs := []testutil.MockPair{
+		{From: hub, To: "DestA", Meters: 1000, Seconds: 60},
+		{From: hub, To: "DestB", Meters: 2000, Seconds: 120},
+		{From: "DestA", To: hub, Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "DestB", Meters: 3000, Seconds: 180},
+		{From: "DestB", To: hub, Meters: 2000, Seconds: 120},
+		{From: "DestB", To: "DestA", Meters: 3000, Seconds: 180},
+	}
+	packages := []*domain.Package{
+		{PackageID: 1, Destination: "DestA"},
+		{PackageID: 2, Destination: "DestB"},
+	}
+	req := services.PlanDeliveriesRequest{
+		Hub:             hub,
+		TruckCount:      1,
+		TruckCapacity:   5,
+		DepartAt:        time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		ReturnToStart:   true,
+		IncludeGeometry: true,
+	}
+
+	t.Run("attaches geometry per plan", func(t *testing.T) {
+		provider := testutil.NewMockDirectionsProvider(pairs)
+		repo := testutil.NewMockPackageRepository(packages, nil)
+
+		result, err := services.PlanDeliveries(context.Background(), req, repo, provider)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Plans) != 1 {
+			t.Fatalf("expected 1 plan, got %d", len(result.Plans))
+		}
+
+		g := result.Plans[0].Geometry
+		if g == nil {
+			t.Fatalf("expected geometry, got nil")
+		}
+		if want := "Hub>DestA>DestB>Hub"; g.Polyline != want {
+			t.Fatalf("expected polyline %q, got %q", want, g.Polyline)
+		}
+		if len(g.Legs) != 3 {
+			t.Fatalf("expected 3 legs, got %d", len(g.Legs))
+		}
+		if len(provider.Requested) != 1 {
+			t.Fatalf("expected 1 directions request, got %d", len(provider.Requested))
+		}
+	})
+
+	t.Run("no geometry unless requested", func(t *testing.T) {
+		provider := testutil.NewMockDirectionsProvider(pairs)
+		repo := testutil.NewMockPackageRepository(packages, nil)
+
+		noGeometry := req
+		noGeometry.IncludeGeometry = false
+		result, err := services.PlanDeliveries(context.Background(), noGeometry, repo, provider)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Plans[0].Geometry != nil {
+			t.Fatalf("expected no geometry, got %+v", result.Plans[0].Geometry)
+		}
+		if len(provider.Requested) != 0 {
+			t.Fatalf("expected no directions requests, got %d", len(provider.Requested))
+		}
+	})
+
+	t.Run("unsupported provider is a validation error", func(t *testing.T) {
+		provider := testutil.NewMockDistanceProvider(pairs)
+		repo := testutil.NewMockPackageRepository(packages, nil)
+
+		_, err := services.PlanDeliveries(context.Background(), req, repo, provider)
+		var ve *domain.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "include_geometry" {
+			t.Fatalf("expected include_geometry validation error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DepotPlans are the route plans of one hub in a multi-depot run.
+type DepotPlans struct {
+	Hub *domain.Hub
+	// Route plans for the hub's trucks with at least one assigned package.
+	Plans []*domain.RoutePlan
+}
+
+// MultiDepotResult is the outcome of a multi-depot planning run.
+type MultiDepotResult struct {
+	// One entry per hub with trucks, in hub order.
+	Depots []DepotPlans
+	// Destinations the provider could not locate; their packages were left out.
+	Ungeocodable []UngeocodableDestination
+	// Destinations no truck could take, including those tied to a hub without trucks.
+	Unassigned []UnassignedDestination
+	// Packages with a promise the plans do not keep, across all hubs.
+	MissedPromises []MissedPromise
+	// Totals of all hubs' plans in every objective.
+	Metrics PlanMetrics
+}
+
+// PlanMultiDepot plans deliveries from several hubs at once.
+//
+// Packages tied to a hub are delivered from it. Every other package goes to
+// the hub nearest its destination under req.Objective among the hubs that
+// have trucks. Each hub then plans its packages as PlanDeliveries would, with
+// its own trucks: the fleet trucks in req.Trucks whose home hub is the hub's
+// address, or TruckCount trucks of TruckCapacity per hub. req.Hub is ignored,
+// and req.MultiTrip is rejected.
+func PlanMultiDepot(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	hubs []*domain.Hub,
+	repo ports.PackageRepository,
+	provider ports.DistanceProvider,
+) (*MultiDepotResult, error) {
+	if req.MultiTrip {
+		return nil, fmt.Errorf("plan multi-depot: %w", &domain.ValidationError{
+			Field:  "multi_trip",
+			Reason: "multi_trip cannot be combined with multi_depot",
+		})
+	}
+	if len(hubs) == 0 {
+		return nil, fmt.Errorf("plan multi-depot: %w", &domain.ValidationError{
+			Field:  "hubs",
+			Reason: "multi-depot planning needs at least one hub",
+		})
+	}
+	hubs = normalizeHubs(hubs)
+	for _, h := range hubs {
+		if h.Address == "" {
+			return nil, fmt.Errorf("plan multi-depot: %w", &domain.ValidationError{
+				Field:  "hubs",
+				Reason: fmt.Sprintf("hub %d address must not be empty", h.HubID),
+			})
+		}
+	}
+
+	// Every hub plans from its own address; the first stands in for validation.
+	check := req
+	check.Hub = hubs[0].Address
+	if err := validateRequest(check); err != nil {
+		return nil, err
+	}
+	if err := validateProvider(check, provider); err != nil {
+		return nil, err
+	}
+
+	trucks, err := depotTrucks(req, hubs)
+	if err != nil {
+		return nil, fmt.Errorf("plan multi-depot: %w", err)
+	}
+
+	pkgDest, destinations, err := loadPackages(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := allPackages(pkgDest, destinations)
+	byID := make(map[int]*domain.Hub, len(hubs))
+	for _, h := range hubs {
+		byID[h.HubID] = h
+	}
+	for _, d := range destinations {
+		for _, pkg := range pkgDest[d] {
+			if _, ok := byID[pkg.HubID]; pkg.HubID != 0 && !ok {
+				return nil, fmt.Errorf("plan multi-depot: %w", &domain.ValidationError{
+					Field:     "hub_id",
+					PackageID: pkg.PackageID,
+					Reason:    fmt.Sprintf("hub %d does not exist", pkg.HubID),
+				})
+			}
+		}
+	}
+
+	// Only hubs with trucks can deliver; they are the ones worth locating.
+	var active []*domain.Hub
+	var addresses []string
+	for _, h := range hubs {
+		if len(trucks[h.HubID]) > 0 {
+			active = append(active, h)
+			addresses = append(addresses, h.Address)
+		}
+	}
+
+	known := knownCoordinates(pkgDest)
+	destinations, ungeocodable, err := resolveLocations(ctx, addresses, pkgDest, destinations, known, repo, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	nearest, err := nearestHubs(ctx, active, trucks, pkgDest, destinations, req.Objective, withKnownCoordinates(provider, known))
+	if err != nil {
+		return nil, attachPackageIDs(err, pkgDest)
+	}
+
+	// Split the packages by hub, keeping each hub's destinations in the
+	// original order.
+	depotDest := make(map[int]map[string][]*domain.Package, len(hubs))
+	depotDests := make(map[int][]string, len(hubs))
+	var unassigned []UnassignedDestination
+	for _, d := range destinations {
+		var orphaned []int
+		for _, pkg := range pkgDest[d] {
+			hub := pkg.HubID
+			if hub == 0 {
+				hub = nearest[d]
+			}
+			if len(trucks[hub]) == 0 {
+				orphaned = append(orphaned, pkg.PackageID)
+				continue
+			}
+			if depotDest[hub] == nil {
+				depotDest[hub] = make(map[string][]*domain.Package)
+			}
+			if _, ok := depotDest[hub][d]; !ok {
+				depotDests[hub] = append(depotDests[hub], d)
+			}
+			depotDest[hub][d] = append(depotDest[hub][d], pkg)
+		}
+		if len(orphaned) > 0 {
+			unassigned = append(unassigned, UnassignedDestination{
+				Address:    d,
+				Reason:     "its hub has no trucks",
+				PackageIDs: orphaned,
+			})
+		}
+	}
+
+	result := &MultiDepotResult{Ungeocodable: ungeocodable, Unassigned: unassigned}
+	var plans []*domain.RoutePlan
+	for _, h := range active {
+		hubReq := req
+		hubReq.Hub = h.Address
+		hubReq.Trucks = trucks[h.HubID]
+
+		res, err := planPackages(ctx, hubReq, depotDest[h.HubID], depotDests[h.HubID], known, repo, provider)
+		if err != nil {
+			return nil, fmt.Errorf("plan multi-depot: hub %d: %w", h.HubID, err)
+		}
+		result.Depots = append(result.Depots, DepotPlans{Hub: h, Plans: res.Plans})
+		result.Ungeocodable = append(result.Ungeocodable, res.Ungeocodable...)
+		result.Unassigned = append(result.Unassigned, res.Unassigned...)
+		plans = append(plans, res.Plans...)
+	}
+	result.MissedPromises = missedPromises(pkgs, plans, req.Promises, req.DepartAt)
+	result.Metrics = MetricsOf(plans, req.DepartAt)
+
+	return result, nil
+}
+
+// normalizeHubs returns copies of hubs with whitespace in addresses collapsed,
+// so they match the home hubs of trucks however either was spaced.
+func normalizeHubs(hubs []*domain.Hub) []*domain.Hub {
+	out := make([]*domain.Hub, 0, len(hubs))
+	for _, h := range hubs {
+		hub := *h
+		hub.Address = strings.Join(strings.Fields(hub.Address), " ")
+		out = append(out, &hub)
+	}
+	return out
+}
+
+// depotTrucks groups the trucks of req by hub ID. Fleet trucks belong to the
+// hub at their home hub address; without a fleet, every hub gets TruckCount
+// trucks of TruckCapacity, numbered on from the previous hub's.
+func depotTrucks(req PlanDeliveriesRequest, hubs []*domain.Hub) (map[int][]*domain.Truck, error) {
+	byHub := make(map[int][]*domain.Truck, len(hubs))
+
+	if len(req.Trucks) == 0 {
+		id := 0
+		for _, h := range hubs {
+			for i := 0; i < req.TruckCount; i++ {
+				id++
+				truck := domain.NewTruck(id, req.TruckCapacity, h.Address)
+				truck.MaxWeightKg = req.TruckMaxWeightKg
+				truck.MaxVolumeM3 = req.TruckMaxVolumeM3
+				byHub[h.HubID] = append(byHub[h.HubID], truck)
+			}
+		}
+		return byHub, nil
+	}
+
+	for _, t := range req.Trucks {
+		home := strings.Join(strings.Fields(t.StartLocation), " ")
+		i := slices.IndexFunc(hubs, func(h *domain.Hub) bool { return h.Address == home })
+		if i < 0 {
+			return nil, &domain.ValidationError{
+				Field:  "trucks",
+				Reason: fmt.Sprintf("truck %d home hub %q is not the address of a hub", t.TruckID, t.StartLocation),
+			}
+		}
+		byHub[hubs[i].HubID] = append(byHub[hubs[i].HubID], t)
+	}
+	return byHub, nil
+}
+
+// nearestHubs picks, for each destination with a package not tied to a hub,
+// the hub in hubs cheapest to drive to it from under objective. A hub costs
+// what its cheapest truck in trucks would for the drive, so the order of the
+// fleet does not matter; earlier hubs win ties.
+func nearestHubs(
+	ctx context.Context,
+	hubs []*domain.Hub,
+	trucks map[int][]*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	destinations []string,
+	objective Objective,
+	provider ports.DistanceProvider,
+) (map[string]int, error) {
+	var open []string
+	for _, d := range destinations {
+		if slices.ContainsFunc(pkgDest[d], func(p *domain.Package) bool { return p.HubID == 0 }) {
+			open = append(open, d)
+		}
+	}
+	nearest := make(map[string]int, len(open))
+	if len(open) == 0 || len(hubs) == 0 {
+		return nearest, nil
+	}
+
+	best := make(map[string]float64, len(open))
+	for _, h := range hubs {
+		distances, err := fetchHubDistances(ctx, h.Address, open, provider)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range open {
+			cost := objective.cheapestLegCost(trucks[h.HubID], distances[d])
+			if _, ok := nearest[d]; !ok || cost < best[d] {
+				nearest[d] = h.HubID
+				best[d] = cost
+			}
+		}
+	}
+	return nearest, nil
+}
@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/services"
+	"delivery-route-service/internal/testutil"
+)
+
+func TestPlanMultiDepot(t *testing.T) {
+	// DestA is next to Hub and DestB next to Depot.
+	pairs := []testutil.MockPair{
+		{From: "Hub", To: "DestA", Meters: 1000, Seconds: 60},
+		{From: "Hub", To: "DestB", Meters: 9000, Seconds: 540},
+		{From: "Depot", To: "DestA", Meters: 9000, Seconds: 540},
+		{From: "Depot", To: "DestB", Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "Hub", Meters: 1000, Seconds: 60},
+		{From: "DestA", To: "Depot", Meters: 9000, Seconds: 540},
+		{From: "DestA", To: "DestB", Meters: 8000, Seconds: 480},
+		{From: "DestB", To: "Hub", Meters: 9000, Seconds: 540},
+		{From: "DestB", To: "Depot", Meters: 1000, Seconds: 60},
+		{From: "DestB", To: "DestA", Meters: 8000, Seconds: 480},
+	}
+	hubs := []*domain.Hub{
+		{HubID: 1, Name: "North", Address: "Hub"},
+		{HubID: 2, Name: "South", Address: "Depot"},
+		{HubID: 3, Name: "Idle", Address: "Idle"},
+	}
+	depart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	// routes renders each depot as "hub:truck[stop/packages ...]".
+	routes := func(r *services.MultiDepotResult) []string {
+		var out []string
+		for _, d := range r.Depots {
+			for _, p := range d.Plans {
+				s := fmt.Sprintf("%d:%d", d.Hub.HubID, p.TruckID)
+				for _, stop := range p.Stops {
+					s += fmt.Sprintf(" %s%v", stop.Destination, stop.PackageIDs)
+				}
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name           string
+		packages       []*domain.Package
+		req            services.PlanDeliveriesRequest
+		wantRoutes     []string
+		wantUnassigned []int
+		wantErrField   string
+	}{
+		{
+			name: "fleet trucks plan from their home hubs",
+			packages: []*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "DestB"},
+				{PackageID: 3, Destination: "DestB", HubID: 1},
+				{PackageID: 4, Destination: "DestA", HubID: 3},
+			},
+			req: services.PlanDeliveriesRequest{
+				Trucks: []*domain.Truck{
+					{TruckID: 4, Capacity: 2, StartLocation: "Hub", Available: true},
+					{TruckID: 9, Capacity: 2, StartLocation: " Depot ", Available: true},
+				},
+			},
+			wantRoutes:     []string{"1:4 DestA[1] DestB[3]", "2:9 DestB[2]"},
+			wantUnassigned: []int{4},
+		},
+		{
+			name: "truck_count trucks per hub",
+			packages: []*domain.Package{
+				{PackageID: 1, Destination: "DestA"},
+				{PackageID: 2, Destination: "DestB"},
+			},
+			req:        services.PlanDeliveriesRequest{TruckCount: 1, TruckCapacity: 4},
+			wantRoutes: []string{"1:1 DestA[1]", "2:2 DestB[2]"},
+		},
+		{
+			name:     "cost objective uses each hub's cheapest truck",
+			packages: []*domain.Package{{PackageID: 1, Destination: "DestA"}},
+			req: services.PlanDeliveriesRequest{
+				Objective: services.ObjectiveCost,
+				Trucks: []*domain.Truck{
+					// Listed first, Hub's pricey truck would make Depot look cheaper.
+					{TruckID: 4, Capacity: 2, StartLocation: "Hub", CostPerKm: 100, Available: true},
+					{TruckID: 5, Capacity: 2, StartLocation: "Hub", CostPerKm: 1, Available: true},
+					{TruckID: 9, Capacity: 2, StartLocation: "Depot", CostPerKm: 10, Available: true},
+				},
+			},
+			wantRoutes: []string{"1:4 DestA[1]"},
+		},
+		{
+			name:         "package tied to an unknown hub",
+			packages:     []*domain.Package{{PackageID: 1, Destination: "DestA", HubID: 7}},
+			req:          services.PlanDeliveriesRequest{TruckCount: 1, TruckCapacity: 4},
+			wantErrField: "hub_id",
+		},
+		{
+			name:     "truck whose home hub is not a hub",
+			packages: []*domain.Package{{PackageID: 1, Destination: "DestA"}},
+			req: services.PlanDeliveriesRequest{
+				Trucks: []*domain.Truck{{TruckID: 4, Capacity: 2, StartLocation: "Elsewhere", Available: true}},
+			},
+			wantErrField: "trucks",
+		},
+		{
+			name:         "multi_trip is rejected",
+			packages:     []*domain.Package{{PackageID: 1, Destination: "DestA"}},
+			req:          services.PlanDeliveriesRequest{TruckCount: 1, TruckCapacity: 4, MultiTrip: true},
+			wantErrField: "multi_trip",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := testutil.NewMockPackageRepository(tc.packages, nil)
+			req := tc.req
+			req.DepartAt = depart
+			depots := hubs
+			if len(req.Trucks) == 0 {
+				// Every hub gets trucks here, so leave out the one without pairs.
+				depots = hubs[:2]
+			}
+
+			result, err := services.PlanMultiDepot(context.Background(), req, depots, repo, testutil.NewMockDistanceProvider(pairs))
+			if tc.wantErrField != "" {
+				var verr *domain.ValidationError
+				if !errors.As(err, &verr) || verr.Field != tc.wantErrField {
+					t.Fatalf("expected validation error on %q, got %v", tc.wantErrField, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := routes(result); fmt.Sprint(got) != fmt.Sprint(tc.wantRoutes) {
+				t.Fatalf("expected routes %q, got %q", tc.wantRoutes, got)
+			}
+			var unassigned []int
+			for _, u := range result.Unassigned {
+				unassigned = append(unassigned, u.PackageIDs...)
+			}
+			if fmt.Sprint(unassigned) != fmt.Sprint(tc.wantUnassigned) {
+				t.Fatalf("expected unassigned packages %v, got %v", tc.wantUnassigned, unassigned)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// planTrips plans multi-trip days: every truck loads up at its start
+// location, delivers, drives back, spends req.ReloadTime reloading and sets
+// out again, for as long as packages remain and its shift allows.
+//
+// Trips are planned in rounds. Each round assigns the destinations still
+// waiting to the trucks with shift time left, as AssignPackagesWithinShift
+// does for a single trip, priority destinations first when not everything
+// fits, with each truck's shift cut down to what its earlier trips left
+// over. Under ObjectiveMakespan, AssignPackagesByMakespan assigns each round,
+// counting when each truck is back from its earlier trips. Every trip returns
+// to the start location, and break rules restart with each trip since the
+// driver rests while the truck is reloaded. Destinations that no round can
+// place are returned as unassigned.
+//
+// Plans are ordered by truck, in the order of trucks, then by trip.
+func planTrips(
+	ctx context.Context,
+	req PlanDeliveriesRequest,
+	trucks []*domain.Truck,
+	pkgDest map[string][]*domain.Package,
+	hubDistances map[string]map[string]ports.DistanceResult,
+	destinations []string,
+	pairwiseDist map[string]ports.DistanceResult,
+) (plans []*domain.RoutePlan, unassigned []UnassignedDestination, err error) {
+	route, name := req.Strategy.router()
+	opts := routeOptions(req)
+	opts.ReturnToStart = true
+
+	// When each truck's next trip can leave: back from the last one and reloaded.
+	departs := make(map[int]time.Time, len(trucks))
+	for _, t := range trucks {
+		departs[t.TruckID] = req.DepartAt
+	}
+
+	fits := func(truck *domain.Truck) (bool, error) {
+		if truck.Shift.MaxDuration <= 0 {
+			return true, nil
+		}
+		plan, err := route(ctx, truck, departs[truck.TruckID], pairwiseDist, opts)
+		if err != nil {
+			return false, fmt.Errorf("plan %s route: %w", name, err)
+		}
+		return time.Duration(plan.TotalDurationSeconds)*time.Second <= truck.Shift.MaxDuration, nil
+	}
+	finish := func(truck *domain.Truck) (time.Time, error) {
+		plan, err := route(ctx, truck, departs[truck.TruckID], pairwiseDist, opts)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("plan %s route: %w", name, err)
+		}
+		return plan.DepartAt.Add(time.Duration(plan.TotalDurationSeconds) * time.Second), nil
+	}
+
+	remaining := slices.Clone(destinations)
+	for trip := 1; len(remaining) > 0; trip++ {
+		round := make([]*domain.Truck, 0, len(trucks))
+		for _, t := range trucks {
+			truck := *t
+			truck.Packages = nil
+			if limit := t.Shift.MaxDuration; limit > 0 {
+				truck.Shift.MaxDuration = limit - departs[t.TruckID].Sub(req.DepartAt)
+				if truck.Shift.MaxDuration <= 0 {
+					continue
+				}
+			}
+			round = append(round, &truck)
+		}
+		if len(round) == 0 {
+			for _, d := range remaining {
+				unassigned = append(unassigned, UnassignedDestination{
+					Address:    d,
+					Reason:     "no truck has shift time left",
+					PackageIDs: packageIDs(pkgDest[d]),
+				})
+			}
+			break
+		}
+
+		left, err := assignWithinShift(round, pkgDest, hubDistances, remaining, fits, finish, req.Objective)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		loaded := false
+		for _, truck := range round {
+			if len(truck.Packages) == 0 {
+				continue
+			}
+			loaded = true
+
+			plan, err := route(ctx, truck, departs[truck.TruckID], pairwiseDist, opts)
+			if err != nil {
+				return nil, nil, fmt.Errorf("plan %s route: truck %d trip %d: %w", name, truck.TruckID, trip, err)
+			}
+			plan.Trip = trip
+			plans = append(plans, plan)
+
+			back := plan.DepartAt.Add(time.Duration(plan.TotalDurationSeconds) * time.Second)
+			departs[truck.TruckID] = back.Add(req.ReloadTime)
+		}
+
+		// Nothing moved this round, so nothing left will move in the next.
+		if !loaded {
+			unassigned = left
+			break
+		}
+		remaining = remaining[:0]
+		for _, u := range left {
+			remaining = append(remaining, u.Address)
+		}
+	}
+
+	order := make(map[int]int, len(trucks))
+	for i, t := range trucks {
+		order[t.TruckID] = i
+	}
+	slices.SortStableFunc(plans, func(a, b *domain.RoutePlan) int {
+		return order[a.TruckID] - order[b.TruckID]
+	})
+
+	return plans, unassigned, nil
+}
+
+// packageIDs lists the IDs of pkgs in order.
+func packageIDs(pkgs []*domain.Package) []int {
+	ids := make([]int, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		ids = append(ids, pkg.PackageID)
+	}
+	return ids
+}
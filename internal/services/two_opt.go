@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RouteStrategy selects how the stops of each truck are ordered.
+type RouteStrategy string
+
+const (
+	// Greedy nearest neighbor by travel duration.
+	RouteStrategyNearestNeighbor RouteStrategy = "nearest_neighbor"
+	// Nearest neighbor refined by 2-opt segment reversals.
+	RouteStrategyTwoOpt RouteStrategy = "two_opt"
+)
+
+// RouteStrategies lists the accepted strategies, default first.
+var RouteStrategies = []RouteStrategy{RouteStrategyNearestNeighbor, RouteStrategyTwoOpt}
+
+// routeFunc orders the stops of one truck into a route plan.
+type routeFunc func(
+	ctx context.Context,
+	truck *domain.Truck,
+	departAt time.Time,
+	distances map[string]ports.DistanceResult,
+	opts RouteOptions,
+) (*domain.RoutePlan, error)
+
+// router returns the route function for s and its name for error messages.
+func (s RouteStrategy) router() (routeFunc, string) {
+	if s == RouteStrategyTwoOpt {
+		return TwoOptRoute, "2-opt"
+	}
+	return NearestNeighborRoute, "nearest neighbor"
+}
+
+// Plan a delivery route by improving the nearest-neighbor order with 2-opt.
+//
+// Each pass tries reversing every segment of the stop sequence and keeps a
+// reversal when it lowers the cost of driving the route under opts.Objective,
+// travel duration by default (including the return leg when
+// opts.ReturnToStart is set). Service time does not depend on the order, so
+// it is left out of the comparison. Passes repeat until none improves the route, so the
+// result is never worse than NearestNeighborRoute. Reversals that would
+// deliver a package before its pickup or overload the truck are skipped.
+// When packages carry promises, a reversal must not add to the total time
+// they are delivered late, and one that cuts it is kept even if the route
+// gets longer. Reversals are tried in a fixed order, keeping the output
+// deterministic.
+func TwoOptRoute(
+	ctx context.Context,
+	truck *domain.Truck,
+	departAt time.Time,
+	distances map[string]ports.DistanceResult,
+	opts RouteOptions,
+) (*domain.RoutePlan, error) {
+	// The greedy route covers the cases with nothing to reorder.
+	if truck.StartLocation == "" || len(truck.Packages) == 0 {
+		return NearestNeighborRoute(ctx, truck, departAt, distances, opts)
+	}
+	order, err := nearestNeighborVisits(truck, departAt, distances, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(order) < 3 {
+		return sequenceRoute(truck, departAt, order, distances, opts)
+	}
+
+	best, err := routeCost(truck, order, distances, opts)
+	if err != nil {
+		return nil, err
+	}
+	due := opts.deadlines(truck.Packages, departAt)
+	late := func() (time.Duration, error) {
+		if len(due) == 0 {
+			return 0, nil
+		}
+		plan, err := sequenceRoute(truck, departAt, order, distances, opts)
+		if err != nil {
+			return 0, err
+		}
+		return lateness(plan, due), nil
+	}
+	bestLate, err := late()
+	if err != nil {
+		return nil, err
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(order)-1; i++ {
+			for j := i + 1; j < len(order); j++ {
+				if err := ctx.Err(); err != nil {
+					return nil, fmt.Errorf("plan route: %w", err)
+				}
+
+				slices.Reverse(order[i : j+1])
+				if !feasibleVisits(truck, order) {
+					slices.Reverse(order[i : j+1])
+					continue
+				}
+				d, err := routeCost(truck, order, distances, opts)
+				if err != nil {
+					return nil, err
+				}
+				l, err := late()
+				if err != nil {
+					return nil, err
+				}
+				if l < bestLate || (l == bestLate && d < best) {
+					best, bestLate = d, l
+					improved = true
+					continue
+				}
+				slices.Reverse(order[i : j+1])
+			}
+		}
+	}
+
+	return sequenceRoute(truck, departAt, order, distances, opts)
+}
+
+// routeCost sums the cost of each leg along order under opts.Objective,
+// starting from the truck's start location. Distances may be asymmetric, so
+// every leg is looked up rather than reusing the reversed segment's cost.
+func routeCost(
+	truck *domain.Truck,
+	order []visit,
+	distances map[string]ports.DistanceResult,
+	opts RouteOptions,
+) (float64, error) {
+	start := truck.StartLocation
+	total := 0.0
+	current := start
+	for _, v := range order {
+		d := v.destination
+		leg, ok := distances[current+"|"+d]
+		if !ok {
+			return 0, fmt.Errorf("plan route: missing distance result from %q to %q", current, d)
+		}
+		total += opts.Objective.legCost(truck, leg)
+		current = d
+	}
+	if opts.ReturnToStart {
+		back, ok := distances[current+"|"+start]
+		if !ok {
+			return 0, fmt.Errorf("plan route: missing distance result for return leg from %q to %q", current, start)
+		}
+		total += opts.Objective.legCost(truck, back)
+	}
+	return total, nil
+}
+
+// lateness sums how long after its deadline in due each package of plan
+// arrives.
+func lateness(plan *domain.RoutePlan, due map[int]time.Time) time.Duration {
+	var total time.Duration
+	for _, s := range plan.Stops {
+		for _, id := range s.PackageIDs {
+			if deadline, ok := due[id]; ok && s.ArriveAt.After(deadline) {
+				total += s.ArriveAt.Sub(deadline)
+			}
+		}
+	}
+	return total
+}
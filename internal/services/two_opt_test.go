@@ -0,0 +1,214 @@
+package services_test
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"delivery-route-service/internal/ports"
+	"delivery-route-service/internal/services"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// gridDistances builds symmetric distances between named grid points, one
+// minute and 100 meters per unit of straight-line distance.
+func gridDistances(points map[string][2]float64) map[string]ports.DistanceResult {
+	distances := make(map[string]ports.DistanceResult)
+	for a, pa := range points {
+		for b, pb := range points {
+			if a == b {
+				continue
+			}
+			units := int(math.Round(math.Hypot(pa[0]-pb[0], pa[1]-pb[1])))
+			distances[a+"|"+b] = ports.DistanceResult{DistanceMeters: units * 100, DurationSeconds: units * 60}
+		}
+	}
+	return distances
+}
+
+func TestTwoOptRoute(t *testing.T) {
+	distances := gridDistances(map[string][2]float64{
+		"HUB": {0, 0},
+		"A":   {-2, 2},
+		"B":   {3, 3},
+		"C":   {2, 1},
+		"D":   {5, -3},
+	})
+	truck := &domain.Truck{
+		TruckID:       1,
+		Capacity:      4,
+		StartLocation: "HUB",
+		Packages: []*domain.Package{
+			{PackageID: 1, Destination: "A"},
+			{PackageID: 2, Destination: "B"},
+			{PackageID: 3, Destination: "C"},
+			{PackageID: 4, Destination: "D"},
+		},
+	}
+	depart := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	greedy, err := services.NearestNeighborRoute(context.Background(), truck, depart, distances, services.RouteOptions{ReturnToStart: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := services.TwoOptRoute(context.Background(), truck, depart, distances, services.RouteOptions{ReturnToStart: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if plan.TotalDurationSeconds >= greedy.TotalDurationSeconds {
+		t.Fatalf("expected 2-opt to shorten the greedy route of %ds, got %ds",
+			greedy.TotalDurationSeconds, plan.TotalDurationSeconds)
+	}
+	if plan.TotalDurationSeconds != 21*60 {
+		t.Fatalf("expected optimal duration %ds, got %ds", 21*60, plan.TotalDurationSeconds)
+	}
+
+	var order []string
+	for _, s := range plan.Stops {
+		order = append(order, s.Destination)
+	}
+	if !slices.Equal(order, []string{"A", "B", "C", "D"}) && !slices.Equal(order, []string{"D", "C", "B", "A"}) {
+		t.Fatalf("expected stops A,B,C,D in either direction, got %v", order)
+	}
+
+	last := plan.Stops[len(plan.Stops)-1]
+	if want := depart.Add(time.Duration(plan.TotalDurationSeconds-distances[last.Destination+"|HUB"].DurationSeconds) * time.Second); !last.ArriveAt.Equal(want) {
+		t.Fatalf("expected last arrival %s, got %s", want, last.ArriveAt)
+	}
+}
+
+func TestPickupAndDelivery(t *testing.T) {
+	// Package 1 is collected at P and dropped at D, behind the hub; package 2
+	// leaves the hub for Q, just past P.
+	distances := gridDistances(map[string][2]float64{
+		"HUB": {0, 0},
+		"D":   {-1, 0},
+		"P":   {3, 0},
+		"Q":   {4, 0},
+	})
+	routers := map[string]func(context.Context, *domain.Truck, time.Time, map[string]ports.DistanceResult, services.RouteOptions) (*domain.RoutePlan, error){
+		"nearest_neighbor": services.NearestNeighborRoute,
+		"two_opt":          services.TwoOptRoute,
+	}
+
+	tests := []struct {
+		name      string
+		capacity  int
+		wantStops []string
+		wantPeak  int
+	}{
+		// A full truck has to drop package 2 before it has room for package 1.
+		{name: "drop before pickup when full", capacity: 1, wantStops: []string{"Q[2]", "P+[1]", "D[1]"}, wantPeak: 1},
+		{name: "pickup on the way out", capacity: 2, wantStops: []string{"P+[1]", "Q[2]", "D[1]"}, wantPeak: 2},
+	}
+
+	for router, route := range routers {
+		for _, tc := range tests {
+			t.Run(router+"/"+tc.name, func(t *testing.T) {
+				truck := &domain.Truck{
+					TruckID:       1,
+					Capacity:      tc.capacity,
+					StartLocation: "HUB",
+					Packages: []*domain.Package{
+						{PackageID: 1, Destination: "D", Pickup: "P"},
+						{PackageID: 2, Destination: "Q"},
+					},
+				}
+
+				plan, err := route(context.Background(), truck, time.Time{}, distances, services.RouteOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				// Stops read "destination[dropped]", with "+[picked up]" instead for pickups.
+				var stops []string
+				for _, s := range plan.Stops {
+					if s.OnBoard.Packages > tc.capacity {
+						t.Fatalf("stop %s: expected at most %d packages on board, got %d", s.Destination, tc.capacity, s.OnBoard.Packages)
+					}
+					if len(s.PickupIDs) > 0 && len(s.PackageIDs) == 0 {
+						stops = append(stops, fmt.Sprintf("%s+%v", s.Destination, s.PickupIDs))
+					} else {
+						stops = append(stops, fmt.Sprintf("%s%v", s.Destination, s.PackageIDs))
+					}
+				}
+				if !slices.Equal(stops, tc.wantStops) {
+					t.Fatalf("expected stops %v, got %v", tc.wantStops, stops)
+				}
+				if plan.Load.Packages != tc.wantPeak {
+					t.Fatalf("expected peak load %d, got %d", tc.wantPeak, plan.Load.Packages)
+				}
+			})
+		}
+	}
+}
+
+func TestRoutePromises(t *testing.T) {
+	// C lies behind the hub, A and B ahead of it; one minute per unit.
+	distances := gridDistances(map[string][2]float64{
+		"HUB": {0, 0},
+		"A":   {1, 0},
+		"B":   {5, 0},
+		"C":   {-3, 0},
+	})
+	depart := time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)
+	routers := map[string]func(context.Context, *domain.Truck, time.Time, map[string]ports.DistanceResult, services.RouteOptions) (*domain.RoutePlan, error){
+		"nearest_neighbor": services.NearestNeighborRoute,
+		"two_opt":          services.TwoOptRoute,
+	}
+
+	tests := []struct {
+		name     string
+		router   string
+		express  time.Duration
+		deadline time.Duration
+		want     []string
+	}{
+		// The nearest stop, A, leaves time for C; B does not.
+		{name: "deadline pulls a stop forward", router: "nearest_neighbor", deadline: 5 * time.Minute, want: []string{"A", "C", "B"}},
+		{name: "express window pulls a stop forward", router: "nearest_neighbor", express: 5 * time.Minute, want: []string{"A", "C", "B"}},
+		// C cannot be reached in time, so it waits its turn.
+		{name: "unreachable deadline is ignored", router: "nearest_neighbor", deadline: 2 * time.Minute, want: []string{"A", "B", "C"}},
+		{name: "no promise", router: "nearest_neighbor", want: []string{"A", "B", "C"}},
+		// Serving C first keeps the promise on a shorter route.
+		{name: "2-opt keeps the promise", router: "two_opt", deadline: 5 * time.Minute, want: []string{"C", "A", "B"}},
+		{name: "2-opt without promise", router: "two_opt", want: []string{"A", "B", "C"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			express := &domain.Package{PackageID: 3, Destination: "C", ServiceLevel: domain.ServiceLevelExpress}
+			if tc.deadline > 0 {
+				d := depart.Add(tc.deadline)
+				express.Deadline = &d
+			}
+			truck := &domain.Truck{
+				TruckID:       1,
+				Capacity:      3,
+				StartLocation: "HUB",
+				Packages: []*domain.Package{
+					{PackageID: 1, Destination: "A"},
+					{PackageID: 2, Destination: "B"},
+					express,
+				},
+			}
+			opts := services.RouteOptions{Promises: domain.Promises{Express: tc.express}}
+
+			plan, err := routers[tc.router](context.Background(), truck, depart, distances, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var stops []string
+			for _, s := range plan.Stops {
+				stops = append(stops, s.Destination)
+			}
+			if !slices.Equal(stops, tc.want) {
+				t.Fatalf("expected stops %v, got %v", tc.want, stops)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package testutil
+
+import (
+	"delivery-route-service/internal/domain"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Endpoints of FakeORS, for FailNext and Requests.
+const (
+	FakeORSGeocode    = "geocode"
+	FakeORSMatrix     = "matrix"
+	FakeORSDirections = "directions"
+)
+
+// FakeORS is an httptest server speaking the subset of the OpenRouteService
+// API the distance adapter uses: geocode search, matrix and directions
+// requests.
+//
+// Geocoding answers from Places and returns no features for unknown text.
+// Matrix distances are straight-line estimates from the posted coordinates
+// (about 111 km per degree at 10 m/s), and any location in Unroutable yields
+// null entries the way ORS does for unreachable points. Directions return one
+// segment per leg with the same estimates and no steps. Failures queued with
+// FailNext are served before normal responses.
+type FakeORS struct {
+	*httptest.Server
+
+	// APIKey, when set, must match the Authorization header.
+	APIKey     string
+	Places     map[string]domain.Coordinates
+	Unroutable map[domain.Coordinates]bool
+
+	mu       sync.Mutex
+	failures map[string][]int
+	requests map[string]int
+}
+
+// FakeORSRetryAfter is the Retry-After value, in seconds, sent with injected 429s.
+const FakeORSRetryAfter = 7
+
+// NewFakeORS starts a fake ORS server. Callers must Close it.
+func NewFakeORS(places map[string]domain.Coordinates) *FakeORS {
+	f := &FakeORS{
+		Places:     places,
+		Unroutable: map[domain.Coordinates]bool{},
+		failures:   map[string][]int{},
+		requests:   map[string]int{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geocode/search", f.serve(FakeORSGeocode, f.geocode))
+	mux.HandleFunc("/v2/matrix/", f.serve(FakeORSMatrix, f.matrix))
+	mux.HandleFunc("/v2/directions/", f.serve(FakeORSDirections, f.directions))
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// FailNext makes the next len(statuses) requests to endpoint fail with the
+// given status codes, in order.
+func (f *FakeORS) FailNext(endpoint string, statuses ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[endpoint] = append(f.failures[endpoint], statuses...)
+}
+
+// Requests reports how many requests endpoint has received, failed or not.
+func (f *FakeORS) Requests(endpoint string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[endpoint]
+}
+
+func (f *FakeORS) serve(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests[endpoint]++
+		status := 0
+		if queued := f.failures[endpoint]; len(queued) > 0 {
+			status, f.failures[endpoint] = queued[0], queued[1:]
+		}
+		f.mu.Unlock()
+
+		if f.APIKey != "" && r.Header.Get("Authorization") != f.APIKey {
+			writeORSError(w, http.StatusForbidden, "access to this API has been disallowed")
+			return
+		}
+		if status != 0 {
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", strconv.Itoa(FakeORSRetryAfter))
+			}
+			writeORSError(w, status, http.StatusText(status))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (f *FakeORS) geocode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeORSError(w, http.StatusMethodNotAllowed, "geocode search expects GET")
+		return
+	}
+	text := strings.Join(strings.Fields(r.URL.Query().Get("text")), " ")
+	if text == "" {
+		writeORSError(w, http.StatusBadRequest, "'text' parameter is required")
+		return
+	}
+
+	features := []any{}
+	if c, ok := f.Places[text]; ok {
+		features = append(features, map[string]any{
+			"type":       "Feature",
+			"geometry":   map[string]any{"type": "Point", "coordinates": c.CoordsToList()},
+			"properties": map[string]any{"label": text},
+		})
+	}
+	writeJSON(w, map[string]any{"type": "FeatureCollection", "features": features})
+}
+
+func (f *FakeORS) matrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeORSError(w, http.StatusMethodNotAllowed, "matrix expects POST")
+		return
+	}
+	var req struct {
+		Locations    [][]float64 `json:"locations"`
+		Sources      []int       `json:"sources"`
+		Destinations []int       `json:"destinations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeORSError(w, http.StatusBadRequest, "invalid matrix request: "+err.Error())
+		return
+	}
+	for _, l := range req.Locations {
+		if len(l) != 2 {
+			writeORSError(w, http.StatusBadRequest, "locations must be [lon, lat] pairs")
+			return
+		}
+	}
+	for _, i := range append(append([]int{}, req.Sources...), req.Destinations...) {
+		if i < 0 || i >= len(req.Locations) {
+			writeORSError(w, http.StatusBadRequest, "location index out of range")
+			return
+		}
+	}
+
+	distances := make([][]*float64, len(req.Sources))
+	durations := make([][]*float64, len(req.Sources))
+	for si, s := range req.Sources {
+		from := domain.Coordinates{Lon: req.Locations[s][0], Lat: req.Locations[s][1]}
+		for _, d := range req.Destinations {
+			to := domain.Coordinates{Lon: req.Locations[d][0], Lat: req.Locations[d][1]}
+			if f.Unroutable[from] || f.Unroutable[to] {
+				distances[si] = append(distances[si], nil)
+				durations[si] = append(durations[si], nil)
+				continue
+			}
+			meters, seconds := estimate(from, to)
+			distances[si] = append(distances[si], &meters)
+			durations[si] = append(durations[si], &seconds)
+		}
+	}
+	writeJSON(w, map[string]any{"distances": distances, "durations": durations})
+}
+
+func (f *FakeORS) directions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeORSError(w, http.StatusMethodNotAllowed, "directions expects POST")
+		return
+	}
+	var req struct {
+		Coordinates [][]float64 `json:"coordinates"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeORSError(w, http.StatusBadRequest, "invalid directions request: "+err.Error())
+		return
+	}
+	if len(req.Coordinates) < 2 {
+		writeORSError(w, http.StatusBadRequest, "at least two coordinates are required")
+		return
+	}
+
+	segments := make([]map[string]any, 0, len(req.Coordinates)-1)
+	for i := 1; i < len(req.Coordinates); i++ {
+		prev, cur := req.Coordinates[i-1], req.Coordinates[i]
+		if len(prev) != 2 || len(cur) != 2 {
+			writeORSError(w, http.StatusBadRequest, "coordinates must be [lon, lat] pairs")
+			return
+		}
+		from := domain.Coordinates{Lon: prev[0], Lat: prev[1]}
+		to := domain.Coordinates{Lon: cur[0], Lat: cur[1]}
+		if f.Unroutable[from] || f.Unroutable[to] {
+			writeORSError(w, http.StatusNotFound, "could not find routable point")
+			return
+		}
+		meters, seconds := estimate(from, to)
+		segments = append(segments, map[string]any{"distance": meters, "duration": seconds, "steps": []any{}})
+	}
+	writeJSON(w, map[string]any{"routes": []any{map[string]any{"geometry": "", "segments": segments}}})
+}
+
+// estimate is the straight-line distance and duration between two points.
+func estimate(from, to domain.Coordinates) (meters, seconds float64) {
+	meters = math.Round(math.Hypot(to.Lon-from.Lon, to.Lat-from.Lat) * 111000)
+	return meters, math.Round(meters / 10)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeORSError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": status, "message": message}})
+}
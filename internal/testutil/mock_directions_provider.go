@@ -0,0 +1,49 @@
+package testutil
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MockDirectionsProvider adds GetDirections to MockDistanceProvider.
+// Legs are built from the configured pairs and the polyline is the joined waypoint list.
+type MockDirectionsProvider struct {
+	*MockDistanceProvider
+
+	mu sync.Mutex
+	// Waypoint lists passed to GetDirections, in call order.
+	Requested [][]string
+}
+
+func NewMockDirectionsProvider(pairs []MockPair) *MockDirectionsProvider {
+	return &MockDirectionsProvider{MockDistanceProvider: NewMockDistanceProvider(pairs)}
+}
+
+func (m *MockDirectionsProvider) GetDirections(
+	ctx context.Context,
+	waypoints []string,
+	known map[string]domain.Coordinates,
+) (*domain.RouteGeometry, error) {
+	m.mu.Lock()
+	m.Requested = append(m.Requested, waypoints)
+	m.mu.Unlock()
+
+	legs := make([]domain.RouteLeg, 0, len(waypoints)-1)
+	for i := 0; i+1 < len(waypoints); i++ {
+		r, ok := m.m[waypoints[i]+"|"+waypoints[i+1]]
+		if !ok {
+			return nil, fmt.Errorf("missing pair %q -> %q", waypoints[i], waypoints[i+1])
+		}
+		legs = append(legs, domain.RouteLeg{
+			From:            waypoints[i],
+			To:              waypoints[i+1],
+			DistanceMeters:  r.DistanceMeters,
+			DurationSeconds: r.DurationSeconds,
+		})
+	}
+
+	return &domain.RouteGeometry{Polyline: strings.Join(waypoints, ">"), Legs: legs}, nil
+}
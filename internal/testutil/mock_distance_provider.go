@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"context"
+	"delivery-route-service/internal/domain"
 	"delivery-route-service/internal/ports"
 	"fmt"
 )
@@ -14,6 +15,12 @@ type MockPair struct {
 
 type MockDistanceProvider struct {
 	m map[string]ports.DistanceResult
+	// Addresses reported by GeocodeAddresses as not geocodable, with their reason.
+	Ungeocodable map[string]string
+	// Coordinates returned by GeocodeAddresses for known addresses.
+	Coordinates map[string]domain.Coordinates
+	// Addresses passed to GeocodeAddresses, in call order.
+	Geocoded []string
 }
 
 func NewMockDistanceProvider(pairs []MockPair) *MockDistanceProvider {
@@ -48,3 +55,22 @@ func (m *MockDistanceProvider) GetDistances(
 	}
 	return results, nil
 }
+
+func (m *MockDistanceProvider) GeocodeAddresses(
+	ctx context.Context,
+	addresses []string,
+) (map[string]domain.Coordinates, map[string]string, error) {
+	coords := make(map[string]domain.Coordinates)
+	failures := make(map[string]string)
+	for _, a := range addresses {
+		m.Geocoded = append(m.Geocoded, a)
+		if reason, ok := m.Ungeocodable[a]; ok {
+			failures[a] = reason
+			continue
+		}
+		if c, ok := m.Coordinates[a]; ok {
+			coords[a] = c
+		}
+	}
+	return coords, failures, nil
+}
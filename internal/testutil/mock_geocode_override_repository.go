@@ -0,0 +1,65 @@
+package testutil
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"sort"
+)
+
+type MockGeocodeOverrideRepository struct {
+	Overrides map[string]domain.GeocodeOverride
+	Err       error
+}
+
+func NewMockGeocodeOverrideRepository(overrides []domain.GeocodeOverride, err error) *MockGeocodeOverrideRepository {
+	m := make(map[string]domain.GeocodeOverride, len(overrides))
+	for _, o := range overrides {
+		m[o.Address] = o
+	}
+	return &MockGeocodeOverrideRepository{Overrides: m, Err: err}
+}
+
+func (m *MockGeocodeOverrideRepository) ListOverrides(ctx context.Context) ([]domain.GeocodeOverride, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := make([]domain.GeocodeOverride, 0, len(m.Overrides))
+	for _, o := range m.Overrides {
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out, nil
+}
+
+func (m *MockGeocodeOverrideRepository) GetOverrides(
+	ctx context.Context,
+	addresses []string,
+) (map[string]domain.Coordinates, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := make(map[string]domain.Coordinates)
+	for _, a := range addresses {
+		if o, ok := m.Overrides[a]; ok {
+			out[a] = o.Coordinates
+		}
+	}
+	return out, nil
+}
+
+func (m *MockGeocodeOverrideRepository) PutOverride(ctx context.Context, o domain.GeocodeOverride) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Overrides[o.Address] = o
+	return nil
+}
+
+func (m *MockGeocodeOverrideRepository) DeleteOverride(ctx context.Context, address string) (bool, error) {
+	if m.Err != nil {
+		return false, m.Err
+	}
+	_, ok := m.Overrides[address]
+	delete(m.Overrides, address)
+	return ok, nil
+}
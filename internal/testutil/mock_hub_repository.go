@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"sort"
+)
+
+type MockHubRepository struct {
+	Hubs map[int]*domain.Hub
+	Err  error
+}
+
+func NewMockHubRepository(hubs []*domain.Hub, err error) *MockHubRepository {
+	m := make(map[int]*domain.Hub, len(hubs))
+	for _, h := range hubs {
+		m[h.HubID] = h
+	}
+	return &MockHubRepository{Hubs: m, Err: err}
+}
+
+func (m *MockHubRepository) ListHubs(ctx context.Context) ([]*domain.Hub, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := make([]*domain.Hub, 0, len(m.Hubs))
+	for _, h := range m.Hubs {
+		cp := *h
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].HubID < out[j].HubID })
+	return out, nil
+}
+
+func (m *MockHubRepository) PutHub(ctx context.Context, h *domain.Hub) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	cp := *h
+	m.Hubs[h.HubID] = &cp
+	return nil
+}
+
+func (m *MockHubRepository) DeleteHub(ctx context.Context, id int) (bool, error) {
+	if m.Err != nil {
+		return false, m.Err
+	}
+	_, ok := m.Hubs[id]
+	delete(m.Hubs, id)
+	return ok, nil
+}
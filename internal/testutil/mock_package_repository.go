@@ -8,6 +8,10 @@ import (
 type MockPackageRepository struct {
 	Packages []*domain.Package
 	Err      error
+	// Coordinates written through SetPackageCoordinates, by package ID.
+	StoredCoordinates map[int]domain.Coordinates
+	// Batches passed to UpsertPackages, in call order.
+	Upserted [][]*domain.Package
 }
 
 func NewMockPackageRepository(packages []*domain.Package, err error) *MockPackageRepository {
@@ -17,3 +21,21 @@ func NewMockPackageRepository(packages []*domain.Package, err error) *MockPackag
 func (m *MockPackageRepository) ListPackages(ctx context.Context) ([]*domain.Package, error) {
 	return m.Packages, m.Err
 }
+
+func (m *MockPackageRepository) SetPackageCoordinates(ctx context.Context, coords map[int]domain.Coordinates) error {
+	if m.StoredCoordinates == nil {
+		m.StoredCoordinates = make(map[int]domain.Coordinates, len(coords))
+	}
+	for id, c := range coords {
+		m.StoredCoordinates[id] = c
+	}
+	return nil
+}
+
+func (m *MockPackageRepository) UpsertPackages(ctx context.Context, pkgs []*domain.Package) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Upserted = append(m.Upserted, pkgs)
+	return nil
+}
@@ -0,0 +1,65 @@
+package testutil
+
+import (
+	"context"
+	"delivery-route-service/internal/domain"
+	"sort"
+)
+
+type MockTruckRepository struct {
+	Trucks map[int]*domain.Truck
+	Err    error
+}
+
+func NewMockTruckRepository(trucks []*domain.Truck, err error) *MockTruckRepository {
+	m := make(map[int]*domain.Truck, len(trucks))
+	for _, t := range trucks {
+		m[t.TruckID] = t
+	}
+	return &MockTruckRepository{Trucks: m, Err: err}
+}
+
+func (m *MockTruckRepository) ListTrucks(ctx context.Context) ([]*domain.Truck, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := make([]*domain.Truck, 0, len(m.Trucks))
+	for _, t := range m.Trucks {
+		cp := *t
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TruckID < out[j].TruckID })
+	return out, nil
+}
+
+func (m *MockTruckRepository) GetTrucks(ctx context.Context, ids []int) (map[int]*domain.Truck, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	out := make(map[int]*domain.Truck)
+	for _, id := range ids {
+		if t, ok := m.Trucks[id]; ok {
+			cp := *t
+			out[id] = &cp
+		}
+	}
+	return out, nil
+}
+
+func (m *MockTruckRepository) PutTruck(ctx context.Context, t *domain.Truck) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	cp := *t
+	m.Trucks[t.TruckID] = &cp
+	return nil
+}
+
+func (m *MockTruckRepository) DeleteTruck(ctx context.Context, id int) (bool, error) {
+	if m.Err != nil {
+		return false, m.Err
+	}
+	_, ok := m.Trucks[id]
+	delete(m.Trucks, id)
+	return ok, nil
+}
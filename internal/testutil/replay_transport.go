@@ -0,0 +1,159 @@
+package testutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Fixture is one recorded HTTP exchange. Request headers are not stored, so
+// API keys never end up in fixture files.
+type Fixture struct {
+	Method      string            `json:"method"`
+	URL         string            `json:"url"`
+	RequestBody string            `json:"request_body,omitempty"`
+	Status      int               `json:"status"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        string            `json:"body"`
+}
+
+// Response headers worth keeping in a fixture.
+var fixtureHeaders = []string{"Content-Type", "Retry-After"}
+
+// ReplayTransport is an http.RoundTripper that serves responses from fixture
+// files in Dir. In record mode it forwards requests to Next (the default
+// transport when nil) and writes each exchange to Dir first.
+//
+// Requests are matched on method, path, query and body, so the host can differ
+// between recording and replay. A request without a fixture fails in replay
+// mode rather than reaching the network.
+type ReplayTransport struct {
+	Dir    string
+	Record bool
+	Next   http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// NewReplayTransport replays fixtures from dir, or records them when record is set.
+func NewReplayTransport(dir string, record bool) *ReplayTransport {
+	return &ReplayTransport{Dir: dir, Record: record}
+}
+
+// fixtureKey identifies a request independently of host and headers.
+func fixtureKey(req *http.Request, body []byte) (key string, target string) {
+	target = req.URL.Path
+	if q := req.URL.Query(); len(q) > 0 {
+		target += "?" + q.Encode()
+	}
+	sum := sha256.Sum256([]byte(req.Method + " " + target + "\n" + string(body)))
+	return hex.EncodeToString(sum[:8]), target
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("replay transport: read request body: %w", err)
+		}
+		body = b
+	}
+	key, target := fixtureKey(req, body)
+	path := filepath.Join(t.Dir, key+".json")
+
+	if !t.Record {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("replay transport: no fixture for %s %s (%s); re-record to add it: %w",
+				req.Method, target, filepath.Base(path), err)
+		}
+		var f Fixture
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, fmt.Errorf("replay transport: parse %s: %w", path, err)
+		}
+		return f.response(req), nil
+	}
+
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("replay transport: read response body: %w", err)
+	}
+
+	f := Fixture{
+		Method:      req.Method,
+		URL:         target,
+		RequestBody: string(body),
+		Status:      resp.StatusCode,
+		Header:      map[string]string{},
+		Body:        string(respBody),
+	}
+	for _, h := range fixtureHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			f.Header[h] = v
+		}
+	}
+	if err := t.save(path, f); err != nil {
+		return nil, err
+	}
+
+	return f.response(req), nil
+}
+
+func (t *ReplayTransport) save(path string, f Fixture) error {
+	// Query strings stay readable with HTML escaping off.
+	var raw bytes.Buffer
+	enc := json.NewEncoder(&raw)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return fmt.Errorf("replay transport: marshal fixture: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return fmt.Errorf("replay transport: %w", err)
+	}
+	if err := os.WriteFile(path, raw.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("replay transport: write fixture: %w", err)
+	}
+	return nil
+}
+
+func (f Fixture) response(req *http.Request) *http.Response {
+	header := make(http.Header, len(f.Header))
+	for k, v := range f.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(f.Body))),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}